@@ -2,6 +2,7 @@ module github.com/tpphu/golang-training
 
 require (
 	github.com/PuerkitoBio/goquery v1.5.1
+	github.com/Shopify/sarama v1.19.0
 	github.com/bsm/sarama-cluster v2.1.15+incompatible // indirect
 	github.com/buger/goterm v0.0.0-20200322175922-2f3e71b85129
 	github.com/c-bata/go-prompt v0.2.3
@@ -17,6 +18,7 @@ require (
 	github.com/hashicorp/go.net v0.0.1 // indirect
 	github.com/jinzhu/gorm v1.9.12
 	github.com/joho/godotenv v1.3.0
+	github.com/mattn/go-sqlite3 v2.0.1+incompatible
 	github.com/mattn/go-runewidth v0.0.9 // indirect
 	github.com/micro/go-micro v1.18.0
 	github.com/mitchellh/gox v0.4.0 // indirect
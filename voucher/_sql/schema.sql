@@ -4,6 +4,16 @@ CREATE TABLE `voucher` (
 	`discount` FLOAT UNSIGNED NOT NULL,
 	`start` TIMESTAMP NOT NULL DEFAULT '',
 	`end` TIMESTAMP NOT NULL DEFAULT '0000-00-00 00:00:00',
+	`quota` INT(10) UNSIGNED NOT NULL DEFAULT 1,
+	`max_per_customer` INT(10) UNSIGNED NOT NULL DEFAULT 0,
+	`used` INT(10) UNSIGNED NOT NULL DEFAULT 0,
+	`type` VARCHAR(16) NOT NULL DEFAULT 'percent',
+	`discount_cap` FLOAT UNSIGNED NOT NULL DEFAULT 0,
+	`min_order` FLOAT UNSIGNED NOT NULL DEFAULT 0,
+	`categories` VARCHAR(255) NOT NULL DEFAULT '',
+	`stackable` TINYINT(1) NOT NULL DEFAULT 0,
+	`disabled` TINYINT(1) NOT NULL DEFAULT 0,
+	`deleted_at` TIMESTAMP NULL DEFAULT NULL,
 	PRIMARY KEY (`id`),
 	INDEX `code` (`code`)
 )
@@ -12,6 +22,91 @@ ENGINE=InnoDB
 AUTO_INCREMENT=1535
 ;
 
+CREATE TABLE `locker` (
+	`id` INT(10) UNSIGNED NOT NULL,
+	PRIMARY KEY (`id`)
+)
+ENGINE=InnoDB
+;
+
+INSERT INTO `locker`(`id`) VALUES(1);
+
+CREATE TABLE `redemption` (
+	`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	`voucher_id` INT(10) UNSIGNED NOT NULL,
+	`customer` VARCHAR(64) NOT NULL,
+	`amount` FLOAT UNSIGNED NOT NULL,
+	`discount` FLOAT UNSIGNED NOT NULL,
+	`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (`id`),
+	INDEX `voucher_customer` (`voucher_id`, `customer`)
+)
+COLLATE='latin1_swedish_ci'
+ENGINE=InnoDB
+;
+
+CREATE TABLE `reservation` (
+	`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	`voucher_id` INT(10) UNSIGNED NOT NULL,
+	`customer` VARCHAR(64) NOT NULL,
+	`amount` FLOAT UNSIGNED NOT NULL,
+	`discount` FLOAT UNSIGNED NOT NULL,
+	`status` VARCHAR(16) NOT NULL,
+	`expires_at` TIMESTAMP NOT NULL,
+	`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (`id`),
+	INDEX `voucher_customer` (`voucher_id`, `customer`),
+	INDEX `status_expires_at` (`status`, `expires_at`)
+)
+COLLATE='latin1_swedish_ci'
+ENGINE=InnoDB
+;
+
+CREATE TABLE `idempotency` (
+	`scope` VARCHAR(16) NOT NULL,
+	`key` VARCHAR(255) NOT NULL,
+	`hash` CHAR(64) NOT NULL,
+	`response` BLOB NULL,
+	`expires_at` TIMESTAMP NOT NULL,
+	`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (`scope`, `key`),
+	INDEX `expires_at` (`expires_at`)
+)
+COLLATE='latin1_swedish_ci'
+ENGINE=InnoDB
+;
+
+-- Chi insert, khong update/delete
+CREATE TABLE `audit` (
+	`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	`voucher_id` INT(10) UNSIGNED NOT NULL,
+	`action` VARCHAR(16) NOT NULL,
+	`actor` VARCHAR(64) NOT NULL,
+	`before` TEXT NOT NULL,
+	`after` TEXT NOT NULL,
+	`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (`id`),
+	INDEX `voucher_id` (`voucher_id`)
+)
+COLLATE='latin1_swedish_ci'
+ENGINE=InnoDB
+;
+
+-- Event duoc ghi cung transaction voi voucher/redemption, relay publish len Kafka
+CREATE TABLE `outbox` (
+	`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	`voucher_id` INT(10) UNSIGNED NOT NULL,
+	`type` VARCHAR(32) NOT NULL,
+	`payload` TEXT NOT NULL,
+	`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	`published_at` TIMESTAMP NULL DEFAULT NULL,
+	PRIMARY KEY (`id`),
+	INDEX `published_at` (`published_at`, `id`)
+)
+COLLATE='latin1_swedish_ci'
+ENGINE=InnoDB
+;
+
 select v1.id, v1.code, v1.`start`, v1.`end`, v2.id, v2.`start`, v2.`end`
 from voucher as v1
 join voucher as v2
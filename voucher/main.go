@@ -4,8 +4,12 @@ import (
 	"database/sql"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"./model"
+	"./publisher"
 	"./storage"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -18,44 +22,232 @@ func main() {
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
-	db, err := sql.Open("mysql", "default:secret@/voucher")
-	if err != nil {
-		panic(err)
+	voucherStorage, closeStore := newStore(os.Getenv("STORAGE"))
+	defer closeStore()
+	// Reservation qua han se duoc sweeper tra lai quota
+	reservationTTL := getDuration("RESERVATION_TTL", 10*time.Minute)
+	stopSweeper := storage.StartSweeper(voucherStorage, getDuration("SWEEP_INTERVAL", time.Minute))
+	defer stopSweeper()
+	// Relay event trong outbox len Kafka, khong co KAFKA_BROKERS thi event nam lai trong outbox
+	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+		topic := os.Getenv("KAFKA_TOPIC")
+		if topic == "" {
+			topic = "voucher_events"
+		}
+		kafka, err := publisher.NewStaticKafka(strings.Split(brokers, ","), topic)
+		if err != nil {
+			panic(err)
+		}
+		defer kafka.Close()
+		stopRelay := storage.StartRelay(voucherStorage, kafka, getDuration("RELAY_INTERVAL", time.Second))
+		defer stopRelay()
 	}
-	db.SetMaxOpenConns(50)
-	db.SetMaxIdleConns(30)
-	defer db.Close()
+	// Ket qua cua request co header Idempotency-Key duoc giu trong IDEMPOTENCY_TTL
+	idempotencyTTL := getDuration("IDEMPOTENCY_TTL", 24*time.Hour)
 	r := gin.Default()
 	r.POST("/register", func(c *gin.Context) {
 		voucher := model.Voucher{}
-		voucherStorage := storage.Voucher{
-			DB: db,
-		}
 		// S1: Lay data tu client
 		if err := c.ShouldBindJSON(&voucher); err != nil {
 			c.JSON(400, err)
 			return
 		}
-		// S2: Kiem tra co ton tai trong db khong
-		// isExist, err := voucherStorage.IsExit(voucher)
-		// if err != nil {
-		// 	c.JSON(400, err)
-		// 	return
-		// }
-
-		// if isExist {
-		// 	c.JSON(400, gin.H{
-		// 		"error": "Exist",
-		// 	})
-		// 	return
-		// }
-		// S3: Insert vo db neu no chua ton tai
-		voucherStorage.RegisterIsolation(&voucher)
+		if err := voucher.Rule.Validate(voucher.Discount); err != nil {
+			c.JSON(400, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		// S2: Insert vo db neu chua co voucher cung code bi overlap,
+		// client retry voi cung Idempotency-Key se nhan lai voucher da insert
+		replayed, err := storage.Idempotent(voucherStorage, model.IDEMPOTENCY_REGISTER, c.GetHeader("Idempotency-Key"),
+			voucher, &voucher, idempotencyTTL, func() error {
+				return voucherStorage.Register(&voucher)
+			})
+		if idempotencyReturnHandler(c, err, replayed) {
+			return
+		}
+		if err == storage.ErrExist {
+			c.JSON(400, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		c.JSON(200, voucher)
 	})
+	r.POST("/generate", func(c *gin.Context) {
+		req := model.GenerateReq{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, err)
+			return
+		}
+		if err := req.Rule.Validate(req.Discount); err != nil {
+			c.JSON(400, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		codes := []string{}
+		err := storage.GenerateBatch(voucherStorage, req, func(vouchers []model.Voucher) error {
+			for _, v := range vouchers {
+				codes = append(codes, v.Code)
+			}
+			return nil
+		})
+		if err == storage.ErrNotEnoughCodes {
+			c.JSON(400, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+				"codes": codes,
+			})
+			return
+		}
+		c.JSON(200, gin.H{
+			"codes": codes,
+		})
+	})
+	r.GET("/vouchers", func(c *gin.Context) {
+		req := model.ListReq{}
+		if err := c.ShouldBindQuery(&req); err != nil {
+			c.JSON(400, err)
+			return
+		}
+		req.Now = time.Now()
+		res, err := voucherStorage.List(req)
+		if err == model.ErrInvalidCursor {
+			c.JSON(400, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(200, res)
+	})
 	r.GET("/verify", func(c *gin.Context) {
-		voucher := model.Voucher{}
-		c.JSON(200, voucher)
+		req := model.VerifyReq{}
+		if err := c.ShouldBindQuery(&req); err != nil {
+			c.JSON(400, err)
+			return
+		}
+		res, err := voucherStorage.Verify(req, time.Now())
+		verifyReturnHandler(c, err, res)
+	})
+	// POST /verify de gui kem Items cua don hang
+	r.POST("/verify", func(c *gin.Context) {
+		req := model.VerifyReq{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, err)
+			return
+		}
+		res, err := voucherStorage.Verify(req, time.Now())
+		verifyReturnHandler(c, err, res)
+	})
+	// Tinh discount khi dung chung nhieu voucher, khong tinh vao quota
+	r.POST("/evaluate", func(c *gin.Context) {
+		req := model.EvaluateReq{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, err)
+			return
+		}
+		res, err := storage.Evaluate(voucherStorage, req, time.Now())
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(200, res)
+	})
+	r.POST("/redeem", func(c *gin.Context) {
+		req := model.VerifyReq{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, err)
+			return
+		}
+		res := &model.VerifyRes{}
+		replayed, err := storage.Idempotent(voucherStorage, model.IDEMPOTENCY_REDEEM, c.GetHeader("Idempotency-Key"),
+			req, res, idempotencyTTL, func() error {
+				redeemRes, err := voucherStorage.Redeem(req, time.Now())
+				if err == nil {
+					*res = *redeemRes
+				}
+				return err
+			})
+		if idempotencyReturnHandler(c, err, replayed) {
+			return
+		}
+		verifyReturnHandler(c, err, res)
+	})
+	r.POST("/reserve", func(c *gin.Context) {
+		req := model.VerifyReq{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, err)
+			return
+		}
+		res, err := voucherStorage.Reserve(req, time.Now(), reservationTTL)
+		if err != nil {
+			verifyReturnHandler(c, err, nil)
+			return
+		}
+		c.JSON(verifyStatusCode(res.Status), res)
+	})
+	r.POST("/reservations/:id/confirm", func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.Param("id"))
+		res, err := voucherStorage.Confirm(id, time.Now())
+		reservationReturnHandler(c, err, res)
+	})
+	r.POST("/reservations/:id/release", func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.Param("id"))
+		res, err := voucherStorage.Release(id)
+		reservationReturnHandler(c, err, res)
+	})
+	// Lifecycle: moi thay doi deu duoc ghi vao audit cung voi actor
+	r.POST("/vouchers/:id/deactivate", func(c *gin.Context) {
+		changeHandler(c, func(id int, req model.ChangeReq) (*model.Voucher, error) {
+			return storage.Deactivate(voucherStorage, id, req.Actor, time.Now())
+		})
+	})
+	r.POST("/vouchers/:id/reactivate", func(c *gin.Context) {
+		changeHandler(c, func(id int, req model.ChangeReq) (*model.Voucher, error) {
+			return storage.Reactivate(voucherStorage, id, req.Actor, time.Now())
+		})
+	})
+	r.POST("/vouchers/:id/extend", func(c *gin.Context) {
+		changeHandler(c, func(id int, req model.ChangeReq) (*model.Voucher, error) {
+			return storage.Extend(voucherStorage, id, req.End, req.Actor, time.Now())
+		})
+	})
+	r.DELETE("/vouchers/:id", func(c *gin.Context) {
+		changeHandler(c, func(id int, req model.ChangeReq) (*model.Voucher, error) {
+			return storage.Delete(voucherStorage, id, req.Actor, time.Now())
+		})
+	})
+	r.GET("/vouchers/:id/audits", func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.Param("id"))
+		audits, err := voucherStorage.Audits(id)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(200, audits)
 	})
 	port := os.Getenv("PORT")
 	r.GET("/ping", func(c *gin.Context) {
@@ -65,3 +257,124 @@ func main() {
 	})
 	r.Run(":" + port) // listen and serve on 0.0.0.0:8080
 }
+
+// newStore chon backend theo env STORAGE: mysql (mac dinh), sqlite hoac memory
+func newStore(backend string) (storage.VoucherStore, func()) {
+	switch backend {
+	case "memory":
+		return storage.NewMemory(), func() {}
+	case "sqlite":
+		store, err := storage.NewSQLite("voucher.db")
+		if err != nil {
+			panic(err)
+		}
+		return store, func() { store.DB.Close() }
+	}
+	db, err := sql.Open("mysql", "default:secret@/voucher?parseTime=true")
+	if err != nil {
+		panic(err)
+	}
+	db.SetMaxOpenConns(50)
+	db.SetMaxIdleConns(30)
+	return storage.Voucher{DB: db}, func() { db.Close() }
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// idempotencyReturnHandler tra ve true neu da response loi cua idempotency key
+func idempotencyReturnHandler(c *gin.Context, err error, replayed bool) bool {
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	switch err {
+	case model.ErrIdempotencyInProgress:
+		c.JSON(409, gin.H{
+			"error": err.Error(),
+		})
+		return true
+	case model.ErrIdempotencyMismatch:
+		c.JSON(422, gin.H{
+			"error": err.Error(),
+		})
+		return true
+	}
+	return false
+}
+
+// changeHandler doc actor (va end cua extend) tu body roi tra ve voucher sau khi thay doi
+func changeHandler(c *gin.Context, change func(id int, req model.ChangeReq) (*model.Voucher, error)) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	req := model.ChangeReq{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, err)
+		return
+	}
+	voucher, err := change(id, req)
+	switch err {
+	case nil:
+		c.JSON(200, voucher)
+	case model.ErrVoucherNotFound:
+		c.JSON(404, gin.H{
+			"error": err.Error(),
+		})
+	case model.ErrInvalidExtend, storage.ErrExist:
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+	}
+}
+
+func reservationReturnHandler(c *gin.Context, err error, res *model.Reservation) {
+	switch err {
+	case nil:
+		c.JSON(200, res)
+	case model.ErrReservationNotFound:
+		c.JSON(404, gin.H{
+			"error": err.Error(),
+		})
+	case model.ErrReservationExpired, model.ErrReservationClosed:
+		c.JSON(409, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+	}
+}
+
+func verifyReturnHandler(c *gin.Context, err error, res *model.VerifyRes) {
+	if err == model.ErrCustomerRequired {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(verifyStatusCode(res.Status), res)
+}
+
+func verifyStatusCode(status string) int {
+	switch status {
+	case model.VERIFY_OK:
+		return 200
+	case model.VERIFY_NOT_FOUND:
+		return 404
+	}
+	return 400
+}
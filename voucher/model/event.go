@@ -0,0 +1,36 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Loai event duoc ghi vao outbox
+const (
+	EVENT_VOUCHER_REGISTERED = "VoucherRegistered"
+	EVENT_VOUCHER_REDEEMED   = "VoucherRedeemed"
+)
+
+// Event duoc ghi vao outbox trong cung transaction voi thay doi cua voucher,
+// relay se publish theo thu tu Id. Payload la JSON cua Voucher (VoucherRegistered)
+// hoac Redemption (VoucherRedeemed).
+type Event struct {
+	Id        int
+	VoucherId int
+	Type      string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+func NewEvent(eventType string, voucherId int, payload interface{}, now time.Time) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		VoucherId: voucherId,
+		Type:      eventType,
+		Payload:   data,
+		CreatedAt: now,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"crypto/rand"
+	"math"
+	"math/big"
+	"time"
+)
+
+const (
+	// Bo ky tu mac dinh, bo bot cac ky tu de nham nhu 0/O, 1/I
+	CODE_ALPHABET = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	CODE_LENGTH   = 8
+)
+
+// GenerateReq sinh ra Count voucher co code = Prefix + Length ky tu ngau nhien tu Alphabet
+type GenerateReq struct {
+	Prefix         string    `binding:"max=32"`
+	Count          int       `binding:"required,min=1,max=100000"`
+	Length         int       `binding:"min=0,max=32"`
+	Alphabet       string    `binding:"max=64"`
+	Discount       float32   `binding:"required,gt=0"`
+	Start          time.Time `binding:"required"`
+	End            time.Time `binding:"required,gtefield=Start"`
+	Quota          int       `binding:"min=0"`
+	MaxPerCustomer int       `binding:"min=0"`
+	Rule
+}
+
+func (r GenerateReq) GetLength() int {
+	if r.Length <= 0 {
+		return CODE_LENGTH
+	}
+	return r.Length
+}
+
+func (r GenerateReq) GetAlphabet() string {
+	if r.Alphabet == "" {
+		return CODE_ALPHABET
+	}
+	return r.Alphabet
+}
+
+// CanGenerate: so code co the sinh ra phai lon hon nhieu lan Count,
+// neu khong thi se bi trung lien tuc
+func (r GenerateReq) CanGenerate() bool {
+	alphabet := []rune(r.GetAlphabet())
+	if len(alphabet) < 2 {
+		return false
+	}
+	return math.Pow(float64(len(alphabet)), float64(r.GetLength())) >= float64(r.Count)*10
+}
+
+func (r GenerateReq) NewCode() (string, error) {
+	alphabet := []rune(r.GetAlphabet())
+	max := big.NewInt(int64(len(alphabet)))
+	code := []rune(r.Prefix)
+	for i := 0; i < r.GetLength(); i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code = append(code, alphabet[n.Int64()])
+	}
+	return string(code), nil
+}
+
+func (r GenerateReq) NewVoucher(code string) Voucher {
+	return Voucher{
+		Code:           code,
+		Discount:       r.Discount,
+		Start:          r.Start,
+		End:            r.End,
+		Quota:          r.Quota,
+		MaxPerCustomer: r.MaxPerCustomer,
+		Rule:           r.Rule,
+	}
+}
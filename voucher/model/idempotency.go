@@ -0,0 +1,44 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Scope cua idempotency key, cung mot key co the dung cho ca register va redeem
+const (
+	IDEMPOTENCY_REGISTER = "register"
+	IDEMPOTENCY_REDEEM   = "redeem"
+)
+
+// Key cua request dang chay chi duoc giu trong IDEMPOTENCY_LEASE. Process chet
+// (hoac khong luu duoc ket qua) giua chung thi client retry duoc sau lease
+// thay vi phai cho het ttl cua key.
+const IDEMPOTENCY_LEASE = time.Minute
+
+var (
+	// Request truoc voi cung key van dang chay
+	ErrIdempotencyInProgress = errors.New("Request with this idempotency key is in progress")
+	// Cung key nhung request khac voi request dau tien
+	ErrIdempotencyMismatch = errors.New("Idempotency key is used for a different request")
+)
+
+// Idempotency luu ket qua cua request dau tien voi mot key trong mot khoang thoi gian,
+// client retry voi cung key se nhan lai ket qua nay ma khong chay lai request.
+// Response = nil nghia la request dau tien chua chay xong.
+type Idempotency struct {
+	Scope     string
+	Key       string
+	Hash      string // sha256 cua request
+	Response  []byte // JSON cua ket qua
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+func (i Idempotency) IsExpired(now time.Time) bool {
+	return now.After(i.ExpiresAt)
+}
+
+func (i Idempotency) IsDone() bool {
+	return i.Response != nil
+}
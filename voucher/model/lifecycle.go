@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Cac thay doi duoc ghi vao audit
+const (
+	ACTION_DEACTIVATE = "deactivate"
+	ACTION_REACTIVATE = "reactivate"
+	ACTION_EXTEND     = "extend"
+	ACTION_DELETE     = "delete"
+)
+
+var (
+	// Khong co voucher hoac voucher da bi xoa
+	ErrVoucherNotFound = errors.New("Voucher not found")
+	// Extend chi duoc keo dai end
+	ErrInvalidExtend = errors.New("New end must be after current end")
+)
+
+// Audit ghi lai mot lan thay doi voucher, chi duoc insert khong bao gio update/delete.
+// Before va After la JSON cua voucher truoc va sau khi thay doi.
+type Audit struct {
+	Id        int
+	VoucherId int
+	Action    string
+	Actor     string
+	Before    json.RawMessage
+	After     json.RawMessage
+	CreatedAt time.Time
+}
+
+// ChangeReq la request cua cac thao tac deactivate/reactivate/extend/delete
+type ChangeReq struct {
+	Actor string    `binding:"required,max=64"`
+	End   time.Time // Chi dung cho extend
+}
+
+func NewAudit(action string, actor string, before *Voucher, after *Voucher, now time.Time) (*Audit, error) {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return nil, err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	return &Audit{
+		VoucherId: before.Id,
+		Action:    action,
+		Actor:     actor,
+		Before:    beforeJSON,
+		After:     afterJSON,
+		CreatedAt: now,
+	}, nil
+}
+
+func (v Voucher) IsDeleted() bool {
+	return v.DeletedAt != nil
+}
+
+// Deactivate tam dung voucher, voucher van giu khoang start/end nen van chan code bi overlap
+func Deactivate(v *Voucher) error {
+	v.Disabled = true
+	return nil
+}
+
+func Reactivate(v *Voucher) error {
+	v.Disabled = false
+	return nil
+}
+
+func Extend(end time.Time) func(v *Voucher) error {
+	return func(v *Voucher) error {
+		if !end.After(v.End) {
+			return ErrInvalidExtend
+		}
+		v.End = end
+		return nil
+	}
+}
+
+func Delete(now time.Time) func(v *Voucher) error {
+	return func(v *Voucher) error {
+		v.DeletedAt = &now
+		return nil
+	}
+}
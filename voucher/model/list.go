@@ -0,0 +1,180 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Trang thai cua voucher tai mot thoi diem
+const (
+	STATUS_ACTIVE    = "active"
+	STATUS_UPCOMING  = "upcoming"
+	STATUS_EXPIRED   = "expired"
+	STATUS_EXHAUSTED = "exhausted"
+	STATUS_INACTIVE  = "inactive"
+)
+
+const (
+	LIST_LIMIT     = 20
+	LIST_MAX_LIMIT = 100
+)
+
+var ErrInvalidCursor = errors.New("Invalid cursor")
+
+// ListReq loc va phan trang voucher. Cursor la gia tri NextCursor cua trang truoc,
+// chi dung duoc voi cung Sort va Desc.
+type ListReq struct {
+	CodePrefix  string    `form:"code_prefix"`
+	ActiveAt    time.Time `form:"active_at" time_format:"2006-01-02T15:04:05Z07:00"`
+	MinDiscount float32   `form:"min_discount"`
+	MaxDiscount float32   `form:"max_discount"`
+	Status      string    `form:"status" binding:"omitempty,oneof=active upcoming expired exhausted inactive"`
+	Sort        string    `form:"sort" binding:"omitempty,oneof=id code start end discount"`
+	Desc        bool      `form:"desc"`
+	Limit       int       `form:"limit" binding:"min=0,max=100"`
+	Cursor      string    `form:"cursor"`
+	// Now la thoi diem de tinh Status
+	Now time.Time `form:"-"`
+}
+
+type ListRes struct {
+	Vouchers   []Voucher
+	NextCursor string
+}
+
+func (v Voucher) Status(now time.Time) string {
+	if now.Before(v.Start) {
+		return STATUS_UPCOMING
+	}
+	if now.After(v.End) {
+		return STATUS_EXPIRED
+	}
+	if v.Disabled {
+		return STATUS_INACTIVE
+	}
+	if v.IsExhausted() {
+		return STATUS_EXHAUSTED
+	}
+	return STATUS_ACTIVE
+}
+
+func (r ListReq) GetSort() string {
+	if r.Sort == "" {
+		return "id"
+	}
+	return r.Sort
+}
+
+func (r ListReq) GetLimit() int {
+	if r.Limit <= 0 {
+		return LIST_LIMIT
+	}
+	if r.Limit > LIST_MAX_LIMIT {
+		return LIST_MAX_LIMIT
+	}
+	return r.Limit
+}
+
+// Match kiem tra voucher co thoa cac dieu kien loc khong
+func (r ListReq) Match(v Voucher) bool {
+	// Giong LIKE cua MySQL/SQLite, khong phan biet hoa thuong
+	if !strings.HasPrefix(strings.ToUpper(v.Code), strings.ToUpper(r.CodePrefix)) {
+		return false
+	}
+	if !r.ActiveAt.IsZero() && (r.ActiveAt.Before(v.Start) || r.ActiveAt.After(v.End)) {
+		return false
+	}
+	if r.MinDiscount > 0 && v.Discount < r.MinDiscount {
+		return false
+	}
+	if r.MaxDiscount > 0 && v.Discount > r.MaxDiscount {
+		return false
+	}
+	if r.Status != "" && v.Status(r.Now) != r.Status {
+		return false
+	}
+	return true
+}
+
+// Less so sanh theo Sort roi toi Id, dao nguoc neu Desc
+func (r ListReq) Less(a Voucher, b Voucher) bool {
+	cmp := 0
+	switch r.GetSort() {
+	case "code":
+		cmp = strings.Compare(a.Code, b.Code)
+	case "start":
+		cmp = compareTime(a.Start, b.Start)
+	case "end":
+		cmp = compareTime(a.End, b.End)
+	case "discount":
+		if a.Discount < b.Discount {
+			cmp = -1
+		} else if a.Discount > b.Discount {
+			cmp = 1
+		}
+	}
+	if cmp == 0 {
+		cmp = a.Id - b.Id
+	}
+	if r.Desc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+func compareTime(a time.Time, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	}
+	if a.After(b) {
+		return 1
+	}
+	return 0
+}
+
+// listCursor gan cursor voi Sort va Desc da tao ra no,
+// dung cursor voi sort khac se bo sot hoac lap lai voucher
+type listCursor struct {
+	Sort    string  `json:"s"`
+	Desc    bool    `json:"d,omitempty"`
+	Voucher Voucher `json:"v"`
+}
+
+// EncodeCursor luu lai sort va gia tri cua cac field dung de sort cua voucher cuoi trang
+func (r ListReq) EncodeCursor(v Voucher) string {
+	data, _ := json.Marshal(listCursor{
+		Sort: r.GetSort(),
+		Desc: r.Desc,
+		Voucher: Voucher{
+			Id:       v.Id,
+			Code:     v.Code,
+			Discount: v.Discount,
+			Start:    v.Start.UTC(),
+			End:      v.End.UTC(),
+		},
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor tra ve voucher cuoi cua trang truoc, nil neu la trang dau.
+// Cursor cua sort khac tra ve ErrInvalidCursor.
+func (r ListReq) DecodeCursor() (*Voucher, error) {
+	if r.Cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(r.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := listCursor{}
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != r.GetSort() || cursor.Desc != r.Desc || cursor.Voucher.Id == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor.Voucher, nil
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Trang thai cua reservation
+const (
+	RESERVATION_RESERVED  = "reserved"
+	RESERVATION_CONFIRMED = "confirmed"
+	RESERVATION_RELEASED  = "released"
+	RESERVATION_EXPIRED   = "expired"
+)
+
+var (
+	ErrReservationNotFound = errors.New("Reservation not found")
+	ErrReservationExpired  = errors.New("Reservation is expired")
+	ErrReservationClosed   = errors.New("Reservation is already confirmed or released")
+)
+
+// Reservation giu cho mot lan dung voucher trong luc thanh toan.
+// Khi reserve thi da tinh vao quota, confirm thi ghi vao redemption,
+// release hoac het han thi tra lai quota.
+type Reservation struct {
+	Id        int
+	VoucherId int
+	Customer  string
+	Amount    float32
+	Discount  float32
+	Status    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type ReserveRes struct {
+	VerifyRes
+	Reservation *Reservation
+}
+
+func (r Reservation) IsExpired(now time.Time) bool {
+	return now.After(r.ExpiresAt)
+}
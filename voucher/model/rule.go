@@ -0,0 +1,188 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Loai discount cua voucher
+const (
+	// Discount la ti le (0, 1] cua gia tri don hang
+	DISCOUNT_PERCENT = "percent"
+	// Discount la so tien duoc giam
+	DISCOUNT_FIXED = "fixed"
+)
+
+// Ly do ma evaluator ap dung hoac khong ap dung voucher
+const (
+	REASON_APPLIED       = "APPLIED"
+	REASON_CAPPED        = "CAPPED"
+	REASON_MIN_ORDER     = "MIN_ORDER"
+	REASON_CATEGORY      = "CATEGORY"
+	REASON_NOT_STACKABLE = "NOT_STACKABLE"
+)
+
+var ErrInvalidRule = errors.New("Invalid discount rule")
+
+// Rule la cac dieu kien de ap dung Discount cua voucher
+type Rule struct {
+	Type        string  // percent (mac dinh) hoac fixed
+	DiscountCap float32 // So tien giam toi da, 0 la khong gioi han
+	MinOrder    float32 // Gia tri don hang toi thieu
+	// Chi tinh discount tren cac san pham thuoc nhung category nay, rong la tat ca
+	Categories []string
+	// Co duoc dung chung voi voucher khac trong cung don hang hay khong
+	Stackable bool
+}
+
+type OrderItem struct {
+	Category string
+	Amount   float32
+}
+
+// Order la don hang can tinh discount, Amount = 0 thi lay tong cua Items
+type Order struct {
+	Amount float32
+	Items  []OrderItem
+}
+
+type Reason struct {
+	Code    string
+	Message string
+}
+
+// Evaluation la ket qua ap dung mot voucher cho don hang
+type Evaluation struct {
+	Code       string
+	Applicable bool
+	Eligible   float32 // Phan gia tri don hang duoc tinh discount
+	Discount   float32
+	Reasons    []Reason
+}
+
+func (r Rule) GetType() string {
+	if r.Type == "" {
+		return DISCOUNT_PERCENT
+	}
+	return r.Type
+}
+
+// Validate kiem tra rule cung voi discount cua voucher
+func (r Rule) Validate(discount float32) error {
+	switch r.GetType() {
+	case DISCOUNT_PERCENT:
+		if discount <= 0 || discount > 1 {
+			return fmt.Errorf("%v: percent discount must be in (0, 1]", ErrInvalidRule)
+		}
+	case DISCOUNT_FIXED:
+		if discount <= 0 {
+			return fmt.Errorf("%v: fixed discount must be positive", ErrInvalidRule)
+		}
+	default:
+		return fmt.Errorf("%v: type must be percent or fixed", ErrInvalidRule)
+	}
+	if r.DiscountCap < 0 || r.MinOrder < 0 {
+		return fmt.Errorf("%v: discount cap and min order must not be negative", ErrInvalidRule)
+	}
+	// Categories duoc luu thanh chuoi phan cach boi dau phay
+	for _, category := range r.Categories {
+		if category == "" || strings.Contains(category, ",") {
+			return fmt.Errorf("%v: category must not be empty or contain comma", ErrInvalidRule)
+		}
+	}
+	return nil
+}
+
+func (r Rule) hasCategory(category string) bool {
+	for _, c := range r.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+func (o Order) GetAmount() float32 {
+	if o.Amount > 0 || len(o.Items) == 0 {
+		return o.Amount
+	}
+	var amount float32
+	for _, item := range o.Items {
+		amount += item.Amount
+	}
+	return amount
+}
+
+func (e *Evaluation) reject(code string, format string, args ...interface{}) {
+	e.Applicable = false
+	e.Discount = 0
+	e.Reasons = append(e.Reasons, Reason{Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// Evaluate tinh discount cua voucher cho order (khong kiem tra thoi gian hay quota)
+func (v Voucher) Evaluate(order Order) Evaluation {
+	e := Evaluation{Code: v.Code, Applicable: true}
+	amount := order.GetAmount()
+	if amount < v.MinOrder {
+		e.reject(REASON_MIN_ORDER, "order amount %.2f is less than min order %.2f", amount, v.MinOrder)
+	}
+	e.Eligible = amount
+	if len(v.Categories) > 0 {
+		e.Eligible = 0
+		for _, item := range order.Items {
+			if v.hasCategory(item.Category) {
+				e.Eligible += item.Amount
+			}
+		}
+		if e.Eligible == 0 {
+			e.reject(REASON_CATEGORY, "order has no item in categories %v", v.Categories)
+		}
+	}
+	if !e.Applicable {
+		return e
+	}
+	if v.GetType() == DISCOUNT_FIXED {
+		e.Discount = v.Discount
+		if e.Discount > e.Eligible {
+			e.Discount = e.Eligible
+		}
+		e.Reasons = append(e.Reasons, Reason{REASON_APPLIED, fmt.Sprintf("fixed %.2f on %.2f", v.Discount, e.Eligible)})
+	} else {
+		e.Discount = e.Eligible * v.Discount
+		e.Reasons = append(e.Reasons, Reason{REASON_APPLIED, fmt.Sprintf("%g%% of %.2f", v.Discount*100, e.Eligible)})
+	}
+	if v.DiscountCap > 0 && e.Discount > v.DiscountCap {
+		e.Discount = v.DiscountCap
+		e.Reasons = append(e.Reasons, Reason{REASON_CAPPED, fmt.Sprintf("discount is capped at %.2f", v.DiscountCap)})
+	}
+	return e
+}
+
+// Stack ap dung lan luot cac voucher cho cung mot order.
+// Voucher khong Stackable chi duoc dung mot minh: bi tu choi neu da co voucher
+// duoc ap dung truoc do, va neu duoc ap dung thi cac voucher sau bi tu choi.
+// Tong discount khong vuot qua gia tri don hang.
+func Stack(vouchers []Voucher, order Order) ([]Evaluation, float32) {
+	evaluations := []Evaluation{}
+	remaining := order.GetAmount()
+	applied := 0
+	exclusive := false
+	for _, v := range vouchers {
+		e := v.Evaluate(order)
+		if e.Applicable && (exclusive || (applied > 0 && !v.Stackable)) {
+			e.reject(REASON_NOT_STACKABLE, "voucher cannot be combined with other vouchers")
+		}
+		if e.Applicable {
+			if e.Discount > remaining {
+				e.Discount = remaining
+				e.Reasons = append(e.Reasons, Reason{REASON_CAPPED, fmt.Sprintf("discount is capped at remaining total %.2f", remaining)})
+			}
+			remaining -= e.Discount
+			applied++
+			exclusive = !v.Stackable
+		}
+		evaluations = append(evaluations, e)
+	}
+	return evaluations, order.GetAmount() - remaining
+}
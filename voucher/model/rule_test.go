@@ -0,0 +1,71 @@
+package model
+
+import "testing"
+
+func Test_Evaluate(t *testing.T) {
+	items := []OrderItem{{Category: "book", Amount: 60}, {Category: "food", Amount: 40}}
+	tests := []struct {
+		name       string
+		voucher    Voucher
+		order      Order
+		applicable bool
+		discount   float32
+		reason     string
+	}{
+		{"percent", Voucher{Discount: 0.1}, Order{Amount: 100}, true, 10, REASON_APPLIED},
+		{"amount from items", Voucher{Discount: 0.1}, Order{Items: items}, true, 10, REASON_APPLIED},
+		{"fixed", Voucher{Discount: 30, Rule: Rule{Type: DISCOUNT_FIXED}}, Order{Amount: 100}, true, 30, REASON_APPLIED},
+		{"fixed over amount", Voucher{Discount: 300, Rule: Rule{Type: DISCOUNT_FIXED}}, Order{Amount: 100}, true, 100, REASON_APPLIED},
+		{"capped", Voucher{Discount: 0.5, Rule: Rule{DiscountCap: 20}}, Order{Amount: 100}, true, 20, REASON_CAPPED},
+		{"min order", Voucher{Discount: 0.1, Rule: Rule{MinOrder: 200}}, Order{Amount: 100}, false, 0, REASON_MIN_ORDER},
+		{"category", Voucher{Discount: 0.5, Rule: Rule{Categories: []string{"book"}}}, Order{Items: items}, true, 30, REASON_APPLIED},
+		{"no category", Voucher{Discount: 0.5, Rule: Rule{Categories: []string{"toy"}}}, Order{Items: items}, false, 0, REASON_CATEGORY},
+	}
+	for _, test := range tests {
+		e := test.voucher.Evaluate(test.order)
+		if e.Applicable != test.applicable || e.Discount != test.discount {
+			t.Error(test.name, "got", e)
+		}
+		if e.Reasons[len(e.Reasons)-1].Code != test.reason {
+			t.Error(test.name, "last reason should be", test.reason, "got", e.Reasons)
+		}
+	}
+}
+
+func Test_Stack(t *testing.T) {
+	stackable := Voucher{Code: "A", Discount: 0.1, Rule: Rule{Stackable: true}}
+	fixed := Voucher{Code: "B", Discount: 95, Rule: Rule{Type: DISCOUNT_FIXED, Stackable: true}}
+	single := Voucher{Code: "C", Discount: 0.2}
+	evaluations, discount := Stack([]Voucher{stackable, fixed, single}, Order{Amount: 100})
+	if discount != 100 {
+		t.Error("Discount should not be over order amount, got", discount)
+	}
+	if evaluations[1].Discount != 90 || evaluations[1].Reasons[1].Code != REASON_CAPPED {
+		t.Error("Second voucher should be capped at remaining total", evaluations[1])
+	}
+	if evaluations[2].Applicable || evaluations[2].Reasons[1].Code != REASON_NOT_STACKABLE {
+		t.Error("Not stackable voucher should be rejected after other vouchers", evaluations[2])
+	}
+	evaluations, discount = Stack([]Voucher{single, stackable}, Order{Amount: 100})
+	if discount != 20 || evaluations[1].Applicable {
+		t.Error("Vouchers after not stackable voucher should be rejected", evaluations)
+	}
+}
+
+func Test_RuleValidate(t *testing.T) {
+	if err := (Rule{}).Validate(0.5); err != nil {
+		t.Error(err)
+	}
+	if err := (Rule{}).Validate(20); err == nil {
+		t.Error("Percent discount over 1 should be invalid")
+	}
+	if err := (Rule{Type: DISCOUNT_FIXED}).Validate(20); err != nil {
+		t.Error(err)
+	}
+	if err := (Rule{Type: "free"}).Validate(20); err == nil {
+		t.Error("Unknown type should be invalid")
+	}
+	if err := (Rule{Categories: []string{"book,comic"}}).Validate(0.5); err == nil {
+		t.Error("Category with comma should be invalid")
+	}
+}
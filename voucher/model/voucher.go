@@ -1,11 +1,158 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrCustomerRequired: voucher co MaxPerCustomer thi phai biet customer moi dem duoc so lan da dung
+var ErrCustomerRequired = errors.New("Customer is required for voucher with per-customer limit")
 
 type Voucher struct {
-	Id       int
-	Code     string
+	Id             int
+	Code           string
+	Discount       float32
+	Start          time.Time
+	End            time.Time
+	Quota          int // Tong so lan duoc dung, mac dinh la 1
+	MaxPerCustomer int // So lan toi da moi customer duoc dung, 0 la khong gioi han
+	Used           int
+	Rule
+	Disabled  bool       // Bi deactivate, khong dung duoc cho toi khi reactivate
+	DeletedAt *time.Time `json:",omitempty"`
+}
+
+// Ghi lai moi lan mot customer redeem voucher
+type Redemption struct {
+	Id        int
+	VoucherId int
+	Customer  string
+	Amount    float32
+	Discount  float32
+	CreatedAt time.Time
+}
+
+// Ly do ma mot voucher bi tu choi khi verify/redeem
+const (
+	VERIFY_OK          = "OK"
+	VERIFY_NOT_FOUND   = "NOT_FOUND"
+	VERIFY_EXPIRED     = "EXPIRED"
+	VERIFY_NOT_STARTED = "NOT_STARTED"
+	VERIFY_EXHAUSTED   = "EXHAUSTED"
+	// Customer da dung het so lan cho phep cua voucher
+	VERIFY_CUSTOMER_LIMIT = "CUSTOMER_LIMIT"
+	// Don hang khong thoa rule cua voucher, xem Reasons
+	VERIFY_NOT_APPLICABLE = "NOT_APPLICABLE"
+	// Voucher dang bi deactivate
+	VERIFY_INACTIVE = "INACTIVE"
+)
+
+type VerifyReq struct {
+	Code     string  `form:"code" binding:"required"`
+	Amount   float32 `form:"amount"`
+	Customer string  `form:"customer"`
+	// Cac san pham trong don hang, can cho voucher chi ap dung cho mot so category
+	Items []OrderItem
+}
+
+type VerifyRes struct {
+	Status   string
+	Voucher  *Voucher
+	Amount   float32
 	Discount float32
-	Start    time.Time
-	End      time.Time
+	Total    float32
+	Reasons  []Reason
+}
+
+func (r VerifyReq) Order() Order {
+	return Order{Amount: r.Amount, Items: r.Items}
+}
+
+// IsOverlap: cung code va khoang start/end giao nhau (tinh ca bien),
+// giong dieu kien cua COUNT_EXIST
+func (v Voucher) IsOverlap(o Voucher) bool {
+	return v.Code == o.Code && !v.End.Before(o.Start) && !v.Start.After(o.End)
+}
+
+func (v Voucher) GetQuota() int {
+	if v.Quota <= 0 {
+		return 1
+	}
+	return v.Quota
+}
+
+func (v Voucher) IsExhausted() bool {
+	return v.Used >= v.GetQuota()
+}
+
+// Pick chon ra voucher dang co hieu luc tai thoi diem now trong cac voucher
+// cung code (cac khoang start/end khong overlap nhau).
+// Neu khong co voucher nao hop le thi tra ve ly do bi tu choi.
+func Pick(vouchers []Voucher, now time.Time) (*Voucher, string) {
+	if len(vouchers) == 0 {
+		return nil, VERIFY_NOT_FOUND
+	}
+	status := VERIFY_EXPIRED
+	for i := range vouchers {
+		v := &vouchers[i]
+		if now.Before(v.Start) {
+			status = VERIFY_NOT_STARTED
+			continue
+		}
+		if now.After(v.End) {
+			continue
+		}
+		if v.Disabled {
+			return v, VERIFY_INACTIVE
+		}
+		if v.IsExhausted() {
+			return v, VERIFY_EXHAUSTED
+		}
+		return v, VERIFY_OK
+	}
+	return nil, status
+}
+
+// Applicable kiem tra rule cua voucher dang co hieu luc voi don hang cua req
+func Applicable(voucher *Voucher, status string, req VerifyReq) string {
+	if status == VERIFY_OK && !voucher.Evaluate(req.Order()).Applicable {
+		return VERIFY_NOT_APPLICABLE
+	}
+	return status
+}
+
+// Result tao ra ket qua verify cho don hang cua req
+func Result(voucher *Voucher, status string, req VerifyReq) *VerifyRes {
+	order := req.Order()
+	res := &VerifyRes{
+		Status:  status,
+		Voucher: voucher,
+		Amount:  order.GetAmount(),
+		Total:   order.GetAmount(),
+	}
+	if status == VERIFY_OK || status == VERIFY_NOT_APPLICABLE {
+		evaluation := voucher.Evaluate(order)
+		res.Reasons = evaluation.Reasons
+		res.Discount = evaluation.Discount
+		res.Total -= evaluation.Discount
+	}
+	return res
+}
+
+// EvaluateReq tinh discount khi dung chung nhieu voucher cho mot don hang
+type EvaluateReq struct {
+	Codes  []string `binding:"required,min=1,max=10"`
+	Amount float32
+	Items  []OrderItem
+}
+
+type EvaluateRes struct {
+	Evaluations []Evaluation
+	Amount      float32
+	Discount    float32
+	Total       float32
+}
+
+func (r EvaluateReq) Order() Order {
+	return Order{Amount: r.Amount, Items: r.Items}
 }
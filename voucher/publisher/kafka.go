@@ -0,0 +1,76 @@
+package publisher
+
+import (
+	"errors"
+	"strconv"
+
+	"../model"
+
+	"github.com/Shopify/sarama"
+	"github.com/uber-go/kafka-client/kafka"
+)
+
+// Kafka publish event cua outbox len mot topic.
+// kafka-client chi co consumer nen producer dung sarama (kafka-client cung dung sarama ben duoi),
+// broker duoc tim qua NameResolver giong nhu kafka/main.go.
+type Kafka struct {
+	Topic    string
+	producer sarama.SyncProducer
+}
+
+// NewKafka tao producer cho topic, broker lay tu cluster cua topic trong resolver
+func NewKafka(resolver kafka.NameResolver, topic string) (*Kafka, error) {
+	clusters, err := resolver.ResolveClusterForTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		return nil, errors.New("No cluster for topic " + topic)
+	}
+	brokers, err := resolver.ResolveIPForCluster(clusters[0])
+	if err != nil {
+		return nil, err
+	}
+	config := sarama.NewConfig()
+	// Header cua message can Kafka >= 0.11
+	config.Version = sarama.V0_11_0_0
+	// Cho tat ca replica ghi xong moi tinh la publish thanh cong
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Return.Successes = true
+	// Cung key thi cung partition, giu thu tu event cua moi voucher
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	// Khong retry song song de cac message khong bi dao thu tu
+	config.Net.MaxOpenRequests = 1
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+	return &Kafka{Topic: topic, producer: producer}, nil
+}
+
+// NewStaticKafka dung mot cluster co dinh, vd: NewStaticKafka([]string{"127.0.0.1:9092"}, "voucher_events")
+func NewStaticKafka(brokers []string, topic string) (*Kafka, error) {
+	resolver := kafka.NewStaticNameResolver(
+		map[string][]string{topic: []string{"voucher_cluster"}},
+		map[string][]string{"voucher_cluster": brokers},
+	)
+	return NewKafka(resolver, topic)
+}
+
+func (k *Kafka) Publish(event model.Event) error {
+	_, _, err := k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: k.Topic,
+		Key:   sarama.StringEncoder(strconv.Itoa(event.VoucherId)),
+		Value: sarama.ByteEncoder(event.Payload),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("type"), Value: []byte(event.Type)},
+			{Key: []byte("event_id"), Value: []byte(strconv.Itoa(event.Id))},
+		},
+	})
+	return err
+}
+
+func (k *Kafka) Close() error {
+	return k.producer.Close()
+}
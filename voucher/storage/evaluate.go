@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"time"
+
+	"../model"
+)
+
+// Evaluate tinh discount khi dung chung cac voucher trong req cho mot don hang,
+// khong tinh vao quota. Voucher khong dung duoc (het han, khong ton tai, het quota...)
+// co Reason la status cua Verify. Code lap lai chi duoc tinh mot lan,
+// ket qua theo thu tu cua Codes.
+func Evaluate(store VoucherStore, req model.EvaluateReq, now time.Time) (*model.EvaluateRes, error) {
+	order := req.Order()
+	evaluations := []model.Evaluation{}
+	active := []model.Voucher{}
+	// Vi tri trong evaluations cua tung voucher trong active
+	indexes := []int{}
+	seen := map[string]bool{}
+	for _, code := range req.Codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		vouchers, err := store.Find(code)
+		if err != nil {
+			return nil, err
+		}
+		voucher, status := model.Pick(vouchers, now)
+		if status != model.VERIFY_OK {
+			evaluations = append(evaluations, model.Evaluation{
+				Code:    code,
+				Reasons: []model.Reason{{Code: status, Message: "voucher cannot be used"}},
+			})
+			continue
+		}
+		indexes = append(indexes, len(evaluations))
+		evaluations = append(evaluations, model.Evaluation{})
+		active = append(active, *voucher)
+	}
+	stacked, discount := model.Stack(active, order)
+	for i, e := range stacked {
+		evaluations[indexes[i]] = e
+	}
+	return &model.EvaluateRes{
+		Evaluations: evaluations,
+		Amount:      order.GetAmount(),
+		Discount:    discount,
+		Total:       order.GetAmount() - discount,
+	}, nil
+}
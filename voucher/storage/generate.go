@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+
+	"../model"
+)
+
+const GENERATE_BATCH_SIZE = 500
+
+// So lan lien tiep ma mot batch khong insert duoc code nao thi dung lai
+const GENERATE_MAX_RETRY = 10
+
+var ErrNotEnoughCodes = errors.New("Not enough unique codes for this alphabet and length")
+
+// GenerateBatch sinh ra req.Count voucher voi code khong trung voi bat ky voucher nao,
+// insert tung batch GENERATE_BATCH_SIZE voucher trong mot transaction (RegisterBatch).
+// Code bi trung voi voucher da co se duoc sinh lai o batch sau.
+// fn duoc goi voi cac voucher da insert sau moi batch, de co the stream ve cho client.
+func GenerateBatch(store VoucherStore, req model.GenerateReq, fn func([]model.Voucher) error) error {
+	if !req.CanGenerate() {
+		return ErrNotEnoughCodes
+	}
+	seen := map[string]bool{}
+	remain := req.Count
+	retry := 0
+	for remain > 0 {
+		size := remain
+		if size > GENERATE_BATCH_SIZE {
+			size = GENERATE_BATCH_SIZE
+		}
+		vouchers := make([]model.Voucher, 0, size)
+		for len(vouchers) < size {
+			code, err := req.NewCode()
+			if err != nil {
+				return err
+			}
+			if seen[code] {
+				continue
+			}
+			seen[code] = true
+			vouchers = append(vouchers, req.NewVoucher(code))
+		}
+		inserted, err := store.RegisterBatch(vouchers)
+		if err != nil {
+			return err
+		}
+		if len(inserted) == 0 {
+			retry++
+			if retry >= GENERATE_MAX_RETRY {
+				return ErrNotEnoughCodes
+			}
+			continue
+		}
+		retry = 0
+		remain -= len(inserted)
+		if err = fn(inserted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"../model"
+)
+
+// IdempotencyStore luu idempotency key va ket qua cua request dau tien
+type IdempotencyStore interface {
+	// BeginIdempotency giu key neu chua co (hoac da het han) va tra ve nil.
+	// Neu key da chay xong voi cung request thi tra ve ket qua cu,
+	// con dang chay thi ErrIdempotencyInProgress, khac request thi ErrIdempotencyMismatch
+	BeginIdempotency(record model.Idempotency, now time.Time) (*model.Idempotency, error)
+	// FinishIdempotency luu response va gia han key toi expiresAt
+	FinishIdempotency(scope string, key string, response []byte, expiresAt time.Time) error
+	// AbortIdempotency xoa key khi request bi loi de client co the retry
+	AbortIdempotency(scope string, key string) error
+	// ExpireIdempotency xoa cac key qua han, tra ve so key bi xoa
+	ExpireIdempotency(now time.Time) (int, error)
+}
+
+// Idempotent chay fn va luu res (JSON) theo key trong ttl.
+// Goi lai voi cung key se doc res da luu ma khong chay fn, tra ve true.
+// Trong luc fn chay key chi duoc giu trong IDEMPOTENCY_LEASE.
+// key rong thi chi chay fn.
+func Idempotent(store IdempotencyStore, scope string, key string, req interface{}, res interface{},
+	ttl time.Duration, fn func() error) (bool, error) {
+	if key == "" {
+		return false, fn()
+	}
+	hash, err := hashRequest(req)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	existing, err := store.BeginIdempotency(model.Idempotency{
+		Scope:     scope,
+		Key:       key,
+		Hash:      hash,
+		ExpiresAt: now.Add(model.IDEMPOTENCY_LEASE),
+		CreatedAt: now,
+	}, now)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return true, json.Unmarshal(existing.Response, res)
+	}
+	if err = fn(); err != nil {
+		if abortErr := store.AbortIdempotency(scope, key); abortErr != nil {
+			log.Println("Abort idempotency key:", abortErr)
+		}
+		return false, err
+	}
+	// fn da chay xong, khong luu duoc ket qua thi key se giu trang thai dang chay toi khi het lease
+	response, err := json.Marshal(res)
+	if err == nil {
+		err = store.FinishIdempotency(scope, key, response, now.Add(ttl))
+	}
+	if err != nil {
+		log.Println("Finish idempotency key:", err)
+	}
+	return false, nil
+}
+
+func hashRequest(req interface{}) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replay kiem tra key da ton tai co dung cho cung request va da chay xong chua
+func replay(existing *model.Idempotency, hash string) (*model.Idempotency, error) {
+	if existing.Hash != hash {
+		return nil, model.ErrIdempotencyMismatch
+	}
+	if !existing.IsDone() {
+		return nil, model.ErrIdempotencyInProgress
+	}
+	return existing, nil
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"time"
+
+	"../model"
+)
+
+func Deactivate(store VoucherStore, id int, actor string, now time.Time) (*model.Voucher, error) {
+	return store.Change(id, model.ACTION_DEACTIVATE, actor, now, model.Deactivate)
+}
+
+func Reactivate(store VoucherStore, id int, actor string, now time.Time) (*model.Voucher, error) {
+	return store.Change(id, model.ACTION_REACTIVATE, actor, now, model.Reactivate)
+}
+
+// Extend keo dai end cua voucher, tra ve ErrExist neu bi overlap voi voucher khac cung code
+func Extend(store VoucherStore, id int, end time.Time, actor string, now time.Time) (*model.Voucher, error) {
+	return store.Change(id, model.ACTION_EXTEND, actor, now, model.Extend(end))
+}
+
+// Delete xoa mem voucher, voucher bi xoa khong con duoc tim thay va khong chan code bi overlap nua
+func Delete(store VoucherStore, id int, actor string, now time.Time) (*model.Voucher, error) {
+	return store.Change(id, model.ACTION_DELETE, actor, now, model.Delete(now))
+}
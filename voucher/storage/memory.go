@@ -0,0 +1,452 @@
+package storage
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"../model"
+)
+
+// Memory luu voucher trong bo nho, dung cho test hoac chay thu ma khong can DB.
+// Moi thao tac deu giu mutex nen khong the bi duplicate hay vuot quota.
+type Memory struct {
+	mutex        sync.Mutex
+	vouchers     []model.Voucher
+	redemptions  []model.Redemption
+	reservations []model.Reservation
+	idempotency  map[string]model.Idempotency
+	audits       []model.Audit
+	events       []model.Event
+	published    map[int]time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		idempotency: map[string]model.Idempotency{},
+		published:   map[int]time.Time{},
+	}
+}
+
+func (s *Memory) isExist(voucher model.Voucher) bool {
+	for _, v := range s.vouchers {
+		if !v.IsDeleted() && v.IsOverlap(voucher) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Memory) IsExist(voucher model.Voucher) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isExist(voucher), nil
+}
+
+func (s *Memory) Register(voucher *model.Voucher) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.isExist(*voucher) {
+		return ErrExist
+	}
+	s.insert(voucher)
+	return nil
+}
+
+// insert phai duoc goi khi dang giu mutex
+func (s *Memory) insert(voucher *model.Voucher) {
+	voucher.Id = len(s.vouchers) + 1
+	voucher.Quota = voucher.GetQuota()
+	voucher.Type = voucher.GetType()
+	voucher.Used = 0
+	s.vouchers = append(s.vouchers, *voucher)
+	s.addEvent(model.EVENT_VOUCHER_REGISTERED, voucher.Id, voucher, time.Now())
+}
+
+// RegisterNaive kiem tra IsExist roi moi insert trong 2 lan giu mutex,
+// giong Voucher.RegisterNaive nen request khac co the insert chen giua
+func (s *Memory) RegisterNaive(voucher *model.Voucher) error {
+	isExist, _ := s.IsExist(*voucher)
+	if isExist {
+		return ErrExist
+	}
+	// Nhuong CPU nhu khi doi round trip toi database giua 2 buoc
+	runtime.Gosched()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.insert(voucher)
+	return nil
+}
+
+func (s *Memory) RegisterBatch(vouchers []model.Voucher) ([]model.Voucher, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	inserted := []model.Voucher{}
+	for _, voucher := range vouchers {
+		if s.isUsed(voucher.Code) {
+			continue
+		}
+		voucher.Id = len(s.vouchers) + 1
+		voucher.Quota = voucher.GetQuota()
+		voucher.Type = voucher.GetType()
+		voucher.Used = 0
+		s.vouchers = append(s.vouchers, voucher)
+		s.addEvent(model.EVENT_VOUCHER_REGISTERED, voucher.Id, voucher, time.Now())
+		inserted = append(inserted, voucher)
+	}
+	return inserted, nil
+}
+
+// isUsed: code da tung duoc dung boi voucher nao chua, ke ca voucher da bi xoa
+func (s *Memory) isUsed(code string) bool {
+	for _, v := range s.vouchers {
+		if v.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Memory) find(code string) []model.Voucher {
+	vouchers := []model.Voucher{}
+	for _, v := range s.vouchers {
+		if v.Code == code && !v.IsDeleted() {
+			vouchers = append(vouchers, v)
+		}
+	}
+	sort.SliceStable(vouchers, func(i, j int) bool {
+		return vouchers[i].Start.Before(vouchers[j].Start)
+	})
+	return vouchers
+}
+
+func (s *Memory) Find(code string) ([]model.Voucher, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.find(code), nil
+}
+
+func (s *Memory) List(req model.ListReq) (*model.ListRes, error) {
+	cursor, err := req.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	vouchers := []model.Voucher{}
+	for _, v := range s.vouchers {
+		if !v.IsDeleted() && req.Match(v) && (cursor == nil || req.Less(*cursor, v)) {
+			vouchers = append(vouchers, v)
+		}
+	}
+	sort.SliceStable(vouchers, func(i, j int) bool {
+		return req.Less(vouchers[i], vouchers[j])
+	})
+	return page(vouchers, req), nil
+}
+
+func (s *Memory) check(req model.VerifyReq, now time.Time) (*model.Voucher, string, error) {
+	voucher, status := model.Pick(s.find(req.Code), now)
+	status = model.Applicable(voucher, status, req)
+	if status != model.VERIFY_OK || voucher.MaxPerCustomer <= 0 {
+		return voucher, status, nil
+	}
+	if req.Customer == "" {
+		return nil, "", model.ErrCustomerRequired
+	}
+	count := 0
+	for _, r := range s.redemptions {
+		if r.VoucherId == voucher.Id && r.Customer == req.Customer {
+			count++
+		}
+	}
+	for _, r := range s.reservations {
+		if r.VoucherId == voucher.Id && r.Customer == req.Customer && r.Status == model.RESERVATION_RESERVED {
+			count++
+		}
+	}
+	if count >= voucher.MaxPerCustomer {
+		return voucher, model.VERIFY_CUSTOMER_LIMIT, nil
+	}
+	return voucher, status, nil
+}
+
+func (s *Memory) Verify(req model.VerifyReq, now time.Time) (*model.VerifyRes, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	voucher, status, err := s.check(req, now)
+	if err != nil {
+		return nil, err
+	}
+	return model.Result(voucher, status, req), nil
+}
+
+// claim tang so lan da dung cua voucher neu voucher van dung duoc
+func (s *Memory) claim(req model.VerifyReq, now time.Time) (*model.VerifyRes, bool, error) {
+	voucher, status, err := s.check(req, now)
+	if err != nil {
+		return nil, false, err
+	}
+	if status != model.VERIFY_OK {
+		return model.Result(voucher, status, req), false, nil
+	}
+	// Id bat dau tu 1 va khong bao gio bi xoa
+	s.vouchers[voucher.Id-1].Used++
+	voucher.Used++
+	return model.Result(voucher, status, req), true, nil
+}
+
+func (s *Memory) addRedemption(voucherId int, customer string, amount float32, discount float32, now time.Time) {
+	redemption := model.Redemption{
+		Id:        len(s.redemptions) + 1,
+		VoucherId: voucherId,
+		Customer:  customer,
+		Amount:    amount,
+		Discount:  discount,
+		CreatedAt: now,
+	}
+	s.redemptions = append(s.redemptions, redemption)
+	s.addEvent(model.EVENT_VOUCHER_REDEEMED, voucherId, redemption, now)
+}
+
+func (s *Memory) addEvent(eventType string, voucherId int, payload interface{}, now time.Time) {
+	// Voucher va Redemption luon marshal duoc nen bo qua loi
+	event, _ := model.NewEvent(eventType, voucherId, payload, now)
+	event.Id = len(s.events) + 1
+	s.events = append(s.events, *event)
+}
+
+func (s *Memory) Redeem(req model.VerifyReq, now time.Time) (*model.VerifyRes, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	res, ok, err := s.claim(req, now)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		s.addRedemption(res.Voucher.Id, req.Customer, res.Amount, res.Discount, now)
+	}
+	return res, nil
+}
+
+func (s *Memory) Reserve(req model.VerifyReq, now time.Time, ttl time.Duration) (*model.ReserveRes, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	res, ok, err := s.claim(req, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &model.ReserveRes{VerifyRes: *res}, nil
+	}
+	reservation := model.Reservation{
+		Id:        len(s.reservations) + 1,
+		VoucherId: res.Voucher.Id,
+		Customer:  req.Customer,
+		Amount:    res.Amount,
+		Discount:  res.Discount,
+		Status:    model.RESERVATION_RESERVED,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	s.reservations = append(s.reservations, reservation)
+	return &model.ReserveRes{VerifyRes: *res, Reservation: &reservation}, nil
+}
+
+func (s *Memory) reservation(id int) (*model.Reservation, error) {
+	if id < 1 || id > len(s.reservations) {
+		return nil, model.ErrReservationNotFound
+	}
+	return &s.reservations[id-1], nil
+}
+
+// closeReservation chuyen reservation sang released/expired va tra lai quota
+func (s *Memory) closeReservation(r *model.Reservation, status string) {
+	r.Status = status
+	if s.vouchers[r.VoucherId-1].Used > 0 {
+		s.vouchers[r.VoucherId-1].Used--
+	}
+}
+
+func (s *Memory) Confirm(id int, now time.Time) (*model.Reservation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	r, err := s.reservation(id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case r.Status == model.RESERVATION_EXPIRED:
+		return nil, model.ErrReservationExpired
+	case r.Status != model.RESERVATION_RESERVED:
+		return nil, model.ErrReservationClosed
+	case r.IsExpired(now):
+		s.closeReservation(r, model.RESERVATION_EXPIRED)
+		return nil, model.ErrReservationExpired
+	}
+	r.Status = model.RESERVATION_CONFIRMED
+	s.addRedemption(r.VoucherId, r.Customer, r.Amount, r.Discount, now)
+	reservation := *r
+	return &reservation, nil
+}
+
+func (s *Memory) Release(id int) (*model.Reservation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	r, err := s.reservation(id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status == model.RESERVATION_CONFIRMED {
+		return nil, model.ErrReservationClosed
+	}
+	if r.Status == model.RESERVATION_RESERVED {
+		s.closeReservation(r, model.RESERVATION_RELEASED)
+	}
+	reservation := *r
+	return &reservation, nil
+}
+
+func (s *Memory) ExpireReservations(now time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	count := 0
+	for i := range s.reservations {
+		r := &s.reservations[i]
+		if r.Status == model.RESERVATION_RESERVED && r.IsExpired(now) {
+			s.closeReservation(r, model.RESERVATION_EXPIRED)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Memory) BeginIdempotency(record model.Idempotency, now time.Time) (*model.Idempotency, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	existing, ok := s.idempotency[record.Scope+"/"+record.Key]
+	if ok && !existing.IsExpired(now) {
+		return replay(&existing, record.Hash)
+	}
+	s.idempotency[record.Scope+"/"+record.Key] = record
+	return nil, nil
+}
+
+func (s *Memory) FinishIdempotency(scope string, key string, response []byte, expiresAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	record, ok := s.idempotency[scope+"/"+key]
+	if ok {
+		record.Response = response
+		record.ExpiresAt = expiresAt
+		s.idempotency[scope+"/"+key] = record
+	}
+	return nil
+}
+
+func (s *Memory) AbortIdempotency(scope string, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.idempotency, scope+"/"+key)
+	return nil
+}
+
+func (s *Memory) ExpireIdempotency(now time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	count := 0
+	for id, record := range s.idempotency {
+		if record.IsExpired(now) {
+			delete(s.idempotency, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Memory) findById(id int) (*model.Voucher, error) {
+	if id < 1 || id > len(s.vouchers) || s.vouchers[id-1].IsDeleted() {
+		return nil, model.ErrVoucherNotFound
+	}
+	return &s.vouchers[id-1], nil
+}
+
+func (s *Memory) FindById(id int) (*model.Voucher, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	voucher, err := s.findById(id)
+	if err != nil {
+		return nil, err
+	}
+	found := *voucher
+	return &found, nil
+}
+
+func (s *Memory) Change(id int, action string, actor string, now time.Time, change func(voucher *model.Voucher) error) (*model.Voucher, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	before, err := s.findById(id)
+	if err != nil {
+		return nil, err
+	}
+	after := *before
+	if err = change(&after); err != nil {
+		return nil, err
+	}
+	if reflect.DeepEqual(*before, after) {
+		return &after, nil
+	}
+	if !after.IsDeleted() {
+		for _, v := range s.vouchers {
+			if v.Id != after.Id && !v.IsDeleted() && v.IsOverlap(after) {
+				return nil, ErrExist
+			}
+		}
+	}
+	audit, err := model.NewAudit(action, actor, before, &after, now)
+	if err != nil {
+		return nil, err
+	}
+	audit.Id = len(s.audits) + 1
+	s.audits = append(s.audits, *audit)
+	*before = after
+	return &after, nil
+}
+
+func (s *Memory) Audits(voucherId int) ([]model.Audit, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	audits := []model.Audit{}
+	for _, a := range s.audits {
+		if a.VoucherId == voucherId {
+			audits = append(audits, a)
+		}
+	}
+	return audits, nil
+}
+
+func (s *Memory) PendingEvents(limit int) ([]model.Event, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events := []model.Event{}
+	for _, e := range s.events {
+		if len(events) >= limit {
+			break
+		}
+		if _, ok := s.published[e.Id]; !ok {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (s *Memory) MarkPublished(ids []int, now time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, id := range ids {
+		s.published[id] = now
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"../model"
+)
+
+// RELAY_BATCH_SIZE la so event toi da moi lan relay doc tu outbox
+const RELAY_BATCH_SIZE = 100
+
+// Publisher gui event ra ngoai (Kafka, ...). Event cua cung voucher phai duoc
+// publish vao cung partition (key = VoucherId) de giu thu tu.
+type Publisher interface {
+	Publish(event model.Event) error
+}
+
+// MemoryPublisher giu lai cac event da publish, dung cho test hoac khi khong co Kafka
+type MemoryPublisher struct {
+	mutex  sync.Mutex
+	events []model.Event
+}
+
+func (p *MemoryPublisher) Publish(event model.Event) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *MemoryPublisher) Events() []model.Event {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]model.Event{}, p.events...)
+}
+
+// Relay publish lan luot cac event chua publish theo thu tu ghi, dung lai o event dau tien bi loi
+// de khong event nao cua cung voucher bi vuot len truoc. Event da publish nhung chua kip
+// MarkPublished se bi publish lai (at-least-once), consumer phai bo qua event trung Id.
+func Relay(store VoucherStore, publisher Publisher) (int, error) {
+	events, err := store.PendingEvents(RELAY_BATCH_SIZE)
+	if err != nil {
+		return 0, err
+	}
+	ids := []int{}
+	var publishErr error
+	for _, event := range events {
+		if publishErr = publisher.Publish(event); publishErr != nil {
+			break
+		}
+		ids = append(ids, event.Id)
+	}
+	if err = store.MarkPublished(ids, time.Now()); err != nil {
+		return 0, err
+	}
+	return len(ids), publishErr
+}
+
+// StartRelay chay Relay moi interval cho toi khi het event. Goi ham tra ve de dung relay.
+func StartRelay(store VoucherStore, publisher Publisher, interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				for {
+					count, err := Relay(store, publisher)
+					if err != nil {
+						log.Println("Relay outbox:", err)
+						break
+					}
+					if count < RELAY_BATCH_SIZE {
+						break
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
@@ -0,0 +1,688 @@
+package storage
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"time"
+
+	"../model"
+)
+
+// Cac ham dung chung cho nhung backend dung database/sql (MySQL, SQLite)
+
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// register chay cau insert co dieu kien (REGISTER_ATOMIC, REGISTER_ISOLATION, REGISTER_UNIQUE)
+// voi cac tham so cua dieu kien la where, khong co row nao duoc insert nghia la bi trung
+// register insert voucher va event VoucherRegistered trong cung tx
+func register(tx *sql.Tx, query string, voucher *model.Voucher, where ...interface{}) error {
+	args := []interface{}{
+		voucher.Code, voucher.Discount, voucher.Start, voucher.End, voucher.GetQuota(), voucher.MaxPerCustomer,
+		voucher.GetType(), voucher.DiscountCap, voucher.MinOrder, strings.Join(voucher.Categories, ","), voucher.Stackable,
+	}
+	result, err := tx.Exec(query, append(args, where...)...)
+	if err != nil {
+		return err
+	}
+	rowEffect, _ := result.RowsAffected()
+	if rowEffect == 0 {
+		return ErrExist
+	}
+	if err = inserted(result, voucher); err != nil {
+		return err
+	}
+	return addEvent(tx, model.EVENT_VOUCHER_REGISTERED, voucher.Id, voucher, time.Now())
+}
+
+// registerTx goi register trong mot transaction moi
+func registerTx(db *sql.DB, query string, voucher *model.Voucher, where ...interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = register(tx, query, voucher, where...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// registerBatch insert cac voucher co code chua duoc dung roi commit tx
+func registerBatch(tx *sql.Tx, vouchers []model.Voucher) ([]model.Voucher, error) {
+	inserted := []model.Voucher{}
+	for _, voucher := range vouchers {
+		err := register(tx, REGISTER_UNIQUE, &voucher, voucher.Code)
+		if err == ErrExist {
+			continue
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		inserted = append(inserted, voucher)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+func inserted(result sql.Result, voucher *model.Voucher) error {
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	voucher.Id = int(id)
+	voucher.Quota = voucher.GetQuota()
+	voucher.Type = voucher.GetType()
+	voucher.Used = 0
+	return nil
+}
+
+func isExist(q querier, voucher model.Voucher) (bool, error) {
+	count := 0
+	err := q.QueryRow(COUNT_EXIST, voucher.Code, voucher.End, voucher.Start).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count >= 1, nil
+}
+
+func scanVouchers(rows *sql.Rows) ([]model.Voucher, error) {
+	defer rows.Close()
+	vouchers := []model.Voucher{}
+	for rows.Next() {
+		v := model.Voucher{}
+		categories := ""
+		err := rows.Scan(&v.Id, &v.Code, &v.Discount, &v.Start, &v.End, &v.Quota, &v.MaxPerCustomer, &v.Used,
+			&v.Type, &v.DiscountCap, &v.MinOrder, &categories, &v.Stackable, &v.Disabled, &v.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		// Categories duoc luu thanh chuoi phan cach boi dau phay
+		if categories != "" {
+			v.Categories = strings.Split(categories, ",")
+		}
+		vouchers = append(vouchers, v)
+	}
+	return vouchers, rows.Err()
+}
+
+func findByCode(q querier, code string) ([]model.Voucher, error) {
+	rows, err := q.Query(FIND_BY_CODE, code)
+	if err != nil {
+		return nil, err
+	}
+	return scanVouchers(rows)
+}
+
+var sortColumns = map[string]string{
+	"id":       "`id`",
+	"code":     "`code`",
+	"start":    "`start`",
+	"end":      "`end`",
+	"discount": "`discount`",
+}
+
+// Collation cua bang voucher tren MySQL khong phan biet hoa thuong, sort code theo byte
+// de cung thu tu voi Memory va SQLite (collation BINARY)
+var mysqlSortColumns = map[string]string{
+	"id":       "`id`",
+	"code":     "CAST(`code` AS BINARY)",
+	"start":    "`start`",
+	"end":      "`end`",
+	"discount": "`discount`",
+}
+
+// listQuery them dieu kien loc giong model.ListReq.Match va phan trang theo
+// cursor: (sort, id) phai lon hon (hoac nho hon neu desc) cua voucher cuoi trang truoc.
+// Time luon dung UTC de SQLite so sanh dung.
+func listQuery(req model.ListReq, columns map[string]string) (string, []interface{}, error) {
+	cursor, err := req.DecodeCursor()
+	if err != nil {
+		return "", nil, err
+	}
+	where := []string{"`deleted_at` IS NULL"}
+	args := []interface{}{}
+	if req.CodePrefix != "" {
+		where = append(where, "`code` LIKE ? ESCAPE '!'")
+		args = append(args, likeEscaper.Replace(req.CodePrefix)+"%")
+	}
+	if !req.ActiveAt.IsZero() {
+		where = append(where, "`start` <= ? AND `end` >= ?")
+		args = append(args, req.ActiveAt.UTC(), req.ActiveAt.UTC())
+	}
+	if req.MinDiscount > 0 {
+		where = append(where, "`discount` >= ?")
+		args = append(args, req.MinDiscount)
+	}
+	if req.MaxDiscount > 0 {
+		where = append(where, "`discount` <= ?")
+		args = append(args, req.MaxDiscount)
+	}
+	now := req.Now.UTC()
+	switch req.Status {
+	case model.STATUS_UPCOMING:
+		where = append(where, "`start` > ?")
+		args = append(args, now)
+	case model.STATUS_EXPIRED:
+		where = append(where, "`end` < ?")
+		args = append(args, now)
+	case model.STATUS_INACTIVE:
+		where = append(where, "`start` <= ? AND `end` >= ? AND `disabled` = 1")
+		args = append(args, now, now)
+	case model.STATUS_EXHAUSTED:
+		where = append(where, "`start` <= ? AND `end` >= ? AND `disabled` = 0 AND `used` >= `quota`")
+		args = append(args, now, now)
+	case model.STATUS_ACTIVE:
+		where = append(where, "`start` <= ? AND `end` >= ? AND `disabled` = 0 AND `used` < `quota`")
+		args = append(args, now, now)
+	}
+	column := columns[req.GetSort()]
+	op, direction := ">", "ASC"
+	if req.Desc {
+		op, direction = "<", "DESC"
+	}
+	order := "ORDER BY " + column + " " + direction
+	if column != "`id`" {
+		order += ", `id` " + direction
+	}
+	if cursor != nil {
+		if column == "`id`" {
+			where = append(where, "`id` "+op+" ?")
+			args = append(args, cursor.Id)
+		} else {
+			value := cursorValue(*cursor, req.GetSort())
+			where = append(where, "("+column+" "+op+" ? OR ("+column+" = ? AND `id` "+op+" ?))")
+			args = append(args, value, value, cursor.Id)
+		}
+	}
+	query := LIST + "WHERE " + strings.Join(where, " AND ") + " " + order + " LIMIT ?"
+	// Lay them 1 row de biet con trang sau khong
+	args = append(args, req.GetLimit()+1)
+	return query, args, nil
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func cursorValue(cursor model.Voucher, sort string) interface{} {
+	switch sort {
+	case "code":
+		return cursor.Code
+	case "start":
+		return cursor.Start.UTC()
+	case "end":
+		return cursor.End.UTC()
+	case "discount":
+		return cursor.Discount
+	}
+	return cursor.Id
+}
+
+func list(q querier, req model.ListReq, columns map[string]string) (*model.ListRes, error) {
+	query, args, err := listQuery(req, columns)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	vouchers, err := scanVouchers(rows)
+	if err != nil {
+		return nil, err
+	}
+	return page(vouchers, req), nil
+}
+
+// page cat bot row thua va tao NextCursor neu con trang sau
+func page(vouchers []model.Voucher, req model.ListReq) *model.ListRes {
+	limit := req.GetLimit()
+	res := &model.ListRes{
+		Vouchers: vouchers,
+	}
+	if len(vouchers) > limit {
+		res.Vouchers = vouchers[:limit]
+		res.NextCursor = req.EncodeCursor(vouchers[limit-1])
+	}
+	return res
+}
+
+// check kiem tra voucher dang hieu luc va customer chua dung qua so lan cho phep
+// (tinh ca cac reservation dang giu cho)
+func check(q querier, req model.VerifyReq, now time.Time) (*model.Voucher, string, error) {
+	vouchers, err := findByCode(q, req.Code)
+	if err != nil {
+		return nil, "", err
+	}
+	voucher, status := model.Pick(vouchers, now)
+	status = model.Applicable(voucher, status, req)
+	if status != model.VERIFY_OK || voucher.MaxPerCustomer <= 0 {
+		return voucher, status, nil
+	}
+	if req.Customer == "" {
+		return nil, "", model.ErrCustomerRequired
+	}
+	count := 0
+	err = q.QueryRow(COUNT_REDEMPTION, voucher.Id, req.Customer, voucher.Id, req.Customer).Scan(&count)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= voucher.MaxPerCustomer {
+		return voucher, model.VERIFY_CUSTOMER_LIMIT, nil
+	}
+	return voucher, status, nil
+}
+
+func verify(q querier, req model.VerifyReq, now time.Time) (*model.VerifyRes, error) {
+	voucher, status, err := check(q, req, now)
+	if err != nil {
+		return nil, err
+	}
+	return model.Result(voucher, status, req), nil
+}
+
+// claim tang so lan da dung cua voucher neu voucher van dung duoc.
+// Backend phai tu lock truoc khi goi de cac request cung luc phai cho nhau.
+// Neu khong claim duoc thi tx da bi rollback.
+func claim(tx *sql.Tx, req model.VerifyReq, now time.Time) (*model.VerifyRes, bool, error) {
+	voucher, status, err := check(tx, req, now)
+	if err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	if status != model.VERIFY_OK {
+		tx.Rollback()
+		return model.Result(voucher, status, req), false, nil
+	}
+	result, err := tx.Exec(REDEEM, voucher.Id)
+	if err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	rowEffect, _ := result.RowsAffected()
+	if rowEffect == 0 {
+		tx.Rollback()
+		return model.Result(voucher, model.VERIFY_EXHAUSTED, req), false, nil
+	}
+	voucher.Used++
+	return model.Result(voucher, status, req), true, nil
+}
+
+// redeem claim voucher va ghi vao redemption roi commit tx
+func redeem(tx *sql.Tx, req model.VerifyReq, now time.Time) (*model.VerifyRes, error) {
+	res, ok, err := claim(tx, req, now)
+	if err != nil || !ok {
+		return res, err
+	}
+	err = insertRedemption(tx, model.Redemption{
+		VoucherId: res.Voucher.Id,
+		Customer:  req.Customer,
+		Amount:    res.Amount,
+		Discount:  res.Discount,
+		CreatedAt: now,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// reserve claim voucher va tao reservation het han sau ttl roi commit tx
+func reserve(tx *sql.Tx, req model.VerifyReq, now time.Time, ttl time.Duration) (*model.ReserveRes, error) {
+	res, ok, err := claim(tx, req, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &model.ReserveRes{VerifyRes: *res}, nil
+	}
+	reservation := &model.Reservation{
+		VoucherId: res.Voucher.Id,
+		Customer:  req.Customer,
+		Amount:    res.Amount,
+		Discount:  res.Discount,
+		Status:    model.RESERVATION_RESERVED,
+		ExpiresAt: now.Add(ttl).UTC(),
+		CreatedAt: now.UTC(),
+	}
+	result, err := tx.Exec(INSERT_RESERVATION, reservation.VoucherId, reservation.Customer,
+		reservation.Amount, reservation.Discount, reservation.Status, reservation.ExpiresAt, reservation.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	reservation.Id = int(id)
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &model.ReserveRes{VerifyRes: *res, Reservation: reservation}, nil
+}
+
+func findReservation(q querier, id int) (*model.Reservation, error) {
+	r := &model.Reservation{}
+	err := q.QueryRow(FIND_RESERVATION, id).Scan(&r.Id, &r.VoucherId, &r.Customer,
+		&r.Amount, &r.Discount, &r.Status, &r.ExpiresAt, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// closeReservation chuyen reservation sang released/expired va tra lai quota
+func closeReservation(tx *sql.Tx, r *model.Reservation, status string) error {
+	if _, err := tx.Exec(UPDATE_RESERVATION, status, r.Id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(RELEASE, r.VoucherId); err != nil {
+		return err
+	}
+	r.Status = status
+	return nil
+}
+
+// confirm ghi reservation vao redemption roi commit tx.
+// Reservation qua han ma sweeper chua kip expire thi expire luon va tra ve ErrReservationExpired.
+func confirm(tx *sql.Tx, id int, now time.Time) (*model.Reservation, error) {
+	r, err := findReservation(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	switch {
+	case r.Status == model.RESERVATION_EXPIRED:
+		tx.Rollback()
+		return nil, model.ErrReservationExpired
+	case r.Status != model.RESERVATION_RESERVED:
+		tx.Rollback()
+		return nil, model.ErrReservationClosed
+	case r.IsExpired(now):
+		if err = closeReservation(tx, r, model.RESERVATION_EXPIRED); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, model.ErrReservationExpired
+	}
+	if _, err = tx.Exec(UPDATE_RESERVATION, model.RESERVATION_CONFIRMED, r.Id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = insertRedemption(tx, model.Redemption{
+		VoucherId: r.VoucherId,
+		Customer:  r.Customer,
+		Amount:    r.Amount,
+		Discount:  r.Discount,
+		CreatedAt: now,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	r.Status = model.RESERVATION_CONFIRMED
+	return r, nil
+}
+
+// release tra lai quota roi commit tx, release lai reservation da released/expired thi khong lam gi
+func release(tx *sql.Tx, id int) (*model.Reservation, error) {
+	r, err := findReservation(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if r.Status == model.RESERVATION_CONFIRMED {
+		tx.Rollback()
+		return nil, model.ErrReservationClosed
+	}
+	if r.Status != model.RESERVATION_RESERVED {
+		tx.Rollback()
+		return r, nil
+	}
+	if err = closeReservation(tx, r, model.RESERVATION_RELEASED); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// expireReservations tra lai quota cua cac reservation qua han roi commit tx
+func expireReservations(tx *sql.Tx, now time.Time) (int, error) {
+	now = now.UTC()
+	if _, err := tx.Exec(EXPIRE_USED, now, now); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	result, err := tx.Exec(EXPIRE_RESERVATION, now)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	rowEffect, _ := result.RowsAffected()
+	return int(rowEffect), nil
+}
+
+func findIdempotency(q querier, scope string, key string) (*model.Idempotency, error) {
+	i := &model.Idempotency{}
+	err := q.QueryRow(FIND_IDEMPOTENCY, scope, key).Scan(&i.Scope, &i.Key, &i.Hash,
+		&i.Response, &i.ExpiresAt, &i.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// beginIdempotency xoa key neu da het han, giu key neu chua co roi commit tx
+func beginIdempotency(tx *sql.Tx, record model.Idempotency, now time.Time) (*model.Idempotency, error) {
+	now = now.UTC()
+	if _, err := tx.Exec(DELETE_EXPIRED_IDEMPOTENCY, record.Scope, record.Key, now); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	result, err := tx.Exec(INSERT_IDEMPOTENCY, record.Scope, record.Key, record.Hash,
+		record.ExpiresAt.UTC(), record.CreatedAt.UTC(), record.Scope, record.Key)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	rowEffect, _ := result.RowsAffected()
+	if rowEffect == 1 {
+		return nil, tx.Commit()
+	}
+	existing, err := findIdempotency(tx, record.Scope, record.Key)
+	tx.Rollback()
+	if err != nil {
+		return nil, err
+	}
+	return replay(existing, record.Hash)
+}
+
+func expireIdempotency(e execer, now time.Time) (int, error) {
+	result, err := e.Exec(EXPIRE_IDEMPOTENCY, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	rowEffect, _ := result.RowsAffected()
+	return int(rowEffect), nil
+}
+
+func findById(q querier, id int) (*model.Voucher, error) {
+	rows, err := q.Query(FIND_BY_ID, id)
+	if err != nil {
+		return nil, err
+	}
+	vouchers, err := scanVouchers(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(vouchers) == 0 {
+		return nil, model.ErrVoucherNotFound
+	}
+	return &vouchers[0], nil
+}
+
+// changeVoucher ap dung change cho voucher, ghi audit roi commit tx.
+// Khong co gi thay doi thi khong ghi audit, khoang start/end moi bi overlap thi tra ve ErrExist.
+func changeVoucher(tx *sql.Tx, id int, action string, actor string, now time.Time,
+	change func(voucher *model.Voucher) error) (*model.Voucher, error) {
+	before, err := findById(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	after := *before
+	if err = change(&after); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if reflect.DeepEqual(*before, after) {
+		tx.Rollback()
+		return &after, nil
+	}
+	if !after.IsDeleted() && (!after.Start.Equal(before.Start) || !after.End.Equal(before.End)) {
+		count := 0
+		err = tx.QueryRow(COUNT_EXIST_OTHER, after.Code, after.End.UTC(), after.Start.UTC(), after.Id).Scan(&count)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if count > 0 {
+			tx.Rollback()
+			return nil, ErrExist
+		}
+	}
+	var deletedAt interface{}
+	if after.DeletedAt != nil {
+		deletedAt = after.DeletedAt.UTC()
+	}
+	_, err = tx.Exec(UPDATE_VOUCHER, after.Start.UTC(), after.End.UTC(), after.Disabled, deletedAt, after.Id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = insertAudit(tx, action, actor, before, &after, now); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+func insertAudit(e execer, action string, actor string, before *model.Voucher, after *model.Voucher, now time.Time) error {
+	audit, err := model.NewAudit(action, actor, before, after, now)
+	if err != nil {
+		return err
+	}
+	_, err = e.Exec(INSERT_AUDIT, audit.VoucherId, audit.Action, audit.Actor,
+		[]byte(audit.Before), []byte(audit.After), audit.CreatedAt.UTC())
+	return err
+}
+
+func audits(q querier, voucherId int) ([]model.Audit, error) {
+	rows, err := q.Query(LIST_AUDIT, voucherId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	audits := []model.Audit{}
+	for rows.Next() {
+		a := model.Audit{}
+		var before, after []byte
+		err := rows.Scan(&a.Id, &a.VoucherId, &a.Action, &a.Actor, &before, &after, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		a.Before, a.After = before, after
+		audits = append(audits, a)
+	}
+	return audits, rows.Err()
+}
+
+// insertRedemption ghi redemption va event VoucherRedeemed trong cung tx
+func insertRedemption(tx *sql.Tx, redemption model.Redemption) error {
+	result, err := tx.Exec(INSERT_REDEMPTION, redemption.VoucherId, redemption.Customer,
+		redemption.Amount, redemption.Discount, redemption.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	redemption.Id = int(id)
+	return addEvent(tx, model.EVENT_VOUCHER_REDEEMED, redemption.VoucherId, redemption, redemption.CreatedAt)
+}
+
+// addEvent ghi event vao outbox, phai dung chung tx voi thay doi cua voucher
+func addEvent(e execer, eventType string, voucherId int, payload interface{}, now time.Time) error {
+	event, err := model.NewEvent(eventType, voucherId, payload, now)
+	if err != nil {
+		return err
+	}
+	_, err = e.Exec(INSERT_EVENT, event.VoucherId, event.Type, []byte(event.Payload), event.CreatedAt.UTC())
+	return err
+}
+
+func pendingEvents(q querier, limit int) ([]model.Event, error) {
+	rows, err := q.Query(PENDING_EVENTS, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := []model.Event{}
+	for rows.Next() {
+		e := model.Event{}
+		var payload []byte
+		if err := rows.Scan(&e.Id, &e.VoucherId, &e.Type, &payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func markPublished(e execer, ids []int, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := []interface{}{now.UTC()}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	query := MARK_PUBLISHED + "(?" + strings.Repeat(", ?", len(ids)-1) + ")"
+	_, err := e.Exec(query, args...)
+	return err
+}
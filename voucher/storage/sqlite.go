@@ -0,0 +1,292 @@
+package storage
+
+import (
+	"database/sql"
+	"runtime"
+	"strings"
+	"time"
+
+	"../model"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLite dung cung cac cau query voi MySQL, chi khac la SQLite khong co FOR UPDATE:
+// moi transaction duoc mo bang BEGIN IMMEDIATE (_txlock=immediate) nen giu lock ghi
+// cua ca database tu dau, transaction ghi chay tuan tu giong nhu lock row cua locker.
+type SQLite struct {
+	DB *sql.DB
+}
+
+const SQLITE_SCHEMA = "CREATE TABLE IF NOT EXISTS `voucher` (" +
+	"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+	"`code` VARCHAR(64) NOT NULL, " +
+	"`discount` FLOAT NOT NULL, " +
+	"`start` TIMESTAMP NOT NULL, " +
+	"`end` TIMESTAMP NOT NULL, " +
+	"`quota` INTEGER NOT NULL DEFAULT 1, " +
+	"`max_per_customer` INTEGER NOT NULL DEFAULT 0, " +
+	"`used` INTEGER NOT NULL DEFAULT 0, " +
+	"`type` VARCHAR(16) NOT NULL DEFAULT 'percent', " +
+	"`discount_cap` FLOAT NOT NULL DEFAULT 0, " +
+	"`min_order` FLOAT NOT NULL DEFAULT 0, " +
+	"`categories` VARCHAR(255) NOT NULL DEFAULT '', " +
+	"`stackable` BOOLEAN NOT NULL DEFAULT 0, " +
+	"`disabled` BOOLEAN NOT NULL DEFAULT 0, " +
+	"`deleted_at` TIMESTAMP NULL" +
+	");" +
+	"CREATE INDEX IF NOT EXISTS `code` ON `voucher`(`code`);" +
+	"CREATE TABLE IF NOT EXISTS `redemption` (" +
+	"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+	"`voucher_id` INTEGER NOT NULL, " +
+	"`customer` VARCHAR(64) NOT NULL, " +
+	"`amount` FLOAT NOT NULL, " +
+	"`discount` FLOAT NOT NULL, " +
+	"`created_at` TIMESTAMP NOT NULL" +
+	");" +
+	"CREATE INDEX IF NOT EXISTS `voucher_customer` ON `redemption`(`voucher_id`, `customer`);" +
+	"CREATE TABLE IF NOT EXISTS `reservation` (" +
+	"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+	"`voucher_id` INTEGER NOT NULL, " +
+	"`customer` VARCHAR(64) NOT NULL, " +
+	"`amount` FLOAT NOT NULL, " +
+	"`discount` FLOAT NOT NULL, " +
+	"`status` VARCHAR(16) NOT NULL, " +
+	"`expires_at` TIMESTAMP NOT NULL, " +
+	"`created_at` TIMESTAMP NOT NULL" +
+	");" +
+	"CREATE INDEX IF NOT EXISTS `status_expires_at` ON `reservation`(`status`, `expires_at`);" +
+	"CREATE TABLE IF NOT EXISTS `idempotency` (" +
+	"`scope` VARCHAR(16) NOT NULL, " +
+	"`key` VARCHAR(255) NOT NULL, " +
+	"`hash` CHAR(64) NOT NULL, " +
+	"`response` BLOB NULL, " +
+	"`expires_at` TIMESTAMP NOT NULL, " +
+	"`created_at` TIMESTAMP NOT NULL, " +
+	"PRIMARY KEY (`scope`, `key`)" +
+	");" +
+	"CREATE INDEX IF NOT EXISTS `expires_at` ON `idempotency`(`expires_at`);" +
+	"CREATE TABLE IF NOT EXISTS `audit` (" +
+	"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+	"`voucher_id` INTEGER NOT NULL, " +
+	"`action` VARCHAR(16) NOT NULL, " +
+	"`actor` VARCHAR(64) NOT NULL, " +
+	"`before` TEXT NOT NULL, " +
+	"`after` TEXT NOT NULL, " +
+	"`created_at` TIMESTAMP NOT NULL" +
+	");" +
+	"CREATE INDEX IF NOT EXISTS `voucher_id` ON `audit`(`voucher_id`);" +
+	"CREATE TABLE IF NOT EXISTS `outbox` (" +
+	"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+	"`voucher_id` INTEGER NOT NULL, " +
+	"`type` VARCHAR(32) NOT NULL, " +
+	"`payload` TEXT NOT NULL, " +
+	"`created_at` TIMESTAMP NOT NULL, " +
+	"`published_at` TIMESTAMP NULL" +
+	");" +
+	"CREATE INDEX IF NOT EXISTS `published_at` ON `outbox`(`published_at`, `id`);"
+
+// NewSQLite mo database (vd: ":memory:" hoac "voucher.db") voi 1 connection va tao schema neu chua co
+func NewSQLite(dsn string) (*SQLite, error) {
+	return openSQLite(dsn, 1)
+}
+
+// NewSQLiteWAL mo file o che do WAL voi conns connection. Cac connection doc song song
+// voi transaction ghi, nen cac strategy kiem tra roi moi insert (RegisterNaive) bi chen giua
+// nhu tren MySQL. Dung cho stress test, ":memory:" khong dung chung duoc giua cac connection.
+func NewSQLiteWAL(file string, conns int) (*SQLite, error) {
+	return openSQLite(file+"?_journal_mode=WAL&_busy_timeout=10000", conns)
+}
+
+func openSQLite(dsn string, conns int) (*SQLite, error) {
+	if strings.Contains(dsn, "?") {
+		dsn += "&_txlock=immediate"
+	} else {
+		dsn += "?_txlock=immediate"
+	}
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(conns)
+	if _, err = db.Exec(SQLITE_SCHEMA); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLite{DB: db}, nil
+}
+
+// utc: SQLite luu TIMESTAMP dang text nen phai cung timezone thi moi so sanh dung
+func utc(voucher *model.Voucher) {
+	voucher.Start = voucher.Start.UTC()
+	voucher.End = voucher.End.UTC()
+}
+
+func (s *SQLite) IsExist(voucher model.Voucher) (bool, error) {
+	utc(&voucher)
+	return isExist(s.DB, voucher)
+}
+
+func (s *SQLite) Register(voucher *model.Voucher) error {
+	return s.RegisterIsolation(voucher)
+}
+
+// RegisterIsolation kiem tra overlap roi insert trong cung mot transaction.
+// Transaction da giu lock ghi tu BEGIN IMMEDIATE nen khong ai insert chen giua 2 buoc.
+func (s *SQLite) RegisterIsolation(voucher *model.Voucher) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	utc(voucher)
+	isExist, err := isExist(tx, *voucher)
+	if err == nil && isExist {
+		err = ErrExist
+	}
+	if err == nil {
+		err = register(tx, REGISTER, voucher)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// RegisterAtomic kiem tra overlap trong chinh cau insert, SQLite chi chay
+// mot cau ghi tai mot thoi diem nen cung khong bi duplicate
+func (s *SQLite) RegisterAtomic(voucher *model.Voucher) error {
+	utc(voucher)
+	return registerTx(s.DB, REGISTER_ATOMIC, voucher, voucher.Code, voucher.End, voucher.Start)
+}
+
+// RegisterNaive kiem tra IsExist roi moi insert, giong Voucher.RegisterNaive
+func (s *SQLite) RegisterNaive(voucher *model.Voucher) error {
+	utc(voucher)
+	isExist, err := isExist(s.DB, *voucher)
+	if err != nil {
+		return err
+	}
+	if isExist {
+		return ErrExist
+	}
+	// SQLite chay trong process nen khong co round trip giua 2 buoc nhu MySQL,
+	// nhuong CPU de request khac chen vao duoc giong nhu khi doi network
+	runtime.Gosched()
+	return registerTx(s.DB, REGISTER, voucher)
+}
+
+// RegisterInsert insert khong kiem tra overlap, giong Voucher.RegisterInsert
+func (s *SQLite) RegisterInsert(voucher *model.Voucher) error {
+	utc(voucher)
+	return registerTx(s.DB, REGISTER, voucher)
+}
+
+func (s *SQLite) RegisterBatch(vouchers []model.Voucher) ([]model.Voucher, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	for i := range vouchers {
+		utc(&vouchers[i])
+	}
+	return registerBatch(tx, vouchers)
+}
+
+func (s *SQLite) Find(code string) ([]model.Voucher, error) {
+	return findByCode(s.DB, code)
+}
+
+func (s *SQLite) List(req model.ListReq) (*model.ListRes, error) {
+	return list(s.DB, req, sortColumns)
+}
+
+func (s *SQLite) Verify(req model.VerifyReq, now time.Time) (*model.VerifyRes, error) {
+	return verify(s.DB, req, now)
+}
+
+func (s *SQLite) Redeem(req model.VerifyReq, now time.Time) (*model.VerifyRes, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return redeem(tx, req, now)
+}
+
+func (s *SQLite) Reserve(req model.VerifyReq, now time.Time, ttl time.Duration) (*model.ReserveRes, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return reserve(tx, req, now, ttl)
+}
+
+func (s *SQLite) Confirm(id int, now time.Time) (*model.Reservation, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return confirm(tx, id, now.UTC())
+}
+
+func (s *SQLite) Release(id int) (*model.Reservation, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return release(tx, id)
+}
+
+func (s *SQLite) ExpireReservations(now time.Time) (int, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	return expireReservations(tx, now)
+}
+
+func (s *SQLite) BeginIdempotency(record model.Idempotency, now time.Time) (*model.Idempotency, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return beginIdempotency(tx, record, now)
+}
+
+func (s *SQLite) FinishIdempotency(scope string, key string, response []byte, expiresAt time.Time) error {
+	_, err := s.DB.Exec(FINISH_IDEMPOTENCY, response, expiresAt.UTC(), scope, key)
+	return err
+}
+
+func (s *SQLite) AbortIdempotency(scope string, key string) error {
+	_, err := s.DB.Exec(DELETE_IDEMPOTENCY, scope, key)
+	return err
+}
+
+func (s *SQLite) ExpireIdempotency(now time.Time) (int, error) {
+	return expireIdempotency(s.DB, now)
+}
+
+func (s *SQLite) FindById(id int) (*model.Voucher, error) {
+	return findById(s.DB, id)
+}
+
+func (s *SQLite) Change(id int, action string, actor string, now time.Time, change func(voucher *model.Voucher) error) (*model.Voucher, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return changeVoucher(tx, id, action, actor, now, change)
+}
+
+func (s *SQLite) Audits(voucherId int) ([]model.Audit, error) {
+	return audits(s.DB, voucherId)
+}
+
+func (s *SQLite) PendingEvents(limit int) ([]model.Event, error) {
+	return pendingEvents(s.DB, limit)
+}
+
+func (s *SQLite) MarkPublished(ids []int, now time.Time) error {
+	return markPublished(s.DB, ids, now)
+}
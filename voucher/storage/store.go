@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"../model"
+)
+
+// ErrExist khi da co voucher cung code bi overlap khoang start/end
+var ErrExist = errors.New("Code is exist")
+
+// VoucherStore la nhung thao tac ma service can tren voucher.
+// Moi backend (MySQL, SQLite, memory) deu phai pass storeSuite trong store_test.go
+type VoucherStore interface {
+	// Register insert voucher va gan Id neu khong overlap voi voucher cung code,
+	// nguoc lai tra ve ErrExist
+	Register(voucher *model.Voucher) error
+	// RegisterBatch insert trong mot transaction cac voucher co code chua tung
+	// duoc dung boi voucher nao, tra ve cac voucher da insert (co Id)
+	RegisterBatch(vouchers []model.Voucher) ([]model.Voucher, error)
+	IsExist(voucher model.Voucher) (bool, error)
+	// Find tra ve cac voucher cung code, sap xep theo start
+	Find(code string) ([]model.Voucher, error)
+	// List loc, sort va phan trang theo cursor, xem model.ListReq
+	List(req model.ListReq) (*model.ListRes, error)
+	Verify(req model.VerifyReq, now time.Time) (*model.VerifyRes, error)
+	Redeem(req model.VerifyReq, now time.Time) (*model.VerifyRes, error)
+	// Reserve giu cho voucher (tinh vao quota) trong ttl, sau do phai Confirm hoac Release
+	Reserve(req model.VerifyReq, now time.Time, ttl time.Duration) (*model.ReserveRes, error)
+	Confirm(id int, now time.Time) (*model.Reservation, error)
+	Release(id int) (*model.Reservation, error)
+	// ExpireReservations tra lai quota cua cac reservation qua han, tra ve so reservation bi expire
+	ExpireReservations(now time.Time) (int, error)
+	IdempotencyStore
+	// FindById tra ve ErrVoucherNotFound neu khong co hoac da bi xoa
+	FindById(id int) (*model.Voucher, error)
+	// Change ap dung change cho voucher va ghi audit (actor, truoc/sau) trong cung transaction,
+	// xem Deactivate, Reactivate, Extend va Delete
+	Change(id int, action string, actor string, now time.Time, change func(voucher *model.Voucher) error) (*model.Voucher, error)
+	// Audits tra ve cac thay doi cua voucher, cu nhat truoc
+	Audits(voucherId int) ([]model.Audit, error)
+	// PendingEvents tra ve toi da limit event trong outbox chua duoc publish, theo thu tu ghi
+	PendingEvents(limit int) ([]model.Event, error)
+	MarkPublished(ids []int, now time.Time) error
+}
@@ -0,0 +1,908 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"../model"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+var base = time.Date(2019, 8, 9, 15, 0, 0, 0, time.UTC)
+
+func newVoucher(code string, from int, to int) *model.Voucher {
+	return &model.Voucher{
+		Code:     code,
+		Discount: 0.1,
+		Start:    base.Add(time.Hour * time.Duration(from)),
+		End:      base.Add(time.Hour * time.Duration(to)),
+	}
+}
+
+// storeSuite la bo test ma moi backend cua VoucherStore deu phai pass
+func storeSuite(t *testing.T, newStore func(t *testing.T) VoucherStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store VoucherStore)
+	}{
+		{"Register", testRegister},
+		{"RegisterOverlap", testRegisterOverlap},
+		{"IsExist", testIsExist},
+		{"RegisterBatch", testRegisterBatch},
+		{"GenerateBatch", testGenerateBatch},
+		{"FindAndList", testFindAndList},
+		{"ListFilter", testListFilter},
+		{"ListCursor", testListCursor},
+		{"ListCodeOrder", testListCodeOrder},
+		{"Verify", testVerify},
+		{"RedeemQuota", testRedeemQuota},
+		{"RedeemCustomerLimit", testRedeemCustomerLimit},
+		{"RedeemConcurrent", testRedeemConcurrent},
+		{"ReserveConfirm", testReserveConfirm},
+		{"ReserveRelease", testReserveRelease},
+		{"ReserveExpire", testReserveExpire},
+		{"Idempotency", testIdempotency},
+		{"Rule", testRule},
+		{"Lifecycle", testLifecycle},
+		{"Delete", testDelete},
+		{"Outbox", testOutbox},
+		{"IdempotentRegister", testIdempotentRegister},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.fn(t, newStore(t))
+		})
+	}
+}
+
+func testRegister(t *testing.T, store VoucherStore) {
+	voucher := newVoucher("ABC", 0, 48)
+	if err := store.Register(voucher); err != nil {
+		t.Fatal(err)
+	}
+	if voucher.Id == 0 {
+		t.Error("Id should be set after register")
+	}
+	if voucher.Quota != 1 {
+		t.Error("Quota should default to 1")
+	}
+}
+
+func testRegisterOverlap(t *testing.T, store VoucherStore) {
+	if err := store.Register(newVoucher("ABC", 0, 48)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Register(newVoucher("ABC", 24, 72)); err != ErrExist {
+		t.Error("Overlap voucher should be ErrExist, got", err)
+	}
+	// Cham dung bien cung tinh la overlap
+	if err := store.Register(newVoucher("ABC", 48, 72)); err != ErrExist {
+		t.Error("Voucher start at end of other should be ErrExist, got", err)
+	}
+	if err := store.Register(newVoucher("ABC", 49, 72)); err != nil {
+		t.Error("Not overlap voucher should be registered, got", err)
+	}
+	if err := store.Register(newVoucher("XYZ", 0, 48)); err != nil {
+		t.Error("Other code should be registered, got", err)
+	}
+}
+
+func testIsExist(t *testing.T, store VoucherStore) {
+	if err := store.Register(newVoucher("ABC", 0, 48)); err != nil {
+		t.Fatal(err)
+	}
+	isExist, err := store.IsExist(*newVoucher("ABC", 10, 20))
+	if err != nil || !isExist {
+		t.Error("Overlap voucher should exist", err)
+	}
+	isExist, err = store.IsExist(*newVoucher("ABC", 49, 50))
+	if err != nil || isExist {
+		t.Error("Not overlap voucher should not exist", err)
+	}
+}
+
+func testRegisterBatch(t *testing.T, store VoucherStore) {
+	if err := store.Register(newVoucher("ABC", 0, 48)); err != nil {
+		t.Fatal(err)
+	}
+	// ABC khong overlap nhung code da duoc dung nen van bi bo qua
+	vouchers := []model.Voucher{*newVoucher("ABC", 100, 148), *newVoucher("XYZ", 0, 48), *newVoucher("XYZ", 100, 148)}
+	inserted, err := store.RegisterBatch(vouchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inserted) != 1 || inserted[0].Code != "XYZ" || inserted[0].Id == 0 {
+		t.Error("Only first XYZ should be inserted", inserted)
+	}
+	all, _ := store.List(model.ListReq{})
+	if len(all.Vouchers) != 2 {
+		t.Error("Store should have 2 vouchers", all)
+	}
+}
+
+func testGenerateBatch(t *testing.T, store VoucherStore) {
+	req := model.GenerateReq{
+		Prefix:   "SALE-",
+		Count:    GENERATE_BATCH_SIZE + 10,
+		Discount: 0.1,
+		Start:    base,
+		End:      base.Add(time.Hour * 48),
+	}
+	codes := map[string]bool{}
+	batches := 0
+	err := GenerateBatch(store, req, func(vouchers []model.Voucher) error {
+		batches++
+		for _, v := range vouchers {
+			if codes[v.Code] || !strings.HasPrefix(v.Code, "SALE-") || len(v.Code) != 5+model.CODE_LENGTH {
+				t.Error("Code should be unique with prefix and length", v.Code)
+			}
+			codes[v.Code] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != req.Count || batches != 2 {
+		t.Error("Should generate all codes in 2 batches", len(codes), batches)
+	}
+	// Alphabet qua nho so voi Count
+	req = model.GenerateReq{Count: 10, Length: 2, Alphabet: "AB"}
+	if err = GenerateBatch(store, req, nil); err != ErrNotEnoughCodes {
+		t.Error("Should be ErrNotEnoughCodes, got", err)
+	}
+}
+
+func testFindAndList(t *testing.T, store VoucherStore) {
+	for _, v := range []*model.Voucher{
+		newVoucher("ABC", 100, 148),
+		newVoucher("ABC", 0, 48),
+		newVoucher("XYZ", 0, 48),
+	} {
+		if err := store.Register(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vouchers, err := store.Find("ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vouchers) != 2 || !vouchers[0].Start.Equal(base) {
+		t.Error("Find should return vouchers of code order by start", vouchers)
+	}
+	vouchers, err = store.Find("NOPE")
+	if err != nil || len(vouchers) != 0 {
+		t.Error("Find should return empty for unknown code", vouchers, err)
+	}
+	res, err := store.List(model.ListReq{})
+	if err != nil || len(res.Vouchers) != 3 || res.NextCursor != "" {
+		t.Error("List should return all vouchers", res, err)
+	}
+}
+
+func testListFilter(t *testing.T, store VoucherStore) {
+	vouchers := []*model.Voucher{
+		newVoucher("SALE-1", 0, 48),
+		newVoucher("SALE-2", 100, 148),
+		newVoucher("sale_3", 0, 48),
+		newVoucher("XYZ", -48, -1),
+	}
+	vouchers[1].Discount = 0.5
+	for _, v := range vouchers {
+		if err := store.Register(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.Redeem(model.VerifyReq{Code: "sale_3"}, base); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		req   model.ListReq
+		codes []string
+	}{
+		{"prefix", model.ListReq{CodePrefix: "sale-"}, []string{"SALE-1", "SALE-2"}},
+		{"prefix escape", model.ListReq{CodePrefix: "SALE_"}, []string{"sale_3"}},
+		{"active at", model.ListReq{ActiveAt: base.Add(time.Hour * 100)}, []string{"SALE-2"}},
+		{"min discount", model.ListReq{MinDiscount: 0.2}, []string{"SALE-2"}},
+		{"max discount", model.ListReq{MaxDiscount: 0.2}, []string{"SALE-1", "sale_3", "XYZ"}},
+		{"active", model.ListReq{Status: model.STATUS_ACTIVE, Now: base}, []string{"SALE-1"}},
+		{"exhausted", model.ListReq{Status: model.STATUS_EXHAUSTED, Now: base}, []string{"sale_3"}},
+		{"upcoming", model.ListReq{Status: model.STATUS_UPCOMING, Now: base}, []string{"SALE-2"}},
+		{"expired", model.ListReq{Status: model.STATUS_EXPIRED, Now: base}, []string{"XYZ"}},
+	}
+	for _, c := range cases {
+		res, err := store.List(c.req)
+		if err != nil {
+			t.Fatal(c.name, err)
+		}
+		codes := []string{}
+		for _, v := range res.Vouchers {
+			codes = append(codes, v.Code)
+		}
+		if strings.Join(codes, ",") != strings.Join(c.codes, ",") {
+			t.Errorf("List %s should be %v, got %v", c.name, c.codes, codes)
+		}
+	}
+}
+
+func testListCursor(t *testing.T, store VoucherStore) {
+	// 5 voucher, 2 cap trung start de kiem tra sort theo id khi bang nhau
+	for i, from := range []int{30, 10, 10, 20, 20} {
+		v := newVoucher("C"+strconv.Itoa(i), from, from+5)
+		if err := store.Register(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		req   model.ListReq
+		codes string
+	}{
+		{model.ListReq{Limit: 2}, "C0,C1,C2,C3,C4"},
+		{model.ListReq{Limit: 2, Sort: "start"}, "C1,C2,C3,C4,C0"},
+		{model.ListReq{Limit: 2, Sort: "start", Desc: true}, "C0,C4,C3,C2,C1"},
+		{model.ListReq{Limit: 3, Sort: "code", Desc: true}, "C4,C3,C2,C1,C0"},
+	}
+	for _, c := range cases {
+		codes := []string{}
+		pages := 0
+		req := c.req
+		for {
+			res, err := store.List(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pages++
+			for _, v := range res.Vouchers {
+				codes = append(codes, v.Code)
+			}
+			if res.NextCursor == "" || pages > 5 {
+				break
+			}
+			req.Cursor = res.NextCursor
+		}
+		if strings.Join(codes, ",") != c.codes {
+			t.Errorf("List %+v should be %s, got %v", c.req, c.codes, codes)
+		}
+	}
+	if _, err := store.List(model.ListReq{Cursor: "???"}); err != model.ErrInvalidCursor {
+		t.Error("Should be ErrInvalidCursor, got", err)
+	}
+	// Cursor chi dung duoc voi sort da tao ra no
+	res, _ := store.List(model.ListReq{Limit: 2, Sort: "start"})
+	for _, req := range []model.ListReq{{Sort: "code"}, {Sort: "start", Desc: true}} {
+		req.Cursor = res.NextCursor
+		if _, err := store.List(req); err != model.ErrInvalidCursor {
+			t.Errorf("Cursor of sort start used with %+v should be ErrInvalidCursor, got %v", req, err)
+		}
+	}
+}
+
+func testListCodeOrder(t *testing.T, store VoucherStore) {
+	// Moi backend deu sort code theo byte: chu hoa truoc chu thuong
+	for _, code := range []string{"abc", "ABD", "Abe", "ACA"} {
+		if err := store.Register(newVoucher(code, 0, 48)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	codes := []string{}
+	req := model.ListReq{Limit: 1, Sort: "code"}
+	for i := 0; i < 5; i++ {
+		res, err := store.List(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range res.Vouchers {
+			codes = append(codes, v.Code)
+		}
+		if res.NextCursor == "" {
+			break
+		}
+		req.Cursor = res.NextCursor
+	}
+	if strings.Join(codes, ",") != "ABD,ACA,Abe,abc" {
+		t.Error("Codes should be sorted by byte, got", codes)
+	}
+}
+
+func testVerify(t *testing.T, store VoucherStore) {
+	if err := store.Register(newVoucher("ABC", 0, 48)); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		code   string
+		now    time.Time
+		status string
+	}{
+		{"NOPE", base, model.VERIFY_NOT_FOUND},
+		{"ABC", base.Add(-time.Hour), model.VERIFY_NOT_STARTED},
+		{"ABC", base.Add(time.Hour * 49), model.VERIFY_EXPIRED},
+		{"ABC", base.Add(time.Hour), model.VERIFY_OK},
+	}
+	for _, c := range cases {
+		res, err := store.Verify(model.VerifyReq{Code: c.code, Amount: 100}, c.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != c.status {
+			t.Errorf("Verify %s at %v should be %s, got %s", c.code, c.now, c.status, res.Status)
+		}
+	}
+	res, _ := store.Verify(model.VerifyReq{Code: "ABC", Amount: 100}, base)
+	if res.Discount != 10 || res.Total != 90 {
+		t.Error("Discount should be applied", res)
+	}
+	// Verify khong duoc danh dau la da dung
+	res, _ = store.Verify(model.VerifyReq{Code: "ABC", Amount: 100}, base)
+	if res.Status != model.VERIFY_OK {
+		t.Error("Verify should not use the voucher", res.Status)
+	}
+}
+
+func testRedeemQuota(t *testing.T, store VoucherStore) {
+	voucher := newVoucher("ABC", 0, 48)
+	voucher.Quota = 2
+	if err := store.Register(voucher); err != nil {
+		t.Fatal(err)
+	}
+	req := model.VerifyReq{Code: "ABC", Amount: 100}
+	for i, status := range []string{model.VERIFY_OK, model.VERIFY_OK, model.VERIFY_EXHAUSTED} {
+		res, err := store.Redeem(req, base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != status {
+			t.Errorf("Redeem %d should be %s, got %s", i, status, res.Status)
+		}
+	}
+	vouchers, _ := store.Find("ABC")
+	if len(vouchers) != 1 || vouchers[0].Used != 2 {
+		t.Error("Used should be 2", vouchers)
+	}
+}
+
+func testRedeemCustomerLimit(t *testing.T, store VoucherStore) {
+	voucher := newVoucher("ABC", 0, 48)
+	voucher.Quota = 10
+	voucher.MaxPerCustomer = 1
+	if err := store.Register(voucher); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		customer string
+		status   string
+	}{
+		{"alice", model.VERIFY_OK},
+		{"alice", model.VERIFY_CUSTOMER_LIMIT},
+		{"bob", model.VERIFY_OK},
+	}
+	for _, c := range cases {
+		res, err := store.Redeem(model.VerifyReq{Code: "ABC", Amount: 100, Customer: c.customer}, base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != c.status {
+			t.Errorf("Redeem by %s should be %s, got %s", c.customer, c.status, res.Status)
+		}
+	}
+	res, _ := store.Verify(model.VerifyReq{Code: "ABC", Customer: "bob"}, base)
+	if res.Status != model.VERIFY_CUSTOMER_LIMIT {
+		t.Error("Verify should check customer limit, got", res.Status)
+	}
+	// Khong co customer thi khong dem duoc so lan da dung
+	if _, err := store.Redeem(model.VerifyReq{Code: "ABC", Amount: 100}, base); err != model.ErrCustomerRequired {
+		t.Error("Redeem without customer should be rejected, got", err)
+	}
+	if _, err := store.Reserve(model.VerifyReq{Code: "ABC", Amount: 100}, base, time.Minute); err != model.ErrCustomerRequired {
+		t.Error("Reserve without customer should be rejected, got", err)
+	}
+}
+
+func testRedeemConcurrent(t *testing.T, store VoucherStore) {
+	voucher := newVoucher("ABC", 0, 48)
+	voucher.Quota = 5
+	if err := store.Register(voucher); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	ok := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := store.Redeem(model.VerifyReq{Code: "ABC", Amount: 100}, base)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res.Status == model.VERIFY_OK {
+				mutex.Lock()
+				ok++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if ok != 5 {
+		t.Error("Only quota redemptions should succeed, got", ok)
+	}
+}
+
+func registerQuota(t *testing.T, store VoucherStore, quota int) {
+	voucher := newVoucher("ABC", 0, 48)
+	voucher.Quota = quota
+	voucher.MaxPerCustomer = 1
+	if err := store.Register(voucher); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func reserveABC(t *testing.T, store VoucherStore, customer string, now time.Time) *model.ReserveRes {
+	res, err := store.Reserve(model.VerifyReq{Code: "ABC", Amount: 100, Customer: customer}, now, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func testReserveConfirm(t *testing.T, store VoucherStore) {
+	registerQuota(t, store, 1)
+	res := reserveABC(t, store, "alice", base)
+	if res.Status != model.VERIFY_OK || res.Reservation == nil || res.Discount != 10 {
+		t.Fatal("Reserve should be OK", res)
+	}
+	// Dang giu cho thi het quota va customer khong reserve them duoc
+	if res := reserveABC(t, store, "bob", base); res.Status != model.VERIFY_EXHAUSTED || res.Reservation != nil {
+		t.Error("Reserved voucher should be EXHAUSTED, got", res.Status)
+	}
+	r, err := store.Confirm(res.Reservation.Id, base.Add(time.Second*30))
+	if err != nil || r.Status != model.RESERVATION_CONFIRMED {
+		t.Fatal("Confirm should be OK", r, err)
+	}
+	if _, err = store.Confirm(res.Reservation.Id, base); err != model.ErrReservationClosed {
+		t.Error("Confirm twice should be ErrReservationClosed, got", err)
+	}
+	if _, err = store.Release(res.Reservation.Id); err != model.ErrReservationClosed {
+		t.Error("Release confirmed should be ErrReservationClosed, got", err)
+	}
+	if _, err = store.Confirm(12345, base); err != model.ErrReservationNotFound {
+		t.Error("Should be ErrReservationNotFound, got", err)
+	}
+	verify, _ := store.Verify(model.VerifyReq{Code: "ABC", Customer: "alice"}, base)
+	if verify.Status != model.VERIFY_EXHAUSTED {
+		t.Error("Confirmed reservation should be used, got", verify.Status)
+	}
+}
+
+func testReserveRelease(t *testing.T, store VoucherStore) {
+	registerQuota(t, store, 1)
+	res := reserveABC(t, store, "alice", base)
+	if verify, _ := store.Verify(model.VerifyReq{Code: "ABC", Customer: "carol"}, base); verify.Status != model.VERIFY_EXHAUSTED {
+		t.Error("Reserved voucher should be EXHAUSTED, got", verify.Status)
+	}
+	r, err := store.Release(res.Reservation.Id)
+	if err != nil || r.Status != model.RESERVATION_RELEASED {
+		t.Fatal("Release should be OK", r, err)
+	}
+	if _, err = store.Release(res.Reservation.Id); err != nil {
+		t.Error("Release twice should do nothing, got", err)
+	}
+	if res := reserveABC(t, store, "alice", base); res.Status != model.VERIFY_OK {
+		t.Error("Released quota should be reserved again, got", res.Status)
+	}
+	vouchers, _ := store.Find("ABC")
+	if vouchers[0].Used != 1 {
+		t.Error("Used should be 1, got", vouchers[0].Used)
+	}
+}
+
+func testReserveExpire(t *testing.T, store VoucherStore) {
+	registerQuota(t, store, 2)
+	first := reserveABC(t, store, "alice", base)
+	second := reserveABC(t, store, "bob", base.Add(time.Minute))
+	// Chua toi luc sweep nhung confirm sau khi het han van phai fail va tra lai quota
+	if _, err := store.Confirm(first.Reservation.Id, base.Add(time.Minute*2)); err != model.ErrReservationExpired {
+		t.Error("Confirm expired should be ErrReservationExpired, got", err)
+	}
+	count, err := store.ExpireReservations(base.Add(time.Minute * 3))
+	if err != nil || count != 1 {
+		t.Error("Should expire 1 reservation", count, err)
+	}
+	if _, err := store.Confirm(second.Reservation.Id, base.Add(time.Minute*3)); err != model.ErrReservationExpired {
+		t.Error("Confirm swept should be ErrReservationExpired, got", err)
+	}
+	vouchers, _ := store.Find("ABC")
+	if vouchers[0].Used != 0 {
+		t.Error("Expired reservations should return to quota, used", vouchers[0].Used)
+	}
+	if res := reserveABC(t, store, "alice", base); res.Status != model.VERIFY_OK {
+		t.Error("Customer with expired reservation should reserve again, got", res.Status)
+	}
+}
+
+func testIdempotency(t *testing.T, store VoucherStore) {
+	record := model.Idempotency{
+		Scope:     model.IDEMPOTENCY_REGISTER,
+		Key:       "key-1",
+		Hash:      "hash-1",
+		ExpiresAt: base.Add(time.Hour),
+		CreatedAt: base,
+	}
+	if existing, err := store.BeginIdempotency(record, base); existing != nil || err != nil {
+		t.Fatal("New key should be reserved", existing, err)
+	}
+	if _, err := store.BeginIdempotency(record, base); err != model.ErrIdempotencyInProgress {
+		t.Error("Unfinished key should be ErrIdempotencyInProgress, got", err)
+	}
+	if err := store.FinishIdempotency(record.Scope, record.Key, []byte(`{"Id":1}`), base.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	existing, err := store.BeginIdempotency(record, base.Add(time.Minute))
+	if err != nil || existing == nil || string(existing.Response) != `{"Id":1}` {
+		t.Error("Finished key should return saved response", existing, err)
+	}
+	other := record
+	other.Hash = "hash-2"
+	if _, err := store.BeginIdempotency(other, base); err != model.ErrIdempotencyMismatch {
+		t.Error("Key with different request should be ErrIdempotencyMismatch, got", err)
+	}
+	// Cung key nhung khac scope la key khac
+	other.Scope = model.IDEMPOTENCY_REDEEM
+	if existing, err := store.BeginIdempotency(other, base); existing != nil || err != nil {
+		t.Error("Key in other scope should be reserved", existing, err)
+	}
+	if err := store.AbortIdempotency(other.Scope, other.Key); err != nil {
+		t.Fatal(err)
+	}
+	if existing, err := store.BeginIdempotency(other, base); existing != nil || err != nil {
+		t.Error("Aborted key should be reserved again", existing, err)
+	}
+	// Het han thi key duoc dung lai cho request moi
+	expired := record
+	expired.Hash = "hash-3"
+	expired.ExpiresAt = base.Add(time.Hour * 3)
+	if existing, err := store.BeginIdempotency(expired, base.Add(time.Hour*2)); existing != nil || err != nil {
+		t.Error("Expired key should be reserved again", existing, err)
+	}
+	count, err := store.ExpireIdempotency(base.Add(time.Hour * 4))
+	if err != nil || count != 2 {
+		t.Error("Should expire 2 keys", count, err)
+	}
+}
+
+func testIdempotentRegister(t *testing.T, store VoucherStore) {
+	calls := 0
+	register := func(voucher model.Voucher) (model.Voucher, bool, error) {
+		replayed, err := Idempotent(store, model.IDEMPOTENCY_REGISTER, "key-1", voucher, &voucher, time.Hour, func() error {
+			calls++
+			return store.Register(&voucher)
+		})
+		return voucher, replayed, err
+	}
+	first, replayed, err := register(*newVoucher("ABC", 0, 48))
+	if err != nil || replayed {
+		t.Fatal("First request should run", replayed, err)
+	}
+	second, replayed, err := register(*newVoucher("ABC", 0, 48))
+	if err != nil || !replayed || second.Id != first.Id || calls != 1 {
+		t.Error("Retry should return the original voucher without insert", second, replayed, err, calls)
+	}
+	if _, _, err = register(*newVoucher("XYZ", 0, 48)); err != model.ErrIdempotencyMismatch {
+		t.Error("Same key with other voucher should be ErrIdempotencyMismatch, got", err)
+	}
+	// Request loi thi khong luu, retry se chay lai
+	_, err = Idempotent(store, model.IDEMPOTENCY_REGISTER, "key-2", *newVoucher("ABC", 1, 2), &model.Voucher{}, time.Hour, func() error {
+		calls++
+		return ErrExist
+	})
+	if err != ErrExist {
+		t.Fatal("Error should be returned, got", err)
+	}
+	if existing, err := store.BeginIdempotency(model.Idempotency{
+		Scope:     model.IDEMPOTENCY_REGISTER,
+		Key:       "key-2",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, time.Now()); existing != nil || err != nil {
+		t.Error("Failed request should release its key", existing, err)
+	}
+	// Key dang chay chi duoc giu trong lease, xong roi moi duoc giu toi het ttl
+	afterLease := func() (*model.Idempotency, error) {
+		hash, _ := hashRequest(*newVoucher("ABC", 2, 3))
+		return store.BeginIdempotency(model.Idempotency{
+			Scope:     model.IDEMPOTENCY_REGISTER,
+			Key:       "key-3",
+			Hash:      hash,
+			ExpiresAt: time.Now().Add(time.Hour * 2),
+		}, time.Now().Add(model.IDEMPOTENCY_LEASE+time.Second))
+	}
+	voucher := *newVoucher("ABC", 2, 3)
+	Idempotent(store, model.IDEMPOTENCY_REGISTER, "key-3", voucher, &voucher, time.Hour, func() error {
+		if existing, err := afterLease(); existing != nil || err != nil {
+			t.Error("Unfinished key should be reclaimed after lease", existing, err)
+		}
+		return store.AbortIdempotency(model.IDEMPOTENCY_REGISTER, "key-3")
+	})
+	Idempotent(store, model.IDEMPOTENCY_REGISTER, "key-3", voucher, &voucher, time.Hour, func() error {
+		return nil
+	})
+	if existing, err := afterLease(); existing == nil || err != nil {
+		t.Error("Finished key should be kept for ttl", existing, err)
+	}
+}
+
+func testRule(t *testing.T, store VoucherStore) {
+	voucher := newVoucher("BOOK", 0, 48)
+	voucher.Discount = 30
+	voucher.Quota = 2
+	voucher.Rule = model.Rule{
+		Type:        model.DISCOUNT_FIXED,
+		DiscountCap: 25,
+		MinOrder:    50,
+		Categories:  []string{"book", "comic"},
+		Stackable:   true,
+	}
+	if err := store.Register(voucher); err != nil {
+		t.Fatal(err)
+	}
+	vouchers, err := store.Find("BOOK")
+	if err != nil || len(vouchers) != 1 {
+		t.Fatal(vouchers, err)
+	}
+	if vouchers[0].Type != model.DISCOUNT_FIXED || vouchers[0].DiscountCap != 25 || vouchers[0].MinOrder != 50 ||
+		len(vouchers[0].Categories) != 2 || !vouchers[0].Stackable {
+		t.Error("Rule should be stored", vouchers[0].Rule)
+	}
+	now := base.Add(time.Hour)
+	req := model.VerifyReq{Code: "BOOK", Items: []model.OrderItem{{Category: "food", Amount: 100}}}
+	res, err := store.Redeem(req, now)
+	if err != nil || res.Status != model.VERIFY_NOT_APPLICABLE || res.Reasons[0].Code != model.REASON_CATEGORY {
+		t.Error("Order without book should be NOT_APPLICABLE", res, err)
+	}
+	req.Items = append(req.Items, model.OrderItem{Category: "comic", Amount: 40})
+	res, err = store.Redeem(req, now)
+	if err != nil || res.Status != model.VERIFY_OK || res.Discount != 25 || res.Total != 115 {
+		t.Error("Discount should be capped at 25", res, err)
+	}
+	vouchers, _ = store.Find("BOOK")
+	if vouchers[0].Used != 1 {
+		t.Error("Not applicable order should not use quota, used", vouchers[0].Used)
+	}
+	evaluateRes, err := Evaluate(store, model.EvaluateReq{Codes: []string{"BOOK", "NONE"}, Items: req.Items}, now)
+	if err != nil || evaluateRes.Discount != 25 || len(evaluateRes.Evaluations) != 2 ||
+		evaluateRes.Evaluations[1].Reasons[0].Code != model.VERIFY_NOT_FOUND {
+		t.Error("Evaluate should apply BOOK and reject NONE", evaluateRes, err)
+	}
+	evaluateRes, err = Evaluate(store, model.EvaluateReq{Codes: []string{"NONE", "BOOK", "BOOK"}, Items: req.Items}, now)
+	if err != nil || evaluateRes.Discount != 25 || len(evaluateRes.Evaluations) != 2 ||
+		evaluateRes.Evaluations[0].Code != "NONE" || evaluateRes.Evaluations[1].Code != "BOOK" {
+		t.Error("Evaluate should apply duplicated BOOK once and keep the order of codes", evaluateRes, err)
+	}
+}
+
+func testLifecycle(t *testing.T, store VoucherStore) {
+	voucher := newVoucher("ABC", 0, 48)
+	if err := store.Register(voucher); err != nil {
+		t.Fatal(err)
+	}
+	next := newVoucher("ABC", 72, 96)
+	if err := store.Register(next); err != nil {
+		t.Fatal(err)
+	}
+	now := base.Add(time.Hour)
+	if _, err := Deactivate(store, voucher.Id, "alice", now); err != nil {
+		t.Fatal(err)
+	}
+	res, _ := store.Verify(model.VerifyReq{Code: "ABC", Amount: 100}, now)
+	if res.Status != model.VERIFY_INACTIVE {
+		t.Error("Deactivated voucher should be INACTIVE, got", res.Status)
+	}
+	listRes, _ := store.List(model.ListReq{Status: model.STATUS_INACTIVE, Now: now})
+	if len(listRes.Vouchers) != 1 || listRes.Vouchers[0].Id != voucher.Id {
+		t.Error("List inactive should return deactivated voucher", listRes.Vouchers)
+	}
+	// Deactivate lai khong thay doi gi nen khong ghi audit
+	if _, err := Deactivate(store, voucher.Id, "alice", now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Reactivate(store, voucher.Id, "bob", now); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := store.Verify(model.VerifyReq{Code: "ABC", Amount: 100}, now); res.Status != model.VERIFY_OK {
+		t.Error("Reactivated voucher should be OK, got", res.Status)
+	}
+	if _, err := Extend(store, voucher.Id, base.Add(time.Hour*72), "bob", now); err != ErrExist {
+		t.Error("Extend overlap with next voucher should be ErrExist, got", err)
+	}
+	if _, err := Extend(store, voucher.Id, base.Add(time.Hour*24), "bob", now); err != model.ErrInvalidExtend {
+		t.Error("Extend to earlier end should be ErrInvalidExtend, got", err)
+	}
+	extended, err := Extend(store, voucher.Id, base.Add(time.Hour*60), "bob", now)
+	if err != nil || !extended.End.Equal(base.Add(time.Hour*60)) {
+		t.Fatal("Extend should change end", extended, err)
+	}
+	found, err := store.FindById(voucher.Id)
+	if err != nil || !found.End.Equal(base.Add(time.Hour*60)) {
+		t.Error("Extended end should be stored", found, err)
+	}
+	audits, err := store.Audits(voucher.Id)
+	if err != nil || len(audits) != 3 {
+		t.Fatal("Should have 3 audits", audits, err)
+	}
+	actions := []string{model.ACTION_DEACTIVATE, model.ACTION_REACTIVATE, model.ACTION_EXTEND}
+	for i, audit := range audits {
+		if audit.Action != actions[i] {
+			t.Error("Audit", i, "should be", actions[i], "got", audit.Action)
+		}
+	}
+	if audits[0].Actor != "alice" || !strings.Contains(string(audits[0].Before), `"Disabled":false`) ||
+		!strings.Contains(string(audits[0].After), `"Disabled":true`) {
+		t.Error("Audit should record actor, before and after", audits[0])
+	}
+}
+
+func testDelete(t *testing.T, store VoucherStore) {
+	voucher := newVoucher("ABC", 0, 48)
+	if err := store.Register(voucher); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Delete(store, voucher.Id, "alice", base); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.FindById(voucher.Id); err != model.ErrVoucherNotFound {
+		t.Error("Deleted voucher should not be found, got", err)
+	}
+	if vouchers, _ := store.Find("ABC"); len(vouchers) != 0 {
+		t.Error("Deleted voucher should not be found by code", vouchers)
+	}
+	if listRes, _ := store.List(model.ListReq{Now: base}); len(listRes.Vouchers) != 0 {
+		t.Error("Deleted voucher should not be listed", listRes.Vouchers)
+	}
+	if _, err := Deactivate(store, voucher.Id, "alice", base); err != model.ErrVoucherNotFound {
+		t.Error("Change deleted voucher should be ErrVoucherNotFound, got", err)
+	}
+	// Code cua voucher da xoa duoc dang ky lai
+	if err := store.Register(newVoucher("ABC", 0, 48)); err != nil {
+		t.Error("Register over deleted voucher should work, got", err)
+	}
+	audits, _ := store.Audits(voucher.Id)
+	if len(audits) != 1 || audits[0].Action != model.ACTION_DELETE {
+		t.Error("Delete should be audited", audits)
+	}
+}
+
+// failPublisher loi o lan publish thu fail
+type failPublisher struct {
+	MemoryPublisher
+	calls int
+	fail  int
+}
+
+func (p *failPublisher) Publish(event model.Event) error {
+	p.calls++
+	if p.calls == p.fail {
+		return errors.New("Kafka is down")
+	}
+	return p.MemoryPublisher.Publish(event)
+}
+
+func testOutbox(t *testing.T, store VoucherStore) {
+	registerQuota(t, store, 2)
+	if err := store.Register(newVoucher("ABC", 1, 2)); err != ErrExist {
+		t.Fatal("Should be ErrExist, got", err)
+	}
+	now := base.Add(time.Hour)
+	if _, err := store.Redeem(model.VerifyReq{Code: "ABC", Amount: 100, Customer: "alice"}, now); err != nil {
+		t.Fatal(err)
+	}
+	reserved := reserveABC(t, store, "bob", now)
+	if _, err := store.Confirm(reserved.Reservation.Id, now); err != nil {
+		t.Fatal(err)
+	}
+	publisher := &failPublisher{fail: 2}
+	count, err := Relay(store, publisher)
+	if err == nil || count != 1 {
+		t.Error("Relay should stop at the failed event", count, err)
+	}
+	publisher.fail = 0
+	if count, err = Relay(store, publisher); err != nil || count != 2 {
+		t.Error("Relay should publish the rest", count, err)
+	}
+	if count, err = Relay(store, publisher); err != nil || count != 0 {
+		t.Error("Published events should not be published again", count, err)
+	}
+	events := publisher.Events()
+	types := []string{model.EVENT_VOUCHER_REGISTERED, model.EVENT_VOUCHER_REDEEMED, model.EVENT_VOUCHER_REDEEMED}
+	if len(events) != len(types) {
+		t.Fatal("Should publish 3 events, got", events)
+	}
+	for i, event := range events {
+		if event.Type != types[i] || event.VoucherId != 1 {
+			t.Error("Event", i, "should be", types[i], "got", event)
+		}
+	}
+	if !strings.Contains(string(events[2].Payload), `"Customer":"bob"`) {
+		t.Error("Redeemed payload should be the redemption", string(events[2].Payload))
+	}
+}
+
+func Test_StartSweeper(t *testing.T) {
+	store := NewMemory()
+	now := time.Now()
+	voucher := newVoucher("ABC", 0, 48)
+	voucher.Start = now.Add(-time.Hour)
+	voucher.End = now.Add(time.Hour)
+	if err := store.Register(voucher); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Reserve(model.VerifyReq{Code: "ABC"}, now, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	stop := StartSweeper(store, time.Millisecond*5)
+	time.Sleep(time.Millisecond * 50)
+	stop()
+	vouchers, _ := store.Find("ABC")
+	if vouchers[0].Used != 0 {
+		t.Error("Sweeper should return expired reservation to quota")
+	}
+}
+
+func Test_Memory(t *testing.T) {
+	storeSuite(t, func(t *testing.T) VoucherStore {
+		return NewMemory()
+	})
+}
+
+func Test_SQLite(t *testing.T) {
+	storeSuite(t, func(t *testing.T) VoucherStore {
+		store, err := NewSQLite(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
+
+// Test_MySQL chi chay khi co MYSQL_DSN (vd: default:secret@/voucher_test?parseTime=true)
+// tro toi database da chay _sql/schema.sql, cac table se bi xoa sach truoc moi test
+func Test_MySQL(t *testing.T) {
+	dsn := os.Getenv("MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_DSN is not set")
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	storeSuite(t, func(t *testing.T) VoucherStore {
+		for _, table := range []string{"voucher", "redemption", "reservation", "idempotency", "audit", "outbox"} {
+			if _, err := db.Exec("TRUNCATE TABLE `" + table + "`"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return Voucher{DB: db}
+	})
+}
+
+func Test_StartRelay(t *testing.T) {
+	store := NewMemory()
+	if err := store.Register(newVoucher("ABC", 0, 48)); err != nil {
+		t.Fatal(err)
+	}
+	publisher := &MemoryPublisher{}
+	stop := StartRelay(store, publisher, time.Millisecond*5)
+	time.Sleep(time.Millisecond * 50)
+	stop()
+	if events := publisher.Events(); len(events) != 1 || events[0].Type != model.EVENT_VOUCHER_REGISTERED {
+		t.Error("Relay should publish VoucherRegistered", events)
+	}
+}
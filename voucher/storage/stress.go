@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"../model"
+)
+
+var ErrInvalidStress = errors.New("Number of requests and codes must be positive")
+
+// Strategy la mot cach register voucher duoc dem ra stress test
+type Strategy struct {
+	Name string
+	// Safe = true nghia la strategy khong duoc phep tao ra duplicate
+	Safe     bool
+	Register func(voucher *model.Voucher) error
+}
+
+type StressReport struct {
+	Strategy   string
+	Safe       bool
+	Requests   int
+	Inserted   int
+	Rejected   int // Bi tu choi vi ErrExist
+	Errors     int
+	Duplicates int // So cap voucher cung code bi overlap
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+// Failed khi strategy Safe ma van de lot duplicate
+func (r StressReport) Failed() bool {
+	return r.Safe && r.Duplicates > 0
+}
+
+func (r StressReport) String() string {
+	return fmt.Sprintf("%-10s safe=%-5v requests=%d inserted=%d rejected=%d errors=%d duplicates=%d p50=%v p90=%v p99=%v max=%v",
+		r.Strategy, r.Safe, r.Requests, r.Inserted, r.Rejected, r.Errors, r.Duplicates, r.P50, r.P90, r.P99, r.Max)
+}
+
+// Strategies tra ve cac cach register cua store.
+// "insert" khong kiem tra overlap, "naive" kiem tra roi moi insert bang 2 lan goi
+// nen van bi chen giua, hai strategy nay chi de thay duplicate xay ra the nao.
+// Voi MySQL chi RegisterIsolation la safe, RegisterAtomic van bi duplicate do
+// subquery khong lock duoc row chua ton tai.
+// SQLite chi chay mot transaction ghi tai mot thoi diem nen RegisterAtomic (mot cau insert)
+// va RegisterIsolation (kiem tra roi insert sau BEGIN IMMEDIATE) deu safe. Naive chi bi
+// chen giua khi co nhieu connection (NewSQLiteWAL), voi 1 connection moi buoc deu tuan tu.
+func Strategies(store VoucherStore) []Strategy {
+	switch s := store.(type) {
+	case Voucher:
+		return []Strategy{
+			{"insert", false, s.RegisterInsert},
+			{"naive", false, s.RegisterNaive},
+			{"atomic", false, s.RegisterAtomic},
+			{"isolation", true, s.RegisterIsolation},
+		}
+	case *SQLite:
+		return []Strategy{
+			{"insert", false, s.RegisterInsert},
+			{"naive", false, s.RegisterNaive},
+			{"atomic", true, s.RegisterAtomic},
+			{"isolation", true, s.RegisterIsolation},
+		}
+	case *Memory:
+		return []Strategy{
+			{"naive", false, s.RegisterNaive},
+			{"register", true, s.Register},
+		}
+	}
+	return []Strategy{
+		{"register", true, store.Register},
+	}
+}
+
+// Stress ban n request register cung luc, chia deu cho codes code (code = strategy name + so thu tu),
+// cac voucher cung code deu overlap nhau nen chi duoc co 1 voucher cho moi code.
+func Stress(store VoucherStore, strategy Strategy, n int, codes int) (*StressReport, error) {
+	if n <= 0 || codes <= 0 {
+		return nil, ErrInvalidStress
+	}
+	base := time.Date(2019, 8, 9, 15, 0, 0, 0, time.UTC)
+	report := &StressReport{
+		Strategy: strategy.Name,
+		Safe:     strategy.Safe,
+		Requests: n,
+	}
+	latencies := make([]time.Duration, n)
+	results := make([]error, n)
+	start := make(chan bool)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			voucher := &model.Voucher{
+				Code:     fmt.Sprintf("%s-%d", strategy.Name, i%codes),
+				Discount: 0.1,
+				Start:    base.Add(time.Hour * time.Duration(i%3)),
+				End:      base.Add(time.Hour * time.Duration(48+i%3)),
+			}
+			// Doi tat ca goroutine san sang roi moi ban
+			<-start
+			begin := time.Now()
+			results[i] = strategy.Register(voucher)
+			latencies[i] = time.Since(begin)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	for _, err := range results {
+		switch err {
+		case nil:
+			report.Inserted++
+		case ErrExist:
+			report.Rejected++
+		default:
+			report.Errors++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	report.P50 = percentile(latencies, 50)
+	report.P90 = percentile(latencies, 90)
+	report.P99 = percentile(latencies, 99)
+	report.Max = percentile(latencies, 100)
+	for i := 0; i < codes; i++ {
+		vouchers, err := store.Find(fmt.Sprintf("%s-%d", strategy.Name, i))
+		if err != nil {
+			return nil, err
+		}
+		report.Duplicates += duplicates(vouchers)
+	}
+	return report, nil
+}
+
+// percentile cua latencies da duoc sort
+func percentile(latencies []time.Duration, p int) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	i := (len(latencies)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return latencies[i]
+}
+
+func duplicates(vouchers []model.Voucher) int {
+	count := 0
+	for i := range vouchers {
+		for j := i + 1; j < len(vouchers); j++ {
+			if vouchers[i].IsOverlap(vouchers[j]) {
+				count++
+			}
+		}
+	}
+	return count
+}
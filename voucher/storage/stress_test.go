@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// stress chay tat ca strategy cua store, race = true thi strategy khong safe
+// phai de lot duplicate, neu khong thi harness khong phat hien duoc race
+func stress(t *testing.T, store VoucherStore, race bool) {
+	for _, strategy := range Strategies(store) {
+		report, err := Stress(store, strategy, 200, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(report)
+		if report.Failed() {
+			t.Error(strategy.Name, "is safe but created", report.Duplicates, "duplicates")
+		}
+		if strategy.Safe && (report.Inserted != 10 || report.Errors != 0) {
+			t.Error(strategy.Name, "should insert 1 voucher per code without error", report)
+		}
+		if race && !strategy.Safe && report.Duplicates == 0 {
+			t.Error(strategy.Name, "is not safe but created no duplicate", report)
+		}
+	}
+}
+
+func Test_Stress_Memory(t *testing.T) {
+	stress(t, NewMemory(), true)
+}
+
+func Test_Stress_Invalid(t *testing.T) {
+	store := NewMemory()
+	strategy := Strategies(store)[0]
+	if _, err := Stress(store, strategy, 10, 0); err != ErrInvalidStress {
+		t.Error("Stress with 0 codes should be invalid, got", err)
+	}
+	if _, err := Stress(store, strategy, 0, 10); err != ErrInvalidStress {
+		t.Error("Stress with 0 requests should be invalid, got", err)
+	}
+}
+
+func Test_Stress_SQLite(t *testing.T) {
+	store, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.DB.Close()
+	// 1 connection: moi cau query chay tuan tu, chi kiem tra cac strategy safe
+	stress(t, store, false)
+}
+
+func Test_Stress_SQLiteWAL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "voucher-stress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewSQLiteWAL(filepath.Join(dir, "voucher.db"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.DB.Close()
+	stress(t, store, true)
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"log"
+	"time"
+)
+
+// StartSweeper chay ExpireReservations moi interval de tra lai quota cua
+// cac reservation qua han va xoa cac idempotency key qua han.
+// Goi ham tra ve de dung sweeper.
+func StartSweeper(store VoucherStore, interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				count, err := store.ExpireReservations(now)
+				if err != nil {
+					log.Println("Sweep reservations:", err)
+				} else if count > 0 {
+					log.Printf("Sweep reservations: %d expired\n", count)
+				}
+				if _, err := store.ExpireIdempotency(now); err != nil {
+					log.Println("Sweep idempotency keys:", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
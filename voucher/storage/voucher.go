@@ -2,44 +2,156 @@ package storage
 
 import (
 	"database/sql"
-	"fmt"
+	"time"
 
 	"../model"
 )
 
+// Voucher la VoucherStore dung MySQL
 type Voucher struct {
 	DB *sql.DB
 }
 
-const REGISTER_ISOLATION = "INSERT INTO `voucher`(`code`, `discount`, `start`, `end`) " +
-	"SELECT ?, ?, ?, ? " +
+const REGISTER_ISOLATION = "INSERT INTO `voucher`(`code`, `discount`, `start`, `end`, `quota`, `max_per_customer`, " +
+	"`type`, `discount_cap`, `min_order`, `categories`, `stackable`) " +
+	"SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? " +
 	"FROM locker " +
 	"WHERE id = 1 AND " +
-	"0 = (SELECT count(*) FROM `voucher` WHERE `code` = ? AND ? >= `start` AND ? <= `end` LIMIT 1) " +
+	"0 = (SELECT count(*) FROM `voucher` WHERE `code` = ? AND ? >= `start` AND ? <= `end` AND `deleted_at` IS NULL LIMIT 1) " +
 	"FOR UPDATE"
 
-const REGISTER_ATOMIC = "INSERT INTO `voucher`(`code`, `discount`, `start`, `end`) " +
-	"SELECT ?, ?, ?, ? " +
-	"WHERE 0 = (SELECT count(*) FROM `voucher` WHERE `code` = ? AND ? >= `start` AND ? <= `end` LIMIT 1)"
-const REGISTER = "INSERT INTO `voucher`(`code`, `discount`, `start`, `end`) " +
-	"VALUES(?, ?, ?, ?)"
+const REGISTER_ATOMIC = "INSERT INTO `voucher`(`code`, `discount`, `start`, `end`, `quota`, `max_per_customer`, " +
+	"`type`, `discount_cap`, `min_order`, `categories`, `stackable`) " +
+	"SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? " +
+	"WHERE 0 = (SELECT count(*) FROM `voucher` WHERE `code` = ? AND ? >= `start` AND ? <= `end` AND `deleted_at` IS NULL LIMIT 1)"
+
+const REGISTER = "INSERT INTO `voucher`(`code`, `discount`, `start`, `end`, `quota`, `max_per_customer`, " +
+	"`type`, `discount_cap`, `min_order`, `categories`, `stackable`) " +
+	"VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+// Dung cho code duoc sinh ra (GenerateBatch), code khong duoc trung voi bat ky voucher nao
+const REGISTER_UNIQUE = "INSERT INTO `voucher`(`code`, `discount`, `start`, `end`, `quota`, `max_per_customer`, " +
+	"`type`, `discount_cap`, `min_order`, `categories`, `stackable`) " +
+	"SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? " +
+	"WHERE 0 = (SELECT count(*) FROM `voucher` WHERE `code` = ? LIMIT 1)"
 
 const COUNT_EXIST = "SELECT count(*) as existing " +
 	"FROM `voucher` " +
-	"WHERE `code` = ? AND ? >= `start` AND ? <= `end` " +
+	"WHERE `code` = ? AND ? >= `start` AND ? <= `end` AND `deleted_at` IS NULL " +
 	"LIMIT 1"
 
-func (s Voucher) IsExit(voucher model.Voucher) (bool, error) {
-	count := 0
-	err := s.DB.QueryRow(COUNT_EXIST, voucher.Code, voucher.End, voucher.Start).Scan(&count)
-	if err != nil {
-		return false, err
-	}
-	fmt.Printf("count %d", count)
-	if count >= 1 {
-		return true, nil
-	}
-	return false, nil
+const FIND_BY_CODE = "SELECT `id`, `code`, `discount`, `start`, `end`, `quota`, `max_per_customer`, `used`, " +
+	"`type`, `discount_cap`, `min_order`, `categories`, `stackable`, `disabled`, `deleted_at` " +
+	"FROM `voucher` " +
+	"WHERE `code` = ? AND `deleted_at` IS NULL " +
+	"ORDER BY `start`"
+
+// LIST duoc them dieu kien loc, sort va phan trang trong listQuery
+const LIST = "SELECT `id`, `code`, `discount`, `start`, `end`, `quota`, `max_per_customer`, `used`, " +
+	"`type`, `discount_cap`, `min_order`, `categories`, `stackable`, `disabled`, `deleted_at` " +
+	"FROM `voucher` "
+
+const FIND_BY_ID = "SELECT `id`, `code`, `discount`, `start`, `end`, `quota`, `max_per_customer`, `used`, " +
+	"`type`, `discount_cap`, `min_order`, `categories`, `stackable`, `disabled`, `deleted_at` " +
+	"FROM `voucher` " +
+	"WHERE `id` = ? AND `deleted_at` IS NULL"
+
+// Giong COUNT_EXIST nhung bo qua chinh voucher dang duoc thay doi
+const COUNT_EXIST_OTHER = "SELECT count(*) as existing " +
+	"FROM `voucher` " +
+	"WHERE `code` = ? AND ? >= `start` AND ? <= `end` AND `id` <> ? AND `deleted_at` IS NULL " +
+	"LIMIT 1"
+
+const UPDATE_VOUCHER = "UPDATE `voucher` SET `start` = ?, `end` = ?, `disabled` = ?, `deleted_at` = ? " +
+	"WHERE `id` = ?"
+
+// Bang audit chi duoc insert, khong bao gio update/delete
+const INSERT_AUDIT = "INSERT INTO `audit`(`voucher_id`, `action`, `actor`, `before`, `after`, `created_at`) " +
+	"VALUES(?, ?, ?, ?, ?, ?)"
+
+const LIST_AUDIT = "SELECT `id`, `voucher_id`, `action`, `actor`, `before`, `after`, `created_at` " +
+	"FROM `audit` " +
+	"WHERE `voucher_id` = ? " +
+	"ORDER BY `id`"
+
+const INSERT_EVENT = "INSERT INTO `outbox`(`voucher_id`, `type`, `payload`, `created_at`) " +
+	"VALUES(?, ?, ?, ?)"
+
+// Relay publish theo thu tu id nen event cua cung voucher luon dung thu tu
+const PENDING_EVENTS = "SELECT `id`, `voucher_id`, `type`, `payload`, `created_at` " +
+	"FROM `outbox` " +
+	"WHERE `published_at` IS NULL " +
+	"ORDER BY `id` " +
+	"LIMIT ?"
+
+// markPublished them danh sach id vao cuoi
+const MARK_PUBLISHED = "UPDATE `outbox` SET `published_at` = ? " +
+	"WHERE `id` IN "
+
+// Lock row = 1 cua locker, giong nhu RegisterIsolation
+const LOCK = "SELECT `id` FROM `locker` WHERE `id` = 1 FOR UPDATE"
+
+// Dieu kien `used` < `quota` de dam bao khong bi redeem qua quota
+const REDEEM = "UPDATE `voucher` SET `used` = `used` + 1 " +
+	"WHERE `id` = ? AND `used` < `quota`"
+
+const INSERT_REDEMPTION = "INSERT INTO `redemption`(`voucher_id`, `customer`, `amount`, `discount`, `created_at`) " +
+	"VALUES(?, ?, ?, ?, ?)"
+
+const COUNT_REDEMPTION = "SELECT " +
+	"(SELECT count(*) FROM `redemption` WHERE `voucher_id` = ? AND `customer` = ?) + " +
+	"(SELECT count(*) FROM `reservation` WHERE `voucher_id` = ? AND `customer` = ? AND `status` = 'reserved')"
+
+const INSERT_RESERVATION = "INSERT INTO `reservation`(`voucher_id`, `customer`, `amount`, `discount`, `status`, `expires_at`, `created_at`) " +
+	"VALUES(?, ?, ?, ?, ?, ?, ?)"
+
+const FIND_RESERVATION = "SELECT `id`, `voucher_id`, `customer`, `amount`, `discount`, `status`, `expires_at`, `created_at` " +
+	"FROM `reservation` " +
+	"WHERE `id` = ?"
+
+const UPDATE_RESERVATION = "UPDATE `reservation` SET `status` = ? " +
+	"WHERE `id` = ? AND `status` = 'reserved'"
+
+// Tra lai quota khi reservation bi release/expire
+const RELEASE = "UPDATE `voucher` SET `used` = `used` - 1 " +
+	"WHERE `id` = ? AND `used` > 0"
+
+const EXPIRE_USED = "UPDATE `voucher` SET `used` = `used` - (" +
+	"SELECT count(*) FROM `reservation` " +
+	"WHERE `reservation`.`voucher_id` = `voucher`.`id` AND `status` = 'reserved' AND `expires_at` < ?) " +
+	"WHERE `id` IN (SELECT `voucher_id` FROM `reservation` WHERE `status` = 'reserved' AND `expires_at` < ?)"
+
+const EXPIRE_RESERVATION = "UPDATE `reservation` SET `status` = 'expired' " +
+	"WHERE `status` = 'reserved' AND `expires_at` < ?"
+
+const DELETE_EXPIRED_IDEMPOTENCY = "DELETE FROM `idempotency` " +
+	"WHERE `scope` = ? AND `key` = ? AND `expires_at` < ?"
+
+const INSERT_IDEMPOTENCY = "INSERT INTO `idempotency`(`scope`, `key`, `hash`, `expires_at`, `created_at`) " +
+	"SELECT ?, ?, ?, ?, ? " +
+	"WHERE 0 = (SELECT count(*) FROM `idempotency` WHERE `scope` = ? AND `key` = ?)"
+
+const FIND_IDEMPOTENCY = "SELECT `scope`, `key`, `hash`, `response`, `expires_at`, `created_at` " +
+	"FROM `idempotency` " +
+	"WHERE `scope` = ? AND `key` = ?"
+
+const FINISH_IDEMPOTENCY = "UPDATE `idempotency` SET `response` = ?, `expires_at` = ? " +
+	"WHERE `scope` = ? AND `key` = ?"
+
+const DELETE_IDEMPOTENCY = "DELETE FROM `idempotency` " +
+	"WHERE `scope` = ? AND `key` = ?"
+
+const EXPIRE_IDEMPOTENCY = "DELETE FROM `idempotency` " +
+	"WHERE `expires_at` < ?"
+
+func (s Voucher) IsExist(voucher model.Voucher) (bool, error) {
+	return isExist(s.DB, voucher)
+}
+
+// Register dung RegisterIsolation vi day la cach duy nhat khong bi duplicate
+// khi co nhieu request cung luc
+func (s Voucher) Register(voucher *model.Voucher) error {
+	return s.RegisterIsolation(voucher)
 }
 
 func (s Voucher) RegisterIsolation(voucher *model.Voucher) error {
@@ -49,32 +161,158 @@ func (s Voucher) RegisterIsolation(voucher *model.Voucher) error {
 	if err != nil {
 		return err
 	}
-	_, err = tx.Exec(REGISTER_ISOLATION,
-		voucher.Code, voucher.Discount, voucher.Start, voucher.End,
-		voucher.Code, voucher.End, voucher.Start)
+	err = register(tx, REGISTER_ISOLATION, voucher, voucher.Code, voucher.End, voucher.Start)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
-	tx.Commit()
-	return nil
+	return tx.Commit()
 }
 
 func (s Voucher) RegisterAtomic(voucher *model.Voucher) error {
-	_, err := s.DB.Exec(REGISTER_ATOMIC,
-		voucher.Code, voucher.Discount, voucher.Start, voucher.End,
-		voucher.Code, voucher.End, voucher.Start)
+	return registerTx(s.DB, REGISTER_ATOMIC, voucher, voucher.Code, voucher.End, voucher.Start)
+}
+
+// RegisterNaive kiem tra IsExist roi moi insert, 2 buoc nay khong atomic
+// nen khi co nhieu request cung luc van bi duplicate data
+func (s Voucher) RegisterNaive(voucher *model.Voucher) error {
+	isExist, err := s.IsExist(*voucher)
 	if err != nil {
 		return err
 	}
-	return nil
+	if isExist {
+		return ErrExist
+	}
+	return registerTx(s.DB, REGISTER, voucher)
 }
 
-func (s Voucher) Register(voucher *model.Voucher) error {
-	_, err := s.DB.Exec(REGISTER, voucher.Code, voucher.Discount, voucher.Start, voucher.End)
+// RegisterInsert la Register ban dau: chi insert, khong kiem tra overlap
+func (s Voucher) RegisterInsert(voucher *model.Voucher) error {
+	return registerTx(s.DB, REGISTER, voucher)
+}
+
+// begin mo transaction va lock row = 1 cua locker giong RegisterIsolation,
+// cac thao tac thay doi quota deu phai di qua day
+func (s Voucher) begin() (*sql.Tx, error) {
+	tx, err := s.DB.Begin()
 	if err != nil {
-		panic(err)
-		return err
+		return nil, err
+	}
+	if _, err = tx.Exec(LOCK); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (s Voucher) RegisterBatch(vouchers []model.Voucher) ([]model.Voucher, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	return registerBatch(tx, vouchers)
+}
+
+func (s Voucher) Find(code string) ([]model.Voucher, error) {
+	return findByCode(s.DB, code)
+}
+
+func (s Voucher) List(req model.ListReq) (*model.ListRes, error) {
+	return list(s.DB, req, mysqlSortColumns)
+}
+
+// Verify chi kiem tra voucher co dung duoc khong, khong danh dau la da dung
+func (s Voucher) Verify(req model.VerifyReq, now time.Time) (*model.VerifyRes, error) {
+	return verify(s.DB, req, now)
+}
+
+// Redeem kiem tra, tang so lan da dung cua voucher va ghi vao redemption
+// trong cung mot transaction.
+// Transaction lock row = 1 cua locker (giong RegisterIsolation) nen cac request
+// redeem cung luc se phai cho nhau, khong the vuot quota hay gioi han cua customer.
+func (s Voucher) Redeem(req model.VerifyReq, now time.Time) (*model.VerifyRes, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return redeem(tx, req, now)
+}
+
+func (s Voucher) Reserve(req model.VerifyReq, now time.Time, ttl time.Duration) (*model.ReserveRes, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	return reserve(tx, req, now, ttl)
+}
+
+func (s Voucher) Confirm(id int, now time.Time) (*model.Reservation, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	return confirm(tx, id, now.UTC())
+}
+
+func (s Voucher) Release(id int) (*model.Reservation, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	return release(tx, id)
+}
+
+func (s Voucher) ExpireReservations(now time.Time) (int, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return 0, err
+	}
+	return expireReservations(tx, now)
+}
+
+func (s Voucher) BeginIdempotency(record model.Idempotency, now time.Time) (*model.Idempotency, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	return beginIdempotency(tx, record, now)
+}
+
+func (s Voucher) FinishIdempotency(scope string, key string, response []byte, expiresAt time.Time) error {
+	_, err := s.DB.Exec(FINISH_IDEMPOTENCY, response, expiresAt.UTC(), scope, key)
+	return err
+}
+
+func (s Voucher) AbortIdempotency(scope string, key string) error {
+	_, err := s.DB.Exec(DELETE_IDEMPOTENCY, scope, key)
+	return err
+}
+
+func (s Voucher) ExpireIdempotency(now time.Time) (int, error) {
+	return expireIdempotency(s.DB, now)
+}
+
+func (s Voucher) FindById(id int) (*model.Voucher, error) {
+	return findById(s.DB, id)
+}
+
+// Change lock giong Redeem nen khong bi chen giua voi Register hay Redeem
+func (s Voucher) Change(id int, action string, actor string, now time.Time, change func(voucher *model.Voucher) error) (*model.Voucher, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	return changeVoucher(tx, id, action, actor, now, change)
+}
+
+func (s Voucher) Audits(voucherId int) ([]model.Audit, error) {
+	return audits(s.DB, voucherId)
+}
+
+func (s Voucher) PendingEvents(limit int) ([]model.Event, error) {
+	return pendingEvents(s.DB, limit)
+}
+
+func (s Voucher) MarkPublished(ids []int, now time.Time) error {
+	return markPublished(s.DB, ids, now)
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"../storage"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// go run stress/main.go -store sqlite -n 1000 -codes 10
+// Thoat voi code 1 neu strategy safe van tao ra duplicate
+func main() {
+	os.Exit(run())
+}
+
+// run tra ve exit code de cac defer Close chay xong truoc khi os.Exit
+func run() int {
+	backend := flag.String("store", "sqlite", "memory, sqlite hoac mysql")
+	dsn := flag.String("dsn", "default:secret@/voucher_test?parseTime=true", "DSN cua mysql")
+	n := flag.Int("n", 1000, "so request register cung luc cho moi strategy")
+	codes := flag.Int("codes", 10, "so code khac nhau")
+	conns := flag.Int("conns", 50, "so connection toi database")
+	flag.Parse()
+	if *n <= 0 || *codes <= 0 || *conns <= 0 {
+		fmt.Println("-n, -codes and -conns must be positive")
+		flag.Usage()
+		return 2
+	}
+
+	var store storage.VoucherStore
+	switch *backend {
+	case "memory":
+		store = storage.NewMemory()
+	case "sqlite":
+		// File tam o che do WAL de cac connection chay song song nhu MySQL
+		dir, err := ioutil.TempDir("", "voucher-stress")
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+		sqlite, err := storage.NewSQLiteWAL(filepath.Join(dir, "voucher.db"), *conns)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		defer sqlite.DB.Close()
+		store = sqlite
+	case "mysql":
+		db, err := sql.Open("mysql", *dsn)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		db.SetMaxOpenConns(*conns)
+		defer db.Close()
+		store = storage.Voucher{DB: db}
+	default:
+		fmt.Println("Unknown store", *backend)
+		return 2
+	}
+
+	failed := false
+	for _, strategy := range storage.Strategies(store) {
+		report, err := storage.Stress(store, strategy, *n, *codes)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		fmt.Println(report)
+		if report.Failed() {
+			failed = true
+		}
+	}
+	if failed {
+		fmt.Println("FAIL: safe strategy created duplicates")
+		return 1
+	}
+	return 0
+}
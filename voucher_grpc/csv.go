@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"../voucher/model"
+	"../voucher/storage"
+	"./proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EXPORT_COLUMNS la header cua file export, import doc theo cung ten cot (bo qua id, used, disabled)
+var EXPORT_COLUMNS = []string{
+	"id", "code", "discount", "start", "end", "quota", "max_per_customer", "used",
+	"type", "discount_cap", "min_order", "categories", "stackable", "disabled",
+}
+
+// ImportVouchers kiem tra va insert tung dong, dong bi loi khong anh huong cac dong khac.
+// Overlap duoc kiem tra boi Register (COUNT_EXIST) nen ca cac dong trong cung file cung khong duoc overlap nhau.
+func (s *voucherServiceImp) ImportVouchers(stream proto.VoucherService_ImportVouchersServer) error {
+	res := &proto.ImportRes{}
+	for {
+		row, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(res)
+		}
+		if err != nil {
+			return err
+		}
+		result, err := s.importRow(row)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if result.Ok {
+			res.Imported++
+		} else {
+			res.Failed++
+		}
+		res.Results = append(res.Results, result)
+	}
+}
+
+func (s *voucherServiceImp) importRow(row *proto.ImportRow) (*proto.ImportResult, error) {
+	result := &proto.ImportResult{Row: row.Row}
+	// Dong client khong doc duoc van nam trong bao cao
+	if len(row.Errors) > 0 {
+		result.Errors = row.Errors
+		return result, nil
+	}
+	if row.Voucher == nil {
+		result.Errors = append(result.Errors, &proto.FieldError{Field: "voucher", Description: "voucher is required"})
+		return result, nil
+	}
+	if violations := validateVoucherReq(row.Voucher); len(violations) > 0 {
+		for _, v := range violations {
+			result.Errors = append(result.Errors, &proto.FieldError{Field: v.Field, Description: v.Description})
+		}
+		return result, nil
+	}
+	voucher := fromVoucherReq(row.Voucher)
+	err := s.Store.Register(&voucher)
+	if err == storage.ErrExist {
+		overlaps, err := s.overlapping(voucher)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range overlaps {
+			result.Errors = append(result.Errors, &proto.FieldError{
+				Field:       "code",
+				Description: fmt.Sprintf("overlap with voucher %d (%s - %s)", v.Id, v.Start.Format(time.RFC3339), v.End.Format(time.RFC3339)),
+			})
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Ok = true
+	result.Id = int32(voucher.Id)
+	return result, nil
+}
+
+// ExportVouchers doc tung trang cua ListVouchers va stream ra tung dong
+func (s *voucherServiceImp) ExportVouchers(req *proto.ExportReq, stream proto.VoucherService_ExportVouchersServer) error {
+	listReq := model.ListReq{
+		CodePrefix: req.CodePrefix,
+		Limit:      model.LIST_MAX_LIMIT,
+		Now:        time.Now(),
+	}
+	if req.Status != proto.VoucherState_STATE_ANY {
+		listReq.Status = strings.ToLower(strings.TrimPrefix(req.Status.String(), "STATE_"))
+	}
+	if err := stream.Send(&proto.ExportRow{Values: EXPORT_COLUMNS}); err != nil {
+		return err
+	}
+	for {
+		res, err := s.Store.List(listReq)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		for _, v := range res.Vouchers {
+			if err := stream.Send(&proto.ExportRow{Values: exportRecord(v)}); err != nil {
+				return err
+			}
+		}
+		if res.NextCursor == "" {
+			return nil
+		}
+		listReq.Cursor = res.NextCursor
+	}
+}
+
+// exportRecord theo thu tu cua EXPORT_COLUMNS, categories phan cach boi dau ;
+func exportRecord(v model.Voucher) []string {
+	return []string{
+		strconv.Itoa(v.Id),
+		v.Code,
+		strconv.FormatFloat(float64(v.Discount), 'f', -1, 32),
+		v.Start.Format(time.RFC3339),
+		v.End.Format(time.RFC3339),
+		strconv.Itoa(v.Quota),
+		strconv.Itoa(v.MaxPerCustomer),
+		strconv.Itoa(v.Used),
+		v.GetType(),
+		strconv.FormatFloat(float64(v.DiscountCap), 'f', -1, 32),
+		strconv.FormatFloat(float64(v.MinOrder), 'f', -1, 32),
+		strings.Join(v.Categories, ";"),
+		strconv.FormatBool(v.Stackable),
+		strconv.FormatBool(v.Disabled),
+	}
+}
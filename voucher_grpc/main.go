@@ -7,49 +7,566 @@ import (
 	"log"
 	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"../voucher/model"
+	"../voucher/publisher"
+	"../voucher/storage"
 	"./proto"
-	"./storage"
 
+	protobuf "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/joho/godotenv"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
 type voucherServiceImp struct {
-	DB *sql.DB
+	Store storage.VoucherStore
+	// Thoi gian giu cho cua mot reservation
+	ReservationTTL time.Duration
+	// Thoi gian giu ket qua cua mot idempotency key
+	IdempotencyTTL time.Duration
 }
 
-const UNKOWN_ERR = 1
-const EXIST_ERR = 400
-
 func (s *voucherServiceImp) Register(ctx context.Context, req *proto.VoucherReq) (*proto.VoucherRes, error) {
 	fmt.Println("Register | 1")
-	voucherStorage := storage.Voucher{
-		DB: s.DB,
+	if violations := validateVoucherReq(req); len(violations) > 0 {
+		return nil, invalidArgument(violations)
+	}
+	voucher := fromVoucherReq(req)
+	// Client retry voi cung idempotency-key se nhan lai voucher da insert
+	replayed, err := storage.Idempotent(s.Store, model.IDEMPOTENCY_REGISTER, idempotencyKey(ctx),
+		voucher, &voucher, s.IdempotencyTTL, func() error {
+			return s.Store.Register(&voucher)
+		})
+	if replayed {
+		grpc.SetHeader(ctx, metadata.Pairs("idempotent-replayed", "true"))
+	}
+	if err == storage.ErrExist {
+		return nil, s.alreadyExists(voucher)
 	}
-	res := &proto.VoucherRes{}
-	isExist, err := voucherStorage.RegisterIsolation(req)
 	if err != nil {
-		res.Error = &proto.Error{
-			Code:    UNKOWN_ERR,
-			Message: err.Error(),
+		return nil, idempotencyError(err)
+	}
+	return &proto.VoucherRes{
+		Data: toVoucher(&voucher),
+	}, nil
+}
+
+type violations []*errdetails.BadRequest_FieldViolation
+
+func (v *violations) add(field string, description string) {
+	*v = append(*v, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+}
+
+// window kiem tra cac field chung cua voucher
+func (v *violations) window(discount float32, rule *proto.Rule, start *timestamp.Timestamp, end *timestamp.Timestamp, quota int32, maxPerCustomer int32) {
+	if err := toRule(rule).Validate(discount); err != nil {
+		v.add("discount", err.Error())
+	}
+	if start == nil {
+		v.add("start", "start is required")
+	}
+	if end == nil {
+		v.add("end", "end is required")
+	}
+	if start != nil && end != nil && end.Seconds < start.Seconds {
+		v.add("end", "end must not be before start")
+	}
+	if quota < 0 {
+		v.add("quota", "quota must not be negative")
+	}
+	if maxPerCustomer < 0 {
+		v.add("max_per_customer", "max_per_customer must not be negative")
+	}
+}
+
+func validateVoucherReq(req *proto.VoucherReq) violations {
+	v := violations{}
+	if req.Code == "" || len(req.Code) > 64 {
+		v.add("code", "code is required and at most 64 characters")
+	}
+	v.window(req.Discount, req.Rule, req.Start, req.End, req.Quota, req.MaxPerCustomer)
+	return v
+}
+
+func validateGenerateReq(req *proto.GenerateReq) violations {
+	v := violations{}
+	if len(req.Prefix) > 32 {
+		v.add("prefix", "prefix is at most 32 characters")
+	}
+	if req.Count < 1 || req.Count > 100000 {
+		v.add("count", "count must be in [1, 100000]")
+	}
+	if req.Length < 0 || req.Length > 32 {
+		v.add("length", "length must be in [0, 32]")
+	}
+	if len(req.Alphabet) > 64 {
+		v.add("alphabet", "alphabet is at most 64 characters")
+	}
+	v.window(req.Discount, req.Rule, req.Start, req.End, req.Quota, req.MaxPerCustomer)
+	return v
+}
+
+func invalidArgument(violations violations) error {
+	st := status.New(codes.InvalidArgument, "Invalid voucher")
+	st, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: violations,
+	})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return st.Err()
+}
+
+// overlapping tra ve cac voucher cung code bi overlap voi voucher
+func (s *voucherServiceImp) overlapping(voucher model.Voucher) ([]model.Voucher, error) {
+	vouchers, err := s.Store.Find(voucher.Code)
+	if err != nil {
+		return nil, err
+	}
+	overlaps := []model.Voucher{}
+	for _, v := range vouchers {
+		if v.IsOverlap(voucher) {
+			overlaps = append(overlaps, v)
 		}
 	}
-	if isExist == true {
-		res.Error = &proto.Error{
-			Code:    EXIST_ERR,
-			Message: "Code is exist",
+	return overlaps, nil
+}
+
+// alreadyExists tra ve AlreadyExists kem theo code va khoang start/end
+// cua cac voucher dang bi overlap
+func (s *voucherServiceImp) alreadyExists(voucher model.Voucher) error {
+	st := status.New(codes.AlreadyExists, storage.ErrExist.Error())
+	vouchers, err := s.overlapping(voucher)
+	if err != nil {
+		return st.Err()
+	}
+	details := []protobuf.Message{}
+	for _, v := range vouchers {
+		details = append(details, &errdetails.ErrorInfo{
+			Reason: "VOUCHER_EXIST",
+			Domain: "voucher",
+			Metadata: map[string]string{
+				"id":    strconv.Itoa(v.Id),
+				"code":  v.Code,
+				"start": v.Start.Format(time.RFC3339),
+				"end":   v.End.Format(time.RFC3339),
+			},
+		})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+func (s *voucherServiceImp) Verify(ctx context.Context, req *proto.VerifyReq) (*proto.VerifyRes, error) {
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+	res, err := s.Store.Verify(model.VerifyReq{
+		Code:     req.Code,
+		Amount:   req.Amount,
+		Customer: req.Customer,
+		Items:    toItems(req.Items),
+	}, time.Now())
+	if err != nil {
+		return nil, verifyError(err)
+	}
+	return toVerifyRes(res), nil
+}
+
+func (s *voucherServiceImp) Redeem(ctx context.Context, req *proto.VerifyReq) (*proto.VerifyRes, error) {
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+	verifyReq := model.VerifyReq{
+		Code:     req.Code,
+		Amount:   req.Amount,
+		Customer: req.Customer,
+		Items:    toItems(req.Items),
+	}
+	res := &model.VerifyRes{}
+	replayed, err := storage.Idempotent(s.Store, model.IDEMPOTENCY_REDEEM, idempotencyKey(ctx),
+		verifyReq, res, s.IdempotencyTTL, func() error {
+			redeemRes, err := s.Store.Redeem(verifyReq, time.Now())
+			if err == nil {
+				*res = *redeemRes
+			}
+			return err
+		})
+	if replayed {
+		grpc.SetHeader(ctx, metadata.Pairs("idempotent-replayed", "true"))
+	}
+	if err != nil {
+		return nil, idempotencyError(err)
+	}
+	return toVerifyRes(res), nil
+}
+
+func (s *voucherServiceImp) GenerateBatch(req *proto.GenerateReq, stream proto.VoucherService_GenerateBatchServer) error {
+	if violations := validateGenerateReq(req); len(violations) > 0 {
+		return invalidArgument(violations)
+	}
+	generateReq := model.GenerateReq{
+		Prefix:         req.Prefix,
+		Count:          int(req.Count),
+		Length:         int(req.Length),
+		Alphabet:       req.Alphabet,
+		Discount:       req.Discount,
+		Start:          time.Unix(req.Start.Seconds, 0),
+		End:            time.Unix(req.End.Seconds, 0),
+		Quota:          int(req.Quota),
+		MaxPerCustomer: int(req.MaxPerCustomer),
+		Rule:           toRule(req.Rule),
+	}
+	err := storage.GenerateBatch(s.Store, generateReq, func(vouchers []model.Voucher) error {
+		res := &proto.GenerateRes{}
+		for _, v := range vouchers {
+			res.Codes = append(res.Codes, v.Code)
 		}
+		return stream.Send(res)
+	})
+	if err == storage.ErrNotEnoughCodes {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (s *voucherServiceImp) ListVouchers(ctx context.Context, req *proto.ListReq) (*proto.ListRes, error) {
+	if req.Limit < 0 || req.Limit > model.LIST_MAX_LIMIT {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be in [0, %d]", model.LIST_MAX_LIMIT)
+	}
+	listReq := model.ListReq{
+		CodePrefix:  req.CodePrefix,
+		MinDiscount: req.MinDiscount,
+		MaxDiscount: req.MaxDiscount,
+		Desc:        req.Desc,
+		Limit:       int(req.Limit),
+		Cursor:      req.Cursor,
+		Now:         time.Now(),
+	}
+	if req.ActiveAt != nil {
+		listReq.ActiveAt = time.Unix(req.ActiveAt.Seconds, 0)
+	}
+	if req.Status != proto.VoucherState_STATE_ANY {
+		listReq.Status = strings.ToLower(strings.TrimPrefix(req.Status.String(), "STATE_"))
+	}
+	listReq.Sort = strings.ToLower(strings.TrimPrefix(req.Sort.String(), "SORT_"))
+	res, err := s.Store.List(listReq)
+	if err == model.ErrInvalidCursor {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
-	res.Data = &proto.Voucher{}
-	res.Data.Id = 1
-	res.Data.Code = req.Code
-	res.Data.Discount = req.Discount
-	res.Data.Start = req.Start
-	res.Data.End = req.End
-	return res, err
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	listRes := &proto.ListRes{
+		NextCursor: res.NextCursor,
+	}
+	for i := range res.Vouchers {
+		listRes.Data = append(listRes.Data, toVoucher(&res.Vouchers[i]))
+	}
+	return listRes, nil
+}
+
+func (s *voucherServiceImp) Evaluate(ctx context.Context, req *proto.EvaluateReq) (*proto.EvaluateRes, error) {
+	if len(req.Codes) == 0 || len(req.Codes) > 10 {
+		return nil, status.Error(codes.InvalidArgument, "codes must have 1 to 10 codes")
+	}
+	res, err := storage.Evaluate(s.Store, model.EvaluateReq{
+		Codes:  req.Codes,
+		Amount: req.Amount,
+		Items:  toItems(req.Items),
+	}, time.Now())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	evaluateRes := &proto.EvaluateRes{
+		Amount:   res.Amount,
+		Discount: res.Discount,
+		Total:    res.Total,
+	}
+	for _, e := range res.Evaluations {
+		evaluateRes.Evaluations = append(evaluateRes.Evaluations, &proto.Evaluation{
+			Code:       e.Code,
+			Applicable: e.Applicable,
+			Eligible:   e.Eligible,
+			Discount:   e.Discount,
+			Reasons:    fromReasons(e.Reasons),
+		})
+	}
+	return evaluateRes, nil
+}
+
+// change kiem tra actor roi ap dung thay doi, loi duoc doi sang gRPC status
+func (s *voucherServiceImp) change(req *proto.ChangeReq, change func(id int, actor string) (*model.Voucher, error)) (*proto.Voucher, error) {
+	if req.Actor == "" || len(req.Actor) > 64 {
+		return nil, status.Error(codes.InvalidArgument, "actor is required and at most 64 characters")
+	}
+	voucher, err := change(int(req.Id), req.Actor)
+	switch err {
+	case nil:
+		return toVoucher(voucher), nil
+	case model.ErrVoucherNotFound:
+		return nil, status.Error(codes.NotFound, err.Error())
+	case model.ErrInvalidExtend:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case storage.ErrExist:
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	return nil, status.Error(codes.Internal, err.Error())
+}
+
+func (s *voucherServiceImp) Deactivate(ctx context.Context, req *proto.ChangeReq) (*proto.Voucher, error) {
+	return s.change(req, func(id int, actor string) (*model.Voucher, error) {
+		return storage.Deactivate(s.Store, id, actor, time.Now())
+	})
+}
+
+func (s *voucherServiceImp) Reactivate(ctx context.Context, req *proto.ChangeReq) (*proto.Voucher, error) {
+	return s.change(req, func(id int, actor string) (*model.Voucher, error) {
+		return storage.Reactivate(s.Store, id, actor, time.Now())
+	})
+}
+
+func (s *voucherServiceImp) Extend(ctx context.Context, req *proto.ChangeReq) (*proto.Voucher, error) {
+	if req.End == nil {
+		return nil, status.Error(codes.InvalidArgument, "end is required")
+	}
+	return s.change(req, func(id int, actor string) (*model.Voucher, error) {
+		return storage.Extend(s.Store, id, time.Unix(req.End.Seconds, 0), actor, time.Now())
+	})
+}
+
+func (s *voucherServiceImp) Delete(ctx context.Context, req *proto.ChangeReq) (*proto.Voucher, error) {
+	return s.change(req, func(id int, actor string) (*model.Voucher, error) {
+		return storage.Delete(s.Store, id, actor, time.Now())
+	})
+}
+
+func (s *voucherServiceImp) ListAudits(ctx context.Context, req *proto.AuditReq) (*proto.AuditRes, error) {
+	audits, err := s.Store.Audits(int(req.VoucherId))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	res := &proto.AuditRes{}
+	for _, a := range audits {
+		res.Data = append(res.Data, &proto.Audit{
+			Id:        int32(a.Id),
+			VoucherId: int32(a.VoucherId),
+			Action:    a.Action,
+			Actor:     a.Actor,
+			Before:    string(a.Before),
+			After:     string(a.After),
+			CreatedAt: &timestamp.Timestamp{Seconds: a.CreatedAt.Unix()},
+		})
+	}
+	return res, nil
+}
+
+func (s *voucherServiceImp) Reserve(ctx context.Context, req *proto.VerifyReq) (*proto.ReserveRes, error) {
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+	res, err := s.Store.Reserve(model.VerifyReq{
+		Code:     req.Code,
+		Amount:   req.Amount,
+		Customer: req.Customer,
+		Items:    toItems(req.Items),
+	}, time.Now(), s.ReservationTTL)
+	if err != nil {
+		return nil, verifyError(err)
+	}
+	return &proto.ReserveRes{
+		Verify:      toVerifyRes(&res.VerifyRes),
+		Reservation: toReservation(res.Reservation),
+	}, nil
+}
+
+func (s *voucherServiceImp) Confirm(ctx context.Context, req *proto.ReservationReq) (*proto.Reservation, error) {
+	reservation, err := s.Store.Confirm(int(req.Id), time.Now())
+	if err != nil {
+		return nil, reservationError(err)
+	}
+	return toReservation(reservation), nil
+}
+
+func (s *voucherServiceImp) Release(ctx context.Context, req *proto.ReservationReq) (*proto.Reservation, error) {
+	reservation, err := s.Store.Release(int(req.Id))
+	if err != nil {
+		return nil, reservationError(err)
+	}
+	return toReservation(reservation), nil
+}
+
+// idempotencyKey lay key tu metadata idempotency-key cua request, khong co thi tra ve ""
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	keys := md.Get("idempotency-key")
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+func idempotencyError(err error) error {
+	switch err {
+	case model.ErrIdempotencyInProgress:
+		return status.Error(codes.Aborted, err.Error())
+	case model.ErrIdempotencyMismatch:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return verifyError(err)
+}
+
+// verifyError la loi cua Verify, Redeem va Reserve
+func verifyError(err error) error {
+	if err == model.ErrCustomerRequired {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func reservationError(err error) error {
+	switch err {
+	case model.ErrReservationNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case model.ErrReservationExpired, model.ErrReservationClosed:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func fromVoucherReq(req *proto.VoucherReq) model.Voucher {
+	return model.Voucher{
+		Code:           req.Code,
+		Discount:       req.Discount,
+		Start:          time.Unix(req.Start.Seconds, 0),
+		End:            time.Unix(req.End.Seconds, 0),
+		Quota:          int(req.Quota),
+		MaxPerCustomer: int(req.MaxPerCustomer),
+		Rule:           toRule(req.Rule),
+	}
+}
+
+func toVoucher(voucher *model.Voucher) *proto.Voucher {
+	if voucher == nil {
+		return nil
+	}
+	return &proto.Voucher{
+		Id:             int32(voucher.Id),
+		Code:           voucher.Code,
+		Discount:       voucher.Discount,
+		Start:          &timestamp.Timestamp{Seconds: voucher.Start.Unix()},
+		End:            &timestamp.Timestamp{Seconds: voucher.End.Unix()},
+		Quota:          int32(voucher.Quota),
+		MaxPerCustomer: int32(voucher.MaxPerCustomer),
+		Used:           int32(voucher.Used),
+		Rule:           fromRule(voucher.Rule),
+		Disabled:       voucher.Disabled,
+	}
+}
+
+func toVerifyRes(res *model.VerifyRes) *proto.VerifyRes {
+	return &proto.VerifyRes{
+		Status:   proto.VerifyStatus(proto.VerifyStatus_value[res.Status]),
+		Data:     toVoucher(res.Voucher),
+		Amount:   res.Amount,
+		Discount: res.Discount,
+		Total:    res.Total,
+		Reasons:  fromReasons(res.Reasons),
+	}
+}
+
+func toRule(rule *proto.Rule) model.Rule {
+	if rule == nil {
+		return model.Rule{}
+	}
+	return model.Rule{
+		Type:        strings.ToLower(rule.Type.String()),
+		DiscountCap: rule.DiscountCap,
+		MinOrder:    rule.MinOrder,
+		Categories:  rule.Categories,
+		Stackable:   rule.Stackable,
+	}
+}
+
+func fromRule(rule model.Rule) *proto.Rule {
+	return &proto.Rule{
+		Type:        proto.DiscountType(proto.DiscountType_value[strings.ToUpper(rule.GetType())]),
+		DiscountCap: rule.DiscountCap,
+		MinOrder:    rule.MinOrder,
+		Categories:  rule.Categories,
+		Stackable:   rule.Stackable,
+	}
+}
+
+func toItems(items []*proto.OrderItem) []model.OrderItem {
+	orderItems := []model.OrderItem{}
+	for _, item := range items {
+		orderItems = append(orderItems, model.OrderItem{
+			Category: item.Category,
+			Amount:   item.Amount,
+		})
+	}
+	return orderItems
+}
+
+func fromReasons(reasons []model.Reason) []*proto.Reason {
+	protoReasons := []*proto.Reason{}
+	for _, reason := range reasons {
+		protoReasons = append(protoReasons, &proto.Reason{
+			Code:    reason.Code,
+			Message: reason.Message,
+		})
+	}
+	return protoReasons
+}
+
+func toReservation(reservation *model.Reservation) *proto.Reservation {
+	if reservation == nil {
+		return nil
+	}
+	reservationStatus := strings.ToUpper(reservation.Status)
+	if reservation.Status == model.RESERVATION_EXPIRED {
+		reservationStatus = "RESERVATION_EXPIRED"
+	}
+	return &proto.Reservation{
+		Id:        int32(reservation.Id),
+		VoucherId: int32(reservation.VoucherId),
+		Customer:  reservation.Customer,
+		Amount:    reservation.Amount,
+		Discount:  reservation.Discount,
+		Status:    proto.ReservationStatus(proto.ReservationStatus_value[reservationStatus]),
+		ExpiresAt: &timestamp.Timestamp{Seconds: reservation.ExpiresAt.Unix()},
+		CreatedAt: &timestamp.Timestamp{Seconds: reservation.CreatedAt.Unix()},
+	}
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
 }
 
 func main() {
@@ -59,7 +576,7 @@ func main() {
 		log.Fatal("Error loading .env file")
 	}
 	// Connect Db
-	db, err := sql.Open("mysql", "default:secret@/voucher")
+	db, err := sql.Open("mysql", "default:secret@/voucher?parseTime=true")
 	if err != nil {
 		panic(err)
 	}
@@ -77,7 +594,26 @@ func main() {
 	grpcServer := grpc.NewServer()
 	// 3. Map service to server
 	voucherService := &voucherServiceImp{
-		DB: db,
+		Store:          storage.Voucher{DB: db},
+		ReservationTTL: getDuration("RESERVATION_TTL", 10*time.Minute),
+		IdempotencyTTL: getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+	}
+	// Reservation qua han se duoc sweeper tra lai quota
+	stopSweeper := storage.StartSweeper(voucherService.Store, getDuration("SWEEP_INTERVAL", time.Minute))
+	defer stopSweeper()
+	// Relay event trong outbox len Kafka, khong co KAFKA_BROKERS thi event nam lai trong outbox
+	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+		topic := os.Getenv("KAFKA_TOPIC")
+		if topic == "" {
+			topic = "voucher_events"
+		}
+		kafka, err := publisher.NewStaticKafka(strings.Split(brokers, ","), topic)
+		if err != nil {
+			panic(err)
+		}
+		defer kafka.Close()
+		stopRelay := storage.StartRelay(voucherService.Store, kafka, getDuration("RELAY_INTERVAL", time.Second))
+		defer stopRelay()
 	}
 	proto.RegisterVoucherServiceServer(grpcServer, voucherService)
 	// 4. Binding port
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"../voucher/storage"
+	"./proto"
+
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newVoucherReq(code string, from time.Time, hours int) *proto.VoucherReq {
+	return &proto.VoucherReq{
+		Code:     code,
+		Discount: 0.05,
+		Start:    &timestamp.Timestamp{Seconds: from.Unix()},
+		End:      &timestamp.Timestamp{Seconds: from.Add(time.Hour * time.Duration(hours)).Unix()},
+	}
+}
+
+func Test_Register_ReturnInsertedId(t *testing.T) {
+	service := &voucherServiceImp{Store: storage.NewMemory()}
+	now := time.Now()
+	for i, code := range []string{"ABC", "XYZ"} {
+		res, err := service.Register(context.TODO(), newVoucherReq(code, now, 48))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Data.Id != int32(i+1) {
+			t.Error("Id should be the inserted id, got", res.Data.Id)
+		}
+	}
+}
+
+func Test_Register_InvalidArgument(t *testing.T) {
+	service := &voucherServiceImp{Store: storage.NewMemory()}
+	req := newVoucherReq("", time.Now(), -1)
+	_, err := service.Register(context.TODO(), req)
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatal("Code should be InvalidArgument, got", st.Code())
+	}
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok || len(badRequest.FieldViolations) != 2 {
+		t.Error("Details should have violations of code and end", st.Details())
+	}
+}
+
+func Test_Register_AlreadyExists(t *testing.T) {
+	service := &voucherServiceImp{Store: storage.NewMemory()}
+	now := time.Now()
+	if _, err := service.Register(context.TODO(), newVoucherReq("ABC", now, 48)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := service.Register(context.TODO(), newVoucherReq("ABC", now.Add(time.Hour), 48))
+	st := status.Convert(err)
+	if st.Code() != codes.AlreadyExists {
+		t.Fatal("Code should be AlreadyExists, got", st.Code())
+	}
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	if !ok || info.Metadata["code"] != "ABC" || info.Metadata["id"] != "1" {
+		t.Error("Details should have the conflicting voucher", st.Details())
+	}
+}
+
+func Test_ListVouchers_Paging(t *testing.T) {
+	service := &voucherServiceImp{Store: storage.NewMemory()}
+	now := time.Now()
+	for _, code := range []string{"SALE-1", "SALE-2", "SALE-3", "XYZ"} {
+		if _, err := service.Register(context.TODO(), newVoucherReq(code, now, 48)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := &proto.ListReq{
+		CodePrefix: "SALE-",
+		Status:     proto.VoucherState_STATE_ACTIVE,
+		Sort:       proto.SortField_SORT_CODE,
+		Desc:       true,
+		Limit:      2,
+	}
+	res, err := service.ListVouchers(context.TODO(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data) != 2 || res.Data[0].Code != "SALE-3" || res.NextCursor == "" {
+		t.Fatal("First page should be SALE-3, SALE-2", res)
+	}
+	req.Cursor = res.NextCursor
+	res, err = service.ListVouchers(context.TODO(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Code != "SALE-1" || res.NextCursor != "" {
+		t.Error("Last page should be SALE-1", res)
+	}
+}
+
+func Test_Reserve_ConfirmAndRelease(t *testing.T) {
+	service := &voucherServiceImp{Store: storage.NewMemory(), ReservationTTL: time.Minute}
+	req := newVoucherReq("ABC", time.Now().Add(-time.Hour), 48)
+	req.Quota = 2
+	if _, err := service.Register(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+	res, err := service.Reserve(context.TODO(), &proto.VerifyReq{Code: "ABC", Amount: 100, Customer: "c-001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Verify.Status != proto.VerifyStatus_OK || res.Reservation.Status != proto.ReservationStatus_RESERVED {
+		t.Fatal("Reservation should be reserved, got", res)
+	}
+	confirmed, err := service.Confirm(context.TODO(), &proto.ReservationReq{Id: res.Reservation.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmed.Status != proto.ReservationStatus_CONFIRMED {
+		t.Error("Reservation should be confirmed, got", confirmed.Status)
+	}
+	_, err = service.Release(context.TODO(), &proto.ReservationReq{Id: res.Reservation.Id})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Error("Release confirmed reservation should be FailedPrecondition, got", err)
+	}
+	_, err = service.Confirm(context.TODO(), &proto.ReservationReq{Id: 100})
+	if status.Code(err) != codes.NotFound {
+		t.Error("Unknown reservation should be NotFound, got", err)
+	}
+}
+
+func Test_Register_IdempotencyKey(t *testing.T) {
+	service := &voucherServiceImp{Store: storage.NewMemory(), IdempotencyTTL: time.Hour}
+	ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("idempotency-key", "key-1"))
+	req := newVoucherReq("ABC", time.Now(), 48)
+	first, err := service.Register(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Retry khong bi AlreadyExists ma nhan lai voucher da insert
+	second, err := service.Register(ctx, req)
+	if err != nil {
+		t.Fatal("Retry should not fail, got", err)
+	}
+	if second.Data.Id != first.Data.Id {
+		t.Error("Retry should return the original voucher, got", second.Data.Id)
+	}
+	_, err = service.Register(ctx, newVoucherReq("XYZ", time.Now(), 48))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Error("Same key with other request should be InvalidArgument, got", err)
+	}
+}
+
+func Test_Evaluate_Rule(t *testing.T) {
+	service := &voucherServiceImp{Store: storage.NewMemory()}
+	req := newVoucherReq("BOOK", time.Now().Add(-time.Hour), 48)
+	req.Discount = 30
+	req.Rule = &proto.Rule{Type: proto.DiscountType_FIXED, Categories: []string{"book"}}
+	res, err := service.Register(context.TODO(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Data.Rule.Type != proto.DiscountType_FIXED {
+		t.Error("Rule should be returned, got", res.Data.Rule)
+	}
+	items := []*proto.OrderItem{{Category: "food", Amount: 100}}
+	verifyRes, err := service.Verify(context.TODO(), &proto.VerifyReq{Code: "BOOK", Items: items})
+	if err != nil || verifyRes.Status != proto.VerifyStatus_NOT_APPLICABLE || len(verifyRes.Reasons) == 0 {
+		t.Error("Order without book should be NOT_APPLICABLE with reasons", verifyRes, err)
+	}
+	items = append(items, &proto.OrderItem{Category: "book", Amount: 50})
+	evaluateRes, err := service.Evaluate(context.TODO(), &proto.EvaluateReq{Codes: []string{"BOOK"}, Items: items})
+	if err != nil || evaluateRes.Discount != 30 || evaluateRes.Total != 120 {
+		t.Error("Evaluate should apply fixed discount", evaluateRes, err)
+	}
+}
+
+func Test_Lifecycle(t *testing.T) {
+	service := &voucherServiceImp{Store: storage.NewMemory()}
+	res, err := service.Register(context.TODO(), newVoucherReq("ABC", time.Now().Add(-time.Hour), 48))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := res.Data.Id
+	if _, err := service.Deactivate(context.TODO(), &proto.ChangeReq{Id: id}); status.Code(err) != codes.InvalidArgument {
+		t.Error("Change without actor should be InvalidArgument, got", err)
+	}
+	voucher, err := service.Deactivate(context.TODO(), &proto.ChangeReq{Id: id, Actor: "alice"})
+	if err != nil || !voucher.Disabled {
+		t.Fatal("Voucher should be deactivated", voucher, err)
+	}
+	verifyRes, _ := service.Verify(context.TODO(), &proto.VerifyReq{Code: "ABC", Amount: 100})
+	if verifyRes.Status != proto.VerifyStatus_INACTIVE {
+		t.Error("Deactivated voucher should be INACTIVE, got", verifyRes.Status)
+	}
+	if _, err := service.Delete(context.TODO(), &proto.ChangeReq{Id: id, Actor: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Reactivate(context.TODO(), &proto.ChangeReq{Id: id, Actor: "alice"}); status.Code(err) != codes.NotFound {
+		t.Error("Change deleted voucher should be NotFound, got", err)
+	}
+	audits, err := service.ListAudits(context.TODO(), &proto.AuditReq{VoucherId: id})
+	if err != nil || len(audits.Data) != 2 || audits.Data[1].Action != "delete" || audits.Data[0].Actor != "alice" {
+		t.Error("Should have deactivate and delete audits", audits, err)
+	}
+}
+
+type importStream struct {
+	grpc.ServerStream
+	rows []*proto.ImportRow
+	res  *proto.ImportRes
+}
+
+func (s *importStream) Recv() (*proto.ImportRow, error) {
+	if len(s.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := s.rows[0]
+	s.rows = s.rows[1:]
+	return row, nil
+}
+
+func (s *importStream) SendAndClose(res *proto.ImportRes) error {
+	s.res = res
+	return nil
+}
+
+type exportStream struct {
+	grpc.ServerStream
+	rows [][]string
+}
+
+func (s *exportStream) Send(row *proto.ExportRow) error {
+	s.rows = append(s.rows, row.Values)
+	return nil
+}
+
+func Test_ImportExport(t *testing.T) {
+	service := &voucherServiceImp{Store: storage.NewMemory()}
+	now := time.Now()
+	invalid := newVoucherReq("BAD", now, -1)
+	invalid.Discount = 2
+	stream := &importStream{rows: []*proto.ImportRow{
+		{Row: 2, Voucher: newVoucherReq("ABC", now, 48)},
+		{Row: 3, Voucher: invalid},
+		{Row: 4, Voucher: newVoucherReq("ABC", now.Add(time.Hour), 48)},
+		{Row: 5, Voucher: newVoucherReq("XYZ", now, 48)},
+		{Row: 6, Errors: []*proto.FieldError{{Field: "type", Description: "unknown discount type"}}},
+	}}
+	if err := service.ImportVouchers(stream); err != nil {
+		t.Fatal(err)
+	}
+	if stream.res.Imported != 2 || stream.res.Failed != 3 {
+		t.Fatal("Should import 2 rows and fail 3 rows", stream.res)
+	}
+	if results := stream.res.Results; !results[0].Ok || len(results[1].Errors) != 2 || results[2].Errors[0].Field != "code" {
+		t.Error("Should report discount and end errors for row 3, overlap for row 4", results)
+	}
+	if result := stream.res.Results[4]; result.Ok || result.Row != 6 || result.Errors[0].Field != "type" {
+		t.Error("Should report client error for row 6", result)
+	}
+	export := &exportStream{}
+	if err := service.ExportVouchers(&proto.ExportReq{}, export); err != nil {
+		t.Fatal(err)
+	}
+	if len(export.rows) != 3 || export.rows[0][1] != "code" || export.rows[1][1] != "ABC" || export.rows[2][1] != "XYZ" {
+		t.Error("Export should have header and 2 vouchers", export.rows)
+	}
+}
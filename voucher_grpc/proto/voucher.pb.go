@@ -25,51 +25,176 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
-type Error struct {
-	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+// Ly do ma voucher bi tu choi khi verify/redeem
+type VerifyStatus int32
+
+const (
+	VerifyStatus_OK             VerifyStatus = 0
+	VerifyStatus_NOT_FOUND      VerifyStatus = 1
+	VerifyStatus_EXPIRED        VerifyStatus = 2
+	VerifyStatus_NOT_STARTED    VerifyStatus = 3
+	VerifyStatus_EXHAUSTED      VerifyStatus = 4
+	VerifyStatus_CUSTOMER_LIMIT VerifyStatus = 5
+	VerifyStatus_NOT_APPLICABLE VerifyStatus = 6
+	VerifyStatus_INACTIVE       VerifyStatus = 7
+)
+
+var VerifyStatus_name = map[int32]string{
+	0: "OK",
+	1: "NOT_FOUND",
+	2: "EXPIRED",
+	3: "NOT_STARTED",
+	4: "EXHAUSTED",
+	5: "CUSTOMER_LIMIT",
+	6: "NOT_APPLICABLE",
+	7: "INACTIVE",
+}
+
+var VerifyStatus_value = map[string]int32{
+	"OK":             0,
+	"NOT_FOUND":      1,
+	"EXPIRED":        2,
+	"NOT_STARTED":    3,
+	"EXHAUSTED":      4,
+	"CUSTOMER_LIMIT": 5,
+	"NOT_APPLICABLE": 6,
+	"INACTIVE":       7,
+}
+
+func (x VerifyStatus) String() string {
+	return proto.EnumName(VerifyStatus_name, int32(x))
 }
 
-func (m *Error) Reset()         { *m = Error{} }
-func (m *Error) String() string { return proto.CompactTextString(m) }
-func (*Error) ProtoMessage()    {}
-func (*Error) Descriptor() ([]byte, []int) {
+func (VerifyStatus) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_60f00a0a2a5aeccc, []int{0}
 }
 
-func (m *Error) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Error.Unmarshal(m, b)
+type DiscountType int32
+
+const (
+	DiscountType_PERCENT DiscountType = 0
+	DiscountType_FIXED   DiscountType = 1
+)
+
+var DiscountType_name = map[int32]string{
+	0: "PERCENT",
+	1: "FIXED",
 }
-func (m *Error) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Error.Marshal(b, m, deterministic)
+
+var DiscountType_value = map[string]int32{
+	"PERCENT": 0,
+	"FIXED":   1,
 }
-func (m *Error) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Error.Merge(m, src)
+
+func (x DiscountType) String() string {
+	return proto.EnumName(DiscountType_name, int32(x))
 }
-func (m *Error) XXX_Size() int {
-	return xxx_messageInfo_Error.Size(m)
+
+func (DiscountType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{1}
 }
-func (m *Error) XXX_DiscardUnknown() {
-	xxx_messageInfo_Error.DiscardUnknown(m)
+
+// Enum value nam chung scope voi VerifyStatus nen phai co prefix
+type VoucherState int32
+
+const (
+	VoucherState_STATE_ANY       VoucherState = 0
+	VoucherState_STATE_ACTIVE    VoucherState = 1
+	VoucherState_STATE_UPCOMING  VoucherState = 2
+	VoucherState_STATE_EXPIRED   VoucherState = 3
+	VoucherState_STATE_EXHAUSTED VoucherState = 4
+	VoucherState_STATE_INACTIVE  VoucherState = 5
+)
+
+var VoucherState_name = map[int32]string{
+	0: "STATE_ANY",
+	1: "STATE_ACTIVE",
+	2: "STATE_UPCOMING",
+	3: "STATE_EXPIRED",
+	4: "STATE_EXHAUSTED",
+	5: "STATE_INACTIVE",
 }
 
-var xxx_messageInfo_Error proto.InternalMessageInfo
+var VoucherState_value = map[string]int32{
+	"STATE_ANY":       0,
+	"STATE_ACTIVE":    1,
+	"STATE_UPCOMING":  2,
+	"STATE_EXPIRED":   3,
+	"STATE_EXHAUSTED": 4,
+	"STATE_INACTIVE":  5,
+}
 
-func (m *Error) GetCode() int32 {
-	if m != nil {
-		return m.Code
-	}
-	return 0
+func (x VoucherState) String() string {
+	return proto.EnumName(VoucherState_name, int32(x))
 }
 
-func (m *Error) GetMessage() string {
-	if m != nil {
-		return m.Message
-	}
-	return ""
+func (VoucherState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{2}
+}
+
+type SortField int32
+
+const (
+	SortField_SORT_ID       SortField = 0
+	SortField_SORT_CODE     SortField = 1
+	SortField_SORT_START    SortField = 2
+	SortField_SORT_END      SortField = 3
+	SortField_SORT_DISCOUNT SortField = 4
+)
+
+var SortField_name = map[int32]string{
+	0: "SORT_ID",
+	1: "SORT_CODE",
+	2: "SORT_START",
+	3: "SORT_END",
+	4: "SORT_DISCOUNT",
+}
+
+var SortField_value = map[string]int32{
+	"SORT_ID":       0,
+	"SORT_CODE":     1,
+	"SORT_START":    2,
+	"SORT_END":      3,
+	"SORT_DISCOUNT": 4,
+}
+
+func (x SortField) String() string {
+	return proto.EnumName(SortField_name, int32(x))
+}
+
+func (SortField) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{3}
+}
+
+type ReservationStatus int32
+
+const (
+	ReservationStatus_RESERVED            ReservationStatus = 0
+	ReservationStatus_CONFIRMED           ReservationStatus = 1
+	ReservationStatus_RELEASED            ReservationStatus = 2
+	ReservationStatus_RESERVATION_EXPIRED ReservationStatus = 3
+)
+
+var ReservationStatus_name = map[int32]string{
+	0: "RESERVED",
+	1: "CONFIRMED",
+	2: "RELEASED",
+	3: "RESERVATION_EXPIRED",
+}
+
+var ReservationStatus_value = map[string]int32{
+	"RESERVED":            0,
+	"CONFIRMED":           1,
+	"RELEASED":            2,
+	"RESERVATION_EXPIRED": 3,
+}
+
+func (x ReservationStatus) String() string {
+	return proto.EnumName(ReservationStatus_name, int32(x))
+}
+
+func (ReservationStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{4}
 }
 
 type Voucher struct {
@@ -78,6 +203,11 @@ type Voucher struct {
 	Discount             float32              `protobuf:"fixed32,3,opt,name=discount,proto3" json:"discount,omitempty"`
 	Start                *timestamp.Timestamp `protobuf:"bytes,4,opt,name=start,proto3" json:"start,omitempty"`
 	End                  *timestamp.Timestamp `protobuf:"bytes,5,opt,name=end,proto3" json:"end,omitempty"`
+	Quota                int32                `protobuf:"varint,6,opt,name=quota,proto3" json:"quota,omitempty"`
+	MaxPerCustomer       int32                `protobuf:"varint,7,opt,name=max_per_customer,json=maxPerCustomer,proto3" json:"max_per_customer,omitempty"`
+	Used                 int32                `protobuf:"varint,8,opt,name=used,proto3" json:"used,omitempty"`
+	Rule                 *Rule                `protobuf:"bytes,9,opt,name=rule,proto3" json:"rule,omitempty"`
+	Disabled             bool                 `protobuf:"varint,10,opt,name=disabled,proto3" json:"disabled,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -87,7 +217,7 @@ func (m *Voucher) Reset()         { *m = Voucher{} }
 func (m *Voucher) String() string { return proto.CompactTextString(m) }
 func (*Voucher) ProtoMessage()    {}
 func (*Voucher) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f00a0a2a5aeccc, []int{1}
+	return fileDescriptor_60f00a0a2a5aeccc, []int{0}
 }
 
 func (m *Voucher) XXX_Unmarshal(b []byte) error {
@@ -143,12 +273,50 @@ func (m *Voucher) GetEnd() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *Voucher) GetQuota() int32 {
+	if m != nil {
+		return m.Quota
+	}
+	return 0
+}
+
+func (m *Voucher) GetMaxPerCustomer() int32 {
+	if m != nil {
+		return m.MaxPerCustomer
+	}
+	return 0
+}
+
+func (m *Voucher) GetUsed() int32 {
+	if m != nil {
+		return m.Used
+	}
+	return 0
+}
+
+func (m *Voucher) GetRule() *Rule {
+	if m != nil {
+		return m.Rule
+	}
+	return nil
+}
+
+func (m *Voucher) GetDisabled() bool {
+	if m != nil {
+		return m.Disabled
+	}
+	return false
+}
+
 // Neu da gan cho no mot gia tri la so = x, no never dc thay doi
 type VoucherReq struct {
 	Code                 string               `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
 	Discount             float32              `protobuf:"fixed32,2,opt,name=discount,proto3" json:"discount,omitempty"`
 	Start                *timestamp.Timestamp `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
 	End                  *timestamp.Timestamp `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	Quota                int32                `protobuf:"varint,5,opt,name=quota,proto3" json:"quota,omitempty"`
+	MaxPerCustomer       int32                `protobuf:"varint,6,opt,name=max_per_customer,json=maxPerCustomer,proto3" json:"max_per_customer,omitempty"`
+	Rule                 *Rule                `protobuf:"bytes,7,opt,name=rule,proto3" json:"rule,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -158,7 +326,7 @@ func (m *VoucherReq) Reset()         { *m = VoucherReq{} }
 func (m *VoucherReq) String() string { return proto.CompactTextString(m) }
 func (*VoucherReq) ProtoMessage()    {}
 func (*VoucherReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f00a0a2a5aeccc, []int{2}
+	return fileDescriptor_60f00a0a2a5aeccc, []int{1}
 }
 
 func (m *VoucherReq) XXX_Unmarshal(b []byte) error {
@@ -207,8 +375,29 @@ func (m *VoucherReq) GetEnd() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *VoucherReq) GetQuota() int32 {
+	if m != nil {
+		return m.Quota
+	}
+	return 0
+}
+
+func (m *VoucherReq) GetMaxPerCustomer() int32 {
+	if m != nil {
+		return m.MaxPerCustomer
+	}
+	return 0
+}
+
+func (m *VoucherReq) GetRule() *Rule {
+	if m != nil {
+		return m.Rule
+	}
+	return nil
+}
+
+// Loi duoc tra ve qua gRPC status (InvalidArgument, AlreadyExists, Internal)
 type VoucherRes struct {
-	Error                *Error   `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
 	Data                 *Voucher `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -219,7 +408,7 @@ func (m *VoucherRes) Reset()         { *m = VoucherRes{} }
 func (m *VoucherRes) String() string { return proto.CompactTextString(m) }
 func (*VoucherRes) ProtoMessage()    {}
 func (*VoucherRes) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f00a0a2a5aeccc, []int{3}
+	return fileDescriptor_60f00a0a2a5aeccc, []int{2}
 }
 
 func (m *VoucherRes) XXX_Unmarshal(b []byte) error {
@@ -240,128 +429,2401 @@ func (m *VoucherRes) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_VoucherRes proto.InternalMessageInfo
 
-func (m *VoucherRes) GetError() *Error {
+func (m *VoucherRes) GetData() *Voucher {
 	if m != nil {
-		return m.Error
+		return m.Data
 	}
 	return nil
 }
 
-func (m *VoucherRes) GetData() *Voucher {
+// Dieu kien de ap dung discount, PERCENT thi discount trong (0, 1]
+type Rule struct {
+	Type                 DiscountType `protobuf:"varint,1,opt,name=type,proto3,enum=proto.DiscountType" json:"type,omitempty"`
+	DiscountCap          float32      `protobuf:"fixed32,2,opt,name=discount_cap,json=discountCap,proto3" json:"discount_cap,omitempty"`
+	MinOrder             float32      `protobuf:"fixed32,3,opt,name=min_order,json=minOrder,proto3" json:"min_order,omitempty"`
+	Categories           []string     `protobuf:"bytes,4,rep,name=categories,proto3" json:"categories,omitempty"`
+	Stackable            bool         `protobuf:"varint,5,opt,name=stackable,proto3" json:"stackable,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Rule) Reset()         { *m = Rule{} }
+func (m *Rule) String() string { return proto.CompactTextString(m) }
+func (*Rule) ProtoMessage()    {}
+func (*Rule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{3}
+}
+
+func (m *Rule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Rule.Unmarshal(m, b)
+}
+func (m *Rule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Rule.Marshal(b, m, deterministic)
+}
+func (m *Rule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Rule.Merge(m, src)
+}
+func (m *Rule) XXX_Size() int {
+	return xxx_messageInfo_Rule.Size(m)
+}
+func (m *Rule) XXX_DiscardUnknown() {
+	xxx_messageInfo_Rule.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Rule proto.InternalMessageInfo
+
+func (m *Rule) GetType() DiscountType {
 	if m != nil {
-		return m.Data
+		return m.Type
+	}
+	return DiscountType_PERCENT
+}
+
+func (m *Rule) GetDiscountCap() float32 {
+	if m != nil {
+		return m.DiscountCap
+	}
+	return 0
+}
+
+func (m *Rule) GetMinOrder() float32 {
+	if m != nil {
+		return m.MinOrder
+	}
+	return 0
+}
+
+func (m *Rule) GetCategories() []string {
+	if m != nil {
+		return m.Categories
 	}
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Error)(nil), "proto.Error")
-	proto.RegisterType((*Voucher)(nil), "proto.Voucher")
-	proto.RegisterType((*VoucherReq)(nil), "proto.VoucherReq")
-	proto.RegisterType((*VoucherRes)(nil), "proto.VoucherRes")
+func (m *Rule) GetStackable() bool {
+	if m != nil {
+		return m.Stackable
+	}
+	return false
 }
 
-func init() { proto.RegisterFile("proto/voucher.proto", fileDescriptor_60f00a0a2a5aeccc) }
+type OrderItem struct {
+	Category             string   `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	Amount               float32  `protobuf:"fixed32,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-var fileDescriptor_60f00a0a2a5aeccc = []byte{
-	// 298 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x91, 0x4d, 0x4e, 0xc3, 0x30,
-	0x10, 0x85, 0x71, 0x1a, 0xd3, 0x32, 0x45, 0x95, 0x30, 0x1b, 0x2b, 0x1b, 0x22, 0xaf, 0xb2, 0x40,
-	0x29, 0x0a, 0xe2, 0x06, 0x70, 0x01, 0x53, 0xb1, 0x4f, 0xe3, 0x21, 0x58, 0xa2, 0x75, 0x6b, 0x3b,
-	0x3d, 0x0d, 0x17, 0xe0, 0x96, 0xa8, 0x76, 0x52, 0xf1, 0x2b, 0x60, 0x65, 0xbf, 0x99, 0xf7, 0xc6,
-	0x9f, 0x35, 0x70, 0xbe, 0xb1, 0xc6, 0x9b, 0xf9, 0xce, 0x74, 0xcd, 0x13, 0xda, 0x32, 0x28, 0x46,
-	0xc3, 0x91, 0x5d, 0xb4, 0xc6, 0xb4, 0xcf, 0x38, 0x0f, 0x6a, 0xd9, 0x3d, 0xce, 0xbd, 0x5e, 0xa1,
-	0xf3, 0xf5, 0x6a, 0x13, 0x7d, 0xe2, 0x06, 0xe8, 0x9d, 0xb5, 0xc6, 0x32, 0x06, 0x69, 0x63, 0x14,
-	0x72, 0x92, 0x93, 0x82, 0xca, 0x70, 0x67, 0x1c, 0xc6, 0x2b, 0x74, 0xae, 0x6e, 0x91, 0x27, 0x39,
-	0x29, 0x4e, 0xe4, 0x20, 0xc5, 0x2b, 0x81, 0xf1, 0x43, 0x7c, 0x90, 0xcd, 0x20, 0xd1, 0xaa, 0xcf,
-	0x25, 0x5a, 0x1d, 0x26, 0xc5, 0x48, 0x9c, 0x94, 0xc1, 0x44, 0x69, 0xd7, 0x98, 0x6e, 0xed, 0xf9,
-	0x28, 0x27, 0x45, 0x22, 0x0f, 0x9a, 0x5d, 0x01, 0x75, 0xbe, 0xb6, 0x9e, 0xa7, 0x39, 0x29, 0xa6,
-	0x55, 0x56, 0x46, 0xe6, 0x72, 0x60, 0x2e, 0x17, 0x03, 0xb3, 0x8c, 0x46, 0x76, 0x09, 0x23, 0x5c,
-	0x2b, 0x4e, 0x7f, 0xf5, 0xef, 0x6d, 0xe2, 0x85, 0x00, 0xf4, 0xac, 0x12, 0xb7, 0x1f, 0x3e, 0xfa,
-	0x1d, 0x5e, 0xf2, 0x13, 0xde, 0xe8, 0x9f, 0x78, 0xe9, 0xdf, 0xf0, 0x16, 0xef, 0xe8, 0x1c, 0x13,
-	0x40, 0x71, 0xbf, 0x8f, 0x80, 0x37, 0xad, 0x4e, 0x63, 0xac, 0x0c, 0x3b, 0x92, 0xb1, 0xc5, 0x04,
-	0xa4, 0xaa, 0xf6, 0x75, 0x20, 0x9d, 0x56, 0xb3, 0xde, 0x32, 0x0c, 0x09, 0xbd, 0xea, 0x16, 0x66,
-	0x7d, 0xe1, 0x1e, 0xed, 0x4e, 0x37, 0xc8, 0x2a, 0x98, 0x48, 0x6c, 0xb5, 0xf3, 0x68, 0xd9, 0xd9,
-	0xa7, 0x0c, 0x6e, 0xb3, 0x2f, 0x25, 0x27, 0x8e, 0x96, 0xc7, 0xa1, 0x76, 0xfd, 0x16, 0x00, 0x00,
-	0xff, 0xff, 0xa3, 0x17, 0xb1, 0x26, 0x63, 0x02, 0x00, 0x00,
+func (m *OrderItem) Reset()         { *m = OrderItem{} }
+func (m *OrderItem) String() string { return proto.CompactTextString(m) }
+func (*OrderItem) ProtoMessage()    {}
+func (*OrderItem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{4}
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *OrderItem) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OrderItem.Unmarshal(m, b)
+}
+func (m *OrderItem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OrderItem.Marshal(b, m, deterministic)
+}
+func (m *OrderItem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OrderItem.Merge(m, src)
+}
+func (m *OrderItem) XXX_Size() int {
+	return xxx_messageInfo_OrderItem.Size(m)
+}
+func (m *OrderItem) XXX_DiscardUnknown() {
+	xxx_messageInfo_OrderItem.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_OrderItem proto.InternalMessageInfo
 
-// VoucherServiceClient is the client API for VoucherService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type VoucherServiceClient interface {
-	Register(ctx context.Context, in *VoucherReq, opts ...grpc.CallOption) (*VoucherRes, error)
+func (m *OrderItem) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
 }
 
-type voucherServiceClient struct {
-	cc *grpc.ClientConn
+func (m *OrderItem) GetAmount() float32 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
 }
 
-func NewVoucherServiceClient(cc *grpc.ClientConn) VoucherServiceClient {
-	return &voucherServiceClient{cc}
+type Reason struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (c *voucherServiceClient) Register(ctx context.Context, in *VoucherReq, opts ...grpc.CallOption) (*VoucherRes, error) {
-	out := new(VoucherRes)
-	err := c.cc.Invoke(ctx, "/proto.VoucherService/Register", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *Reason) Reset()         { *m = Reason{} }
+func (m *Reason) String() string { return proto.CompactTextString(m) }
+func (*Reason) ProtoMessage()    {}
+func (*Reason) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{5}
+}
+
+func (m *Reason) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Reason.Unmarshal(m, b)
+}
+func (m *Reason) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Reason.Marshal(b, m, deterministic)
+}
+func (m *Reason) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Reason.Merge(m, src)
+}
+func (m *Reason) XXX_Size() int {
+	return xxx_messageInfo_Reason.Size(m)
+}
+func (m *Reason) XXX_DiscardUnknown() {
+	xxx_messageInfo_Reason.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Reason proto.InternalMessageInfo
+
+func (m *Reason) GetCode() string {
+	if m != nil {
+		return m.Code
 	}
-	return out, nil
+	return ""
 }
 
-// VoucherServiceServer is the server API for VoucherService service.
-type VoucherServiceServer interface {
-	Register(context.Context, *VoucherReq) (*VoucherRes, error)
+func (m *Reason) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
 }
 
-// UnimplementedVoucherServiceServer can be embedded to have forward compatible implementations.
-type UnimplementedVoucherServiceServer struct {
+type VerifyReq struct {
+	Code                 string       `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Amount               float32      `protobuf:"fixed32,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Customer             string       `protobuf:"bytes,3,opt,name=customer,proto3" json:"customer,omitempty"`
+	Items                []*OrderItem `protobuf:"bytes,4,rep,name=items,proto3" json:"items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
-func (*UnimplementedVoucherServiceServer) Register(ctx context.Context, req *VoucherReq) (*VoucherRes, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+func (m *VerifyReq) Reset()         { *m = VerifyReq{} }
+func (m *VerifyReq) String() string { return proto.CompactTextString(m) }
+func (*VerifyReq) ProtoMessage()    {}
+func (*VerifyReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{6}
 }
 
-func RegisterVoucherServiceServer(s *grpc.Server, srv VoucherServiceServer) {
-	s.RegisterService(&_VoucherService_serviceDesc, srv)
+func (m *VerifyReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyReq.Unmarshal(m, b)
+}
+func (m *VerifyReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyReq.Marshal(b, m, deterministic)
+}
+func (m *VerifyReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyReq.Merge(m, src)
+}
+func (m *VerifyReq) XXX_Size() int {
+	return xxx_messageInfo_VerifyReq.Size(m)
+}
+func (m *VerifyReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyReq.DiscardUnknown(m)
 }
 
-func _VoucherService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VoucherReq)
-	if err := dec(in); err != nil {
-		return nil, err
+var xxx_messageInfo_VerifyReq proto.InternalMessageInfo
+
+func (m *VerifyReq) GetCode() string {
+	if m != nil {
+		return m.Code
 	}
-	if interceptor == nil {
-		return srv.(VoucherServiceServer).Register(ctx, in)
+	return ""
+}
+
+func (m *VerifyReq) GetAmount() float32 {
+	if m != nil {
+		return m.Amount
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/proto.VoucherService/Register",
+	return 0
+}
+
+func (m *VerifyReq) GetCustomer() string {
+	if m != nil {
+		return m.Customer
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(VoucherServiceServer).Register(ctx, req.(*VoucherReq))
+	return ""
+}
+
+func (m *VerifyReq) GetItems() []*OrderItem {
+	if m != nil {
+		return m.Items
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _VoucherService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "proto.VoucherService",
-	HandlerType: (*VoucherServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Register",
-			Handler:    _VoucherService_Register_Handler,
+type VerifyRes struct {
+	Status               VerifyStatus `protobuf:"varint,1,opt,name=status,proto3,enum=proto.VerifyStatus" json:"status,omitempty"`
+	Data                 *Voucher     `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Amount               float32      `protobuf:"fixed32,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Discount             float32      `protobuf:"fixed32,4,opt,name=discount,proto3" json:"discount,omitempty"`
+	Total                float32      `protobuf:"fixed32,5,opt,name=total,proto3" json:"total,omitempty"`
+	Reasons              []*Reason    `protobuf:"bytes,6,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *VerifyRes) Reset()         { *m = VerifyRes{} }
+func (m *VerifyRes) String() string { return proto.CompactTextString(m) }
+func (*VerifyRes) ProtoMessage()    {}
+func (*VerifyRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{7}
+}
+
+func (m *VerifyRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyRes.Unmarshal(m, b)
+}
+func (m *VerifyRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyRes.Marshal(b, m, deterministic)
+}
+func (m *VerifyRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyRes.Merge(m, src)
+}
+func (m *VerifyRes) XXX_Size() int {
+	return xxx_messageInfo_VerifyRes.Size(m)
+}
+func (m *VerifyRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyRes proto.InternalMessageInfo
+
+func (m *VerifyRes) GetStatus() VerifyStatus {
+	if m != nil {
+		return m.Status
+	}
+	return VerifyStatus_OK
+}
+
+func (m *VerifyRes) GetData() *Voucher {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *VerifyRes) GetAmount() float32 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *VerifyRes) GetDiscount() float32 {
+	if m != nil {
+		return m.Discount
+	}
+	return 0
+}
+
+func (m *VerifyRes) GetTotal() float32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *VerifyRes) GetReasons() []*Reason {
+	if m != nil {
+		return m.Reasons
+	}
+	return nil
+}
+
+type GenerateReq struct {
+	Prefix               string               `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Count                int32                `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Length               int32                `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	Alphabet             string               `protobuf:"bytes,4,opt,name=alphabet,proto3" json:"alphabet,omitempty"`
+	Discount             float32              `protobuf:"fixed32,5,opt,name=discount,proto3" json:"discount,omitempty"`
+	Start                *timestamp.Timestamp `protobuf:"bytes,6,opt,name=start,proto3" json:"start,omitempty"`
+	End                  *timestamp.Timestamp `protobuf:"bytes,7,opt,name=end,proto3" json:"end,omitempty"`
+	Quota                int32                `protobuf:"varint,8,opt,name=quota,proto3" json:"quota,omitempty"`
+	MaxPerCustomer       int32                `protobuf:"varint,9,opt,name=max_per_customer,json=maxPerCustomer,proto3" json:"max_per_customer,omitempty"`
+	Rule                 *Rule                `protobuf:"bytes,10,opt,name=rule,proto3" json:"rule,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *GenerateReq) Reset()         { *m = GenerateReq{} }
+func (m *GenerateReq) String() string { return proto.CompactTextString(m) }
+func (*GenerateReq) ProtoMessage()    {}
+func (*GenerateReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{8}
+}
+
+func (m *GenerateReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GenerateReq.Unmarshal(m, b)
+}
+func (m *GenerateReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GenerateReq.Marshal(b, m, deterministic)
+}
+func (m *GenerateReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GenerateReq.Merge(m, src)
+}
+func (m *GenerateReq) XXX_Size() int {
+	return xxx_messageInfo_GenerateReq.Size(m)
+}
+func (m *GenerateReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_GenerateReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GenerateReq proto.InternalMessageInfo
+
+func (m *GenerateReq) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *GenerateReq) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *GenerateReq) GetLength() int32 {
+	if m != nil {
+		return m.Length
+	}
+	return 0
+}
+
+func (m *GenerateReq) GetAlphabet() string {
+	if m != nil {
+		return m.Alphabet
+	}
+	return ""
+}
+
+func (m *GenerateReq) GetDiscount() float32 {
+	if m != nil {
+		return m.Discount
+	}
+	return 0
+}
+
+func (m *GenerateReq) GetStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *GenerateReq) GetEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+func (m *GenerateReq) GetQuota() int32 {
+	if m != nil {
+		return m.Quota
+	}
+	return 0
+}
+
+func (m *GenerateReq) GetMaxPerCustomer() int32 {
+	if m != nil {
+		return m.MaxPerCustomer
+	}
+	return 0
+}
+
+func (m *GenerateReq) GetRule() *Rule {
+	if m != nil {
+		return m.Rule
+	}
+	return nil
+}
+
+type GenerateRes struct {
+	Codes                []string `protobuf:"bytes,1,rep,name=codes,proto3" json:"codes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GenerateRes) Reset()         { *m = GenerateRes{} }
+func (m *GenerateRes) String() string { return proto.CompactTextString(m) }
+func (*GenerateRes) ProtoMessage()    {}
+func (*GenerateRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{9}
+}
+
+func (m *GenerateRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GenerateRes.Unmarshal(m, b)
+}
+func (m *GenerateRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GenerateRes.Marshal(b, m, deterministic)
+}
+func (m *GenerateRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GenerateRes.Merge(m, src)
+}
+func (m *GenerateRes) XXX_Size() int {
+	return xxx_messageInfo_GenerateRes.Size(m)
+}
+func (m *GenerateRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_GenerateRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GenerateRes proto.InternalMessageInfo
+
+func (m *GenerateRes) GetCodes() []string {
+	if m != nil {
+		return m.Codes
+	}
+	return nil
+}
+
+type ListReq struct {
+	CodePrefix  string               `protobuf:"bytes,1,opt,name=code_prefix,json=codePrefix,proto3" json:"code_prefix,omitempty"`
+	ActiveAt    *timestamp.Timestamp `protobuf:"bytes,2,opt,name=active_at,json=activeAt,proto3" json:"active_at,omitempty"`
+	MinDiscount float32              `protobuf:"fixed32,3,opt,name=min_discount,json=minDiscount,proto3" json:"min_discount,omitempty"`
+	MaxDiscount float32              `protobuf:"fixed32,4,opt,name=max_discount,json=maxDiscount,proto3" json:"max_discount,omitempty"`
+	Status      VoucherState         `protobuf:"varint,5,opt,name=status,proto3,enum=proto.VoucherState" json:"status,omitempty"`
+	Sort        SortField            `protobuf:"varint,6,opt,name=sort,proto3,enum=proto.SortField" json:"sort,omitempty"`
+	Desc        bool                 `protobuf:"varint,7,opt,name=desc,proto3" json:"desc,omitempty"`
+	Limit       int32                `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Lay tu next_cursor cua trang truoc
+	Cursor               string   `protobuf:"bytes,9,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListReq) Reset()         { *m = ListReq{} }
+func (m *ListReq) String() string { return proto.CompactTextString(m) }
+func (*ListReq) ProtoMessage()    {}
+func (*ListReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{10}
+}
+
+func (m *ListReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReq.Unmarshal(m, b)
+}
+func (m *ListReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReq.Marshal(b, m, deterministic)
+}
+func (m *ListReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReq.Merge(m, src)
+}
+func (m *ListReq) XXX_Size() int {
+	return xxx_messageInfo_ListReq.Size(m)
+}
+func (m *ListReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReq proto.InternalMessageInfo
+
+func (m *ListReq) GetCodePrefix() string {
+	if m != nil {
+		return m.CodePrefix
+	}
+	return ""
+}
+
+func (m *ListReq) GetActiveAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ActiveAt
+	}
+	return nil
+}
+
+func (m *ListReq) GetMinDiscount() float32 {
+	if m != nil {
+		return m.MinDiscount
+	}
+	return 0
+}
+
+func (m *ListReq) GetMaxDiscount() float32 {
+	if m != nil {
+		return m.MaxDiscount
+	}
+	return 0
+}
+
+func (m *ListReq) GetStatus() VoucherState {
+	if m != nil {
+		return m.Status
+	}
+	return VoucherState_STATE_ANY
+}
+
+func (m *ListReq) GetSort() SortField {
+	if m != nil {
+		return m.Sort
+	}
+	return SortField_SORT_ID
+}
+
+func (m *ListReq) GetDesc() bool {
+	if m != nil {
+		return m.Desc
+	}
+	return false
+}
+
+func (m *ListReq) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListReq) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+type ListRes struct {
+	Data                 []*Voucher `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	NextCursor           string     `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ListRes) Reset()         { *m = ListRes{} }
+func (m *ListRes) String() string { return proto.CompactTextString(m) }
+func (*ListRes) ProtoMessage()    {}
+func (*ListRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{11}
+}
+
+func (m *ListRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRes.Unmarshal(m, b)
+}
+func (m *ListRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRes.Marshal(b, m, deterministic)
+}
+func (m *ListRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRes.Merge(m, src)
+}
+func (m *ListRes) XXX_Size() int {
+	return xxx_messageInfo_ListRes.Size(m)
+}
+func (m *ListRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRes proto.InternalMessageInfo
+
+func (m *ListRes) GetData() []*Voucher {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *ListRes) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
+type Reservation struct {
+	Id                   int32                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	VoucherId            int32                `protobuf:"varint,2,opt,name=voucher_id,json=voucherId,proto3" json:"voucher_id,omitempty"`
+	Customer             string               `protobuf:"bytes,3,opt,name=customer,proto3" json:"customer,omitempty"`
+	Amount               float32              `protobuf:"fixed32,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Discount             float32              `protobuf:"fixed32,5,opt,name=discount,proto3" json:"discount,omitempty"`
+	Status               ReservationStatus    `protobuf:"varint,6,opt,name=status,proto3,enum=proto.ReservationStatus" json:"status,omitempty"`
+	ExpiresAt            *timestamp.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Reservation) Reset()         { *m = Reservation{} }
+func (m *Reservation) String() string { return proto.CompactTextString(m) }
+func (*Reservation) ProtoMessage()    {}
+func (*Reservation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{12}
+}
+
+func (m *Reservation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Reservation.Unmarshal(m, b)
+}
+func (m *Reservation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Reservation.Marshal(b, m, deterministic)
+}
+func (m *Reservation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Reservation.Merge(m, src)
+}
+func (m *Reservation) XXX_Size() int {
+	return xxx_messageInfo_Reservation.Size(m)
+}
+func (m *Reservation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Reservation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Reservation proto.InternalMessageInfo
+
+func (m *Reservation) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Reservation) GetVoucherId() int32 {
+	if m != nil {
+		return m.VoucherId
+	}
+	return 0
+}
+
+func (m *Reservation) GetCustomer() string {
+	if m != nil {
+		return m.Customer
+	}
+	return ""
+}
+
+func (m *Reservation) GetAmount() float32 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *Reservation) GetDiscount() float32 {
+	if m != nil {
+		return m.Discount
+	}
+	return 0
+}
+
+func (m *Reservation) GetStatus() ReservationStatus {
+	if m != nil {
+		return m.Status
+	}
+	return ReservationStatus_RESERVED
+}
+
+func (m *Reservation) GetExpiresAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return nil
+}
+
+func (m *Reservation) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+// reservation chi co khi verify.status = OK
+type ReserveRes struct {
+	Verify               *VerifyRes   `protobuf:"bytes,1,opt,name=verify,proto3" json:"verify,omitempty"`
+	Reservation          *Reservation `protobuf:"bytes,2,opt,name=reservation,proto3" json:"reservation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ReserveRes) Reset()         { *m = ReserveRes{} }
+func (m *ReserveRes) String() string { return proto.CompactTextString(m) }
+func (*ReserveRes) ProtoMessage()    {}
+func (*ReserveRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{13}
+}
+
+func (m *ReserveRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReserveRes.Unmarshal(m, b)
+}
+func (m *ReserveRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReserveRes.Marshal(b, m, deterministic)
+}
+func (m *ReserveRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReserveRes.Merge(m, src)
+}
+func (m *ReserveRes) XXX_Size() int {
+	return xxx_messageInfo_ReserveRes.Size(m)
+}
+func (m *ReserveRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReserveRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReserveRes proto.InternalMessageInfo
+
+func (m *ReserveRes) GetVerify() *VerifyRes {
+	if m != nil {
+		return m.Verify
+	}
+	return nil
+}
+
+func (m *ReserveRes) GetReservation() *Reservation {
+	if m != nil {
+		return m.Reservation
+	}
+	return nil
+}
+
+type ReservationReq struct {
+	Id                   int32    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReservationReq) Reset()         { *m = ReservationReq{} }
+func (m *ReservationReq) String() string { return proto.CompactTextString(m) }
+func (*ReservationReq) ProtoMessage()    {}
+func (*ReservationReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{14}
+}
+
+func (m *ReservationReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReservationReq.Unmarshal(m, b)
+}
+func (m *ReservationReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReservationReq.Marshal(b, m, deterministic)
+}
+func (m *ReservationReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReservationReq.Merge(m, src)
+}
+func (m *ReservationReq) XXX_Size() int {
+	return xxx_messageInfo_ReservationReq.Size(m)
+}
+func (m *ReservationReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReservationReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReservationReq proto.InternalMessageInfo
+
+func (m *ReservationReq) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type Evaluation struct {
+	Code                 string    `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Applicable           bool      `protobuf:"varint,2,opt,name=applicable,proto3" json:"applicable,omitempty"`
+	Eligible             float32   `protobuf:"fixed32,3,opt,name=eligible,proto3" json:"eligible,omitempty"`
+	Discount             float32   `protobuf:"fixed32,4,opt,name=discount,proto3" json:"discount,omitempty"`
+	Reasons              []*Reason `protobuf:"bytes,5,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Evaluation) Reset()         { *m = Evaluation{} }
+func (m *Evaluation) String() string { return proto.CompactTextString(m) }
+func (*Evaluation) ProtoMessage()    {}
+func (*Evaluation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{15}
+}
+
+func (m *Evaluation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Evaluation.Unmarshal(m, b)
+}
+func (m *Evaluation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Evaluation.Marshal(b, m, deterministic)
+}
+func (m *Evaluation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Evaluation.Merge(m, src)
+}
+func (m *Evaluation) XXX_Size() int {
+	return xxx_messageInfo_Evaluation.Size(m)
+}
+func (m *Evaluation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Evaluation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Evaluation proto.InternalMessageInfo
+
+func (m *Evaluation) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *Evaluation) GetApplicable() bool {
+	if m != nil {
+		return m.Applicable
+	}
+	return false
+}
+
+func (m *Evaluation) GetEligible() float32 {
+	if m != nil {
+		return m.Eligible
+	}
+	return 0
+}
+
+func (m *Evaluation) GetDiscount() float32 {
+	if m != nil {
+		return m.Discount
+	}
+	return 0
+}
+
+func (m *Evaluation) GetReasons() []*Reason {
+	if m != nil {
+		return m.Reasons
+	}
+	return nil
+}
+
+// Voucher duoc ap dung lan luot theo thu tu cua codes
+type EvaluateReq struct {
+	Codes                []string     `protobuf:"bytes,1,rep,name=codes,proto3" json:"codes,omitempty"`
+	Amount               float32      `protobuf:"fixed32,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Items                []*OrderItem `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *EvaluateReq) Reset()         { *m = EvaluateReq{} }
+func (m *EvaluateReq) String() string { return proto.CompactTextString(m) }
+func (*EvaluateReq) ProtoMessage()    {}
+func (*EvaluateReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{16}
+}
+
+func (m *EvaluateReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EvaluateReq.Unmarshal(m, b)
+}
+func (m *EvaluateReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EvaluateReq.Marshal(b, m, deterministic)
+}
+func (m *EvaluateReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EvaluateReq.Merge(m, src)
+}
+func (m *EvaluateReq) XXX_Size() int {
+	return xxx_messageInfo_EvaluateReq.Size(m)
+}
+func (m *EvaluateReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_EvaluateReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EvaluateReq proto.InternalMessageInfo
+
+func (m *EvaluateReq) GetCodes() []string {
+	if m != nil {
+		return m.Codes
+	}
+	return nil
+}
+
+func (m *EvaluateReq) GetAmount() float32 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *EvaluateReq) GetItems() []*OrderItem {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type EvaluateRes struct {
+	Evaluations          []*Evaluation `protobuf:"bytes,1,rep,name=evaluations,proto3" json:"evaluations,omitempty"`
+	Amount               float32       `protobuf:"fixed32,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Discount             float32       `protobuf:"fixed32,3,opt,name=discount,proto3" json:"discount,omitempty"`
+	Total                float32       `protobuf:"fixed32,4,opt,name=total,proto3" json:"total,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *EvaluateRes) Reset()         { *m = EvaluateRes{} }
+func (m *EvaluateRes) String() string { return proto.CompactTextString(m) }
+func (*EvaluateRes) ProtoMessage()    {}
+func (*EvaluateRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{17}
+}
+
+func (m *EvaluateRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EvaluateRes.Unmarshal(m, b)
+}
+func (m *EvaluateRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EvaluateRes.Marshal(b, m, deterministic)
+}
+func (m *EvaluateRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EvaluateRes.Merge(m, src)
+}
+func (m *EvaluateRes) XXX_Size() int {
+	return xxx_messageInfo_EvaluateRes.Size(m)
+}
+func (m *EvaluateRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_EvaluateRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EvaluateRes proto.InternalMessageInfo
+
+func (m *EvaluateRes) GetEvaluations() []*Evaluation {
+	if m != nil {
+		return m.Evaluations
+	}
+	return nil
+}
+
+func (m *EvaluateRes) GetAmount() float32 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *EvaluateRes) GetDiscount() float32 {
+	if m != nil {
+		return m.Discount
+	}
+	return 0
+}
+
+func (m *EvaluateRes) GetTotal() float32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+type ChangeReq struct {
+	Id    int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Actor string `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Chi dung cho Extend
+	End                  *timestamp.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ChangeReq) Reset()         { *m = ChangeReq{} }
+func (m *ChangeReq) String() string { return proto.CompactTextString(m) }
+func (*ChangeReq) ProtoMessage()    {}
+func (*ChangeReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{18}
+}
+
+func (m *ChangeReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChangeReq.Unmarshal(m, b)
+}
+func (m *ChangeReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChangeReq.Marshal(b, m, deterministic)
+}
+func (m *ChangeReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChangeReq.Merge(m, src)
+}
+func (m *ChangeReq) XXX_Size() int {
+	return xxx_messageInfo_ChangeReq.Size(m)
+}
+func (m *ChangeReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChangeReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChangeReq proto.InternalMessageInfo
+
+func (m *ChangeReq) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *ChangeReq) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *ChangeReq) GetEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+// before va after la JSON cua voucher truoc va sau khi thay doi
+type Audit struct {
+	Id                   int32                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	VoucherId            int32                `protobuf:"varint,2,opt,name=voucher_id,json=voucherId,proto3" json:"voucher_id,omitempty"`
+	Action               string               `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Actor                string               `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	Before               string               `protobuf:"bytes,5,opt,name=before,proto3" json:"before,omitempty"`
+	After                string               `protobuf:"bytes,6,opt,name=after,proto3" json:"after,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Audit) Reset()         { *m = Audit{} }
+func (m *Audit) String() string { return proto.CompactTextString(m) }
+func (*Audit) ProtoMessage()    {}
+func (*Audit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{19}
+}
+
+func (m *Audit) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Audit.Unmarshal(m, b)
+}
+func (m *Audit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Audit.Marshal(b, m, deterministic)
+}
+func (m *Audit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Audit.Merge(m, src)
+}
+func (m *Audit) XXX_Size() int {
+	return xxx_messageInfo_Audit.Size(m)
+}
+func (m *Audit) XXX_DiscardUnknown() {
+	xxx_messageInfo_Audit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Audit proto.InternalMessageInfo
+
+func (m *Audit) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Audit) GetVoucherId() int32 {
+	if m != nil {
+		return m.VoucherId
+	}
+	return 0
+}
+
+func (m *Audit) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *Audit) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *Audit) GetBefore() string {
+	if m != nil {
+		return m.Before
+	}
+	return ""
+}
+
+func (m *Audit) GetAfter() string {
+	if m != nil {
+		return m.After
+	}
+	return ""
+}
+
+func (m *Audit) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+type AuditReq struct {
+	VoucherId            int32    `protobuf:"varint,1,opt,name=voucher_id,json=voucherId,proto3" json:"voucher_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditReq) Reset()         { *m = AuditReq{} }
+func (m *AuditReq) String() string { return proto.CompactTextString(m) }
+func (*AuditReq) ProtoMessage()    {}
+func (*AuditReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{20}
+}
+
+func (m *AuditReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditReq.Unmarshal(m, b)
+}
+func (m *AuditReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditReq.Marshal(b, m, deterministic)
+}
+func (m *AuditReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditReq.Merge(m, src)
+}
+func (m *AuditReq) XXX_Size() int {
+	return xxx_messageInfo_AuditReq.Size(m)
+}
+func (m *AuditReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditReq proto.InternalMessageInfo
+
+func (m *AuditReq) GetVoucherId() int32 {
+	if m != nil {
+		return m.VoucherId
+	}
+	return 0
+}
+
+type AuditRes struct {
+	Data                 []*Audit `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditRes) Reset()         { *m = AuditRes{} }
+func (m *AuditRes) String() string { return proto.CompactTextString(m) }
+func (*AuditRes) ProtoMessage()    {}
+func (*AuditRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{21}
+}
+
+func (m *AuditRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditRes.Unmarshal(m, b)
+}
+func (m *AuditRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditRes.Marshal(b, m, deterministic)
+}
+func (m *AuditRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditRes.Merge(m, src)
+}
+func (m *AuditRes) XXX_Size() int {
+	return xxx_messageInfo_AuditRes.Size(m)
+}
+func (m *AuditRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditRes proto.InternalMessageInfo
+
+func (m *AuditRes) GetData() []*Audit {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type ImportRow struct {
+	// So thu tu cua dong trong file, dung de bao loi
+	Row     int32       `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Voucher *VoucherReq `protobuf:"bytes,2,opt,name=voucher,proto3" json:"voucher,omitempty"`
+	// Loi client gap khi doc dong (vd parse CSV), server bao dong la failed
+	// voi cac loi nay ma khong insert voucher
+	Errors               []*FieldError `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ImportRow) Reset()         { *m = ImportRow{} }
+func (m *ImportRow) String() string { return proto.CompactTextString(m) }
+func (*ImportRow) ProtoMessage()    {}
+func (*ImportRow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{22}
+}
+
+func (m *ImportRow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportRow.Unmarshal(m, b)
+}
+func (m *ImportRow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportRow.Marshal(b, m, deterministic)
+}
+func (m *ImportRow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportRow.Merge(m, src)
+}
+func (m *ImportRow) XXX_Size() int {
+	return xxx_messageInfo_ImportRow.Size(m)
+}
+func (m *ImportRow) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportRow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportRow proto.InternalMessageInfo
+
+func (m *ImportRow) GetRow() int32 {
+	if m != nil {
+		return m.Row
+	}
+	return 0
+}
+
+func (m *ImportRow) GetVoucher() *VoucherReq {
+	if m != nil {
+		return m.Voucher
+	}
+	return nil
+}
+
+func (m *ImportRow) GetErrors() []*FieldError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+type FieldError struct {
+	Field                string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Description          string   `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldError) Reset()         { *m = FieldError{} }
+func (m *FieldError) String() string { return proto.CompactTextString(m) }
+func (*FieldError) ProtoMessage()    {}
+func (*FieldError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{23}
+}
+
+func (m *FieldError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldError.Unmarshal(m, b)
+}
+func (m *FieldError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldError.Marshal(b, m, deterministic)
+}
+func (m *FieldError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldError.Merge(m, src)
+}
+func (m *FieldError) XXX_Size() int {
+	return xxx_messageInfo_FieldError.Size(m)
+}
+func (m *FieldError) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldError proto.InternalMessageInfo
+
+func (m *FieldError) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *FieldError) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+type ImportResult struct {
+	Row int32 `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Ok  bool  `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
+	// Id cua voucher da insert khi ok
+	Id                   int32         `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	Errors               []*FieldError `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ImportResult) Reset()         { *m = ImportResult{} }
+func (m *ImportResult) String() string { return proto.CompactTextString(m) }
+func (*ImportResult) ProtoMessage()    {}
+func (*ImportResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{24}
+}
+
+func (m *ImportResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportResult.Unmarshal(m, b)
+}
+func (m *ImportResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportResult.Marshal(b, m, deterministic)
+}
+func (m *ImportResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportResult.Merge(m, src)
+}
+func (m *ImportResult) XXX_Size() int {
+	return xxx_messageInfo_ImportResult.Size(m)
+}
+func (m *ImportResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportResult proto.InternalMessageInfo
+
+func (m *ImportResult) GetRow() int32 {
+	if m != nil {
+		return m.Row
+	}
+	return 0
+}
+
+func (m *ImportResult) GetOk() bool {
+	if m != nil {
+		return m.Ok
+	}
+	return false
+}
+
+func (m *ImportResult) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *ImportResult) GetErrors() []*FieldError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+type ImportRes struct {
+	Imported             int32           `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`
+	Failed               int32           `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Results              []*ImportResult `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ImportRes) Reset()         { *m = ImportRes{} }
+func (m *ImportRes) String() string { return proto.CompactTextString(m) }
+func (*ImportRes) ProtoMessage()    {}
+func (*ImportRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{25}
+}
+
+func (m *ImportRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportRes.Unmarshal(m, b)
+}
+func (m *ImportRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportRes.Marshal(b, m, deterministic)
+}
+func (m *ImportRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportRes.Merge(m, src)
+}
+func (m *ImportRes) XXX_Size() int {
+	return xxx_messageInfo_ImportRes.Size(m)
+}
+func (m *ImportRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportRes proto.InternalMessageInfo
+
+func (m *ImportRes) GetImported() int32 {
+	if m != nil {
+		return m.Imported
+	}
+	return 0
+}
+
+func (m *ImportRes) GetFailed() int32 {
+	if m != nil {
+		return m.Failed
+	}
+	return 0
+}
+
+func (m *ImportRes) GetResults() []*ImportResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type ExportReq struct {
+	CodePrefix           string       `protobuf:"bytes,1,opt,name=code_prefix,json=codePrefix,proto3" json:"code_prefix,omitempty"`
+	Status               VoucherState `protobuf:"varint,2,opt,name=status,proto3,enum=proto.VoucherState" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ExportReq) Reset()         { *m = ExportReq{} }
+func (m *ExportReq) String() string { return proto.CompactTextString(m) }
+func (*ExportReq) ProtoMessage()    {}
+func (*ExportReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{26}
+}
+
+func (m *ExportReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportReq.Unmarshal(m, b)
+}
+func (m *ExportReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportReq.Marshal(b, m, deterministic)
+}
+func (m *ExportReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportReq.Merge(m, src)
+}
+func (m *ExportReq) XXX_Size() int {
+	return xxx_messageInfo_ExportReq.Size(m)
+}
+func (m *ExportReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportReq proto.InternalMessageInfo
+
+func (m *ExportReq) GetCodePrefix() string {
+	if m != nil {
+		return m.CodePrefix
+	}
+	return ""
+}
+
+func (m *ExportReq) GetStatus() VoucherState {
+	if m != nil {
+		return m.Status
+	}
+	return VoucherState_STATE_ANY
+}
+
+type ExportRow struct {
+	Values               []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportRow) Reset()         { *m = ExportRow{} }
+func (m *ExportRow) String() string { return proto.CompactTextString(m) }
+func (*ExportRow) ProtoMessage()    {}
+func (*ExportRow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f00a0a2a5aeccc, []int{27}
+}
+
+func (m *ExportRow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportRow.Unmarshal(m, b)
+}
+func (m *ExportRow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportRow.Marshal(b, m, deterministic)
+}
+func (m *ExportRow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportRow.Merge(m, src)
+}
+func (m *ExportRow) XXX_Size() int {
+	return xxx_messageInfo_ExportRow.Size(m)
+}
+func (m *ExportRow) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportRow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportRow proto.InternalMessageInfo
+
+func (m *ExportRow) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("proto.VerifyStatus", VerifyStatus_name, VerifyStatus_value)
+	proto.RegisterEnum("proto.DiscountType", DiscountType_name, DiscountType_value)
+	proto.RegisterEnum("proto.VoucherState", VoucherState_name, VoucherState_value)
+	proto.RegisterEnum("proto.SortField", SortField_name, SortField_value)
+	proto.RegisterEnum("proto.ReservationStatus", ReservationStatus_name, ReservationStatus_value)
+	proto.RegisterType((*Voucher)(nil), "proto.Voucher")
+	proto.RegisterType((*VoucherReq)(nil), "proto.VoucherReq")
+	proto.RegisterType((*VoucherRes)(nil), "proto.VoucherRes")
+	proto.RegisterType((*Rule)(nil), "proto.Rule")
+	proto.RegisterType((*OrderItem)(nil), "proto.OrderItem")
+	proto.RegisterType((*Reason)(nil), "proto.Reason")
+	proto.RegisterType((*VerifyReq)(nil), "proto.VerifyReq")
+	proto.RegisterType((*VerifyRes)(nil), "proto.VerifyRes")
+	proto.RegisterType((*GenerateReq)(nil), "proto.GenerateReq")
+	proto.RegisterType((*GenerateRes)(nil), "proto.GenerateRes")
+	proto.RegisterType((*ListReq)(nil), "proto.ListReq")
+	proto.RegisterType((*ListRes)(nil), "proto.ListRes")
+	proto.RegisterType((*Reservation)(nil), "proto.Reservation")
+	proto.RegisterType((*ReserveRes)(nil), "proto.ReserveRes")
+	proto.RegisterType((*ReservationReq)(nil), "proto.ReservationReq")
+	proto.RegisterType((*Evaluation)(nil), "proto.Evaluation")
+	proto.RegisterType((*EvaluateReq)(nil), "proto.EvaluateReq")
+	proto.RegisterType((*EvaluateRes)(nil), "proto.EvaluateRes")
+	proto.RegisterType((*ChangeReq)(nil), "proto.ChangeReq")
+	proto.RegisterType((*Audit)(nil), "proto.Audit")
+	proto.RegisterType((*AuditReq)(nil), "proto.AuditReq")
+	proto.RegisterType((*AuditRes)(nil), "proto.AuditRes")
+	proto.RegisterType((*ImportRow)(nil), "proto.ImportRow")
+	proto.RegisterType((*FieldError)(nil), "proto.FieldError")
+	proto.RegisterType((*ImportResult)(nil), "proto.ImportResult")
+	proto.RegisterType((*ImportRes)(nil), "proto.ImportRes")
+	proto.RegisterType((*ExportReq)(nil), "proto.ExportReq")
+	proto.RegisterType((*ExportRow)(nil), "proto.ExportRow")
+}
+
+func init() { proto.RegisterFile("proto/voucher.proto", fileDescriptor_60f00a0a2a5aeccc) }
+
+var fileDescriptor_60f00a0a2a5aeccc = []byte{
+	// 1851 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x57, 0x4f, 0x93, 0xdb, 0x48,
+	0x15, 0x1f, 0xc9, 0x96, 0x6c, 0x3d, 0x39, 0x8e, 0xd2, 0x59, 0x82, 0xcb, 0xc0, 0xc6, 0x68, 0xa9,
+	0x5d, 0xef, 0x6c, 0x98, 0xa4, 0x66, 0xb7, 0x02, 0x14, 0x07, 0xca, 0x6b, 0x2b, 0x8b, 0x61, 0x62,
+	0x4f, 0xb5, 0x3d, 0x21, 0x7b, 0x72, 0xf5, 0x48, 0x3d, 0x33, 0xaa, 0x58, 0x96, 0x47, 0x6a, 0xcf,
+	0x38, 0xc5, 0x81, 0x2b, 0x07, 0xbe, 0x04, 0x1f, 0x80, 0x1b, 0x27, 0x0e, 0x7c, 0x02, 0x28, 0x8a,
+	0x0f, 0x44, 0x15, 0xd5, 0x7f, 0x24, 0xcb, 0x1e, 0x7b, 0xfe, 0x9c, 0xec, 0xdf, 0xeb, 0xd7, 0xdd,
+	0xef, 0xfd, 0xde, 0xbf, 0x16, 0x3c, 0x9d, 0x27, 0x31, 0x8b, 0x5f, 0x5e, 0xc5, 0x0b, 0xff, 0x82,
+	0x26, 0x07, 0x02, 0x21, 0x43, 0xfc, 0x34, 0x9f, 0x9f, 0xc7, 0xf1, 0xf9, 0x94, 0xbe, 0x14, 0xe8,
+	0x74, 0x71, 0xf6, 0x92, 0x85, 0x11, 0x4d, 0x19, 0x89, 0xe6, 0x52, 0xcf, 0xfd, 0x87, 0x0e, 0x95,
+	0x77, 0x72, 0x27, 0xaa, 0x83, 0x1e, 0x06, 0x0d, 0xad, 0xa5, 0xb5, 0x0d, 0xac, 0x87, 0x01, 0x42,
+	0x50, 0xf6, 0xe3, 0x80, 0x36, 0xf4, 0x96, 0xd6, 0xb6, 0xb0, 0xf8, 0x8f, 0x9a, 0x50, 0x0d, 0xc2,
+	0xd4, 0x8f, 0x17, 0x33, 0xd6, 0x28, 0xb5, 0xb4, 0xb6, 0x8e, 0x73, 0x8c, 0x5e, 0x81, 0x91, 0x32,
+	0x92, 0xb0, 0x46, 0xb9, 0xa5, 0xb5, 0xed, 0xc3, 0xe6, 0x81, 0xbc, 0xfc, 0x20, 0xbb, 0xfc, 0x60,
+	0x9c, 0x5d, 0x8e, 0xa5, 0x22, 0x7a, 0x01, 0x25, 0x3a, 0x0b, 0x1a, 0xc6, 0x9d, 0xfa, 0x5c, 0x0d,
+	0x7d, 0x02, 0xc6, 0xe5, 0x22, 0x66, 0xa4, 0x61, 0x0a, 0x13, 0x25, 0x40, 0x6d, 0x70, 0x22, 0xb2,
+	0x9c, 0xcc, 0x69, 0x32, 0xf1, 0x17, 0x29, 0x8b, 0x23, 0x9a, 0x34, 0x2a, 0x42, 0xa1, 0x1e, 0x91,
+	0xe5, 0x31, 0x4d, 0xba, 0x4a, 0xca, 0xfd, 0x59, 0xa4, 0x34, 0x68, 0x54, 0xc5, 0xaa, 0xf8, 0x8f,
+	0x9e, 0x43, 0x39, 0x59, 0x4c, 0x69, 0xc3, 0x12, 0x26, 0xd8, 0xf2, 0xee, 0x03, 0xbc, 0x98, 0x52,
+	0x2c, 0x16, 0x94, 0xc3, 0xe4, 0x74, 0x4a, 0x83, 0x06, 0xb4, 0xb4, 0x76, 0x15, 0xe7, 0xd8, 0xfd,
+	0x9f, 0x06, 0xa0, 0xc8, 0xc3, 0xf4, 0x32, 0xe7, 0x4b, 0xdb, 0xc1, 0x97, 0xbe, 0x8b, 0xaf, 0xd2,
+	0x03, 0xf9, 0x2a, 0x3f, 0x90, 0x2f, 0xe3, 0x2e, 0xbe, 0xcc, 0xad, 0x7c, 0x65, 0xdc, 0x54, 0x76,
+	0x70, 0xe3, 0xbe, 0x2e, 0xb8, 0x9f, 0x22, 0x17, 0xca, 0x01, 0x61, 0x44, 0xb8, 0x69, 0x1f, 0xd6,
+	0x95, 0x7a, 0xa6, 0x20, 0xd6, 0x7e, 0x57, 0xae, 0x6a, 0x8e, 0xee, 0xfe, 0x4d, 0x83, 0x32, 0x3f,
+	0x06, 0x7d, 0x01, 0x65, 0xf6, 0x71, 0x2e, 0x19, 0xab, 0x1f, 0x3e, 0x55, 0x5b, 0x7a, 0x8a, 0xa0,
+	0xf1, 0xc7, 0x39, 0xc5, 0x42, 0x01, 0xfd, 0x14, 0x6a, 0x19, 0x6d, 0x13, 0x9f, 0xcc, 0x15, 0x95,
+	0x76, 0x26, 0xeb, 0x92, 0x39, 0xfa, 0x11, 0x58, 0x51, 0x38, 0x9b, 0xc4, 0x49, 0x40, 0x93, 0x2c,
+	0x35, 0xa3, 0x70, 0x36, 0xe4, 0x18, 0x7d, 0x0a, 0xe0, 0x13, 0x46, 0xcf, 0xe3, 0x24, 0xa4, 0x69,
+	0xa3, 0xdc, 0x2a, 0xb5, 0x2d, 0x5c, 0x90, 0xa0, 0x1f, 0x83, 0x95, 0x32, 0xe2, 0x7f, 0xe0, 0x71,
+	0x15, 0x74, 0x55, 0xf1, 0x4a, 0xe0, 0xfe, 0x06, 0x2c, 0x71, 0x4c, 0x9f, 0xd1, 0x88, 0x47, 0x54,
+	0x6d, 0xfc, 0xa8, 0x22, 0x9d, 0x63, 0xf4, 0x0c, 0x4c, 0x12, 0x15, 0x62, 0xad, 0x90, 0xfb, 0x1a,
+	0x4c, 0x4c, 0x49, 0x1a, 0xcf, 0xb6, 0xe6, 0x48, 0x03, 0x2a, 0x11, 0x4d, 0x53, 0x72, 0x9e, 0x95,
+	0x5a, 0x06, 0xdd, 0x3f, 0x82, 0xf5, 0x8e, 0x26, 0xe1, 0xd9, 0xc7, 0x5d, 0xe9, 0xb5, 0xe3, 0x42,
+	0x61, 0x64, 0x16, 0xdc, 0x92, 0x32, 0x32, 0x0b, 0xeb, 0xe7, 0x60, 0x84, 0x8c, 0x46, 0x92, 0x06,
+	0xfb, 0xd0, 0x51, 0xac, 0xe7, 0x1e, 0x62, 0xb9, 0xec, 0xfe, 0x47, 0x5b, 0xdd, 0x9e, 0xa2, 0xaf,
+	0xc0, 0x4c, 0x19, 0x61, 0x8b, 0x74, 0x23, 0x58, 0x52, 0x63, 0x24, 0x96, 0xb0, 0x52, 0xb9, 0x4f,
+	0x2a, 0x14, 0x4c, 0x2f, 0x6d, 0x9a, 0x9e, 0x57, 0x4c, 0x79, 0xa3, 0x62, 0x3e, 0x01, 0x83, 0xc5,
+	0x8c, 0x4c, 0x45, 0x88, 0x74, 0x2c, 0x01, 0xfa, 0x02, 0x2a, 0x89, 0x60, 0x37, 0x6d, 0x98, 0xc2,
+	0xa5, 0x47, 0x59, 0xaa, 0x0a, 0x29, 0xce, 0x56, 0xdd, 0x7f, 0xe9, 0x60, 0x7f, 0x47, 0x67, 0x34,
+	0x21, 0x8c, 0x72, 0x46, 0x9f, 0x81, 0x39, 0x4f, 0xe8, 0x59, 0xb8, 0x54, 0x9c, 0x2a, 0xc4, 0xaf,
+	0x59, 0x55, 0xac, 0x81, 0x25, 0xe0, 0xda, 0x53, 0x3a, 0x3b, 0x67, 0x17, 0xc2, 0x60, 0x03, 0x2b,
+	0xc4, 0x0d, 0x26, 0xd3, 0xf9, 0x05, 0x39, 0xa5, 0xd2, 0x60, 0x0b, 0xe7, 0x78, 0xcd, 0x19, 0x63,
+	0x57, 0xf9, 0x9b, 0x0f, 0x2c, 0xff, 0xca, 0x03, 0xcb, 0xbf, 0x7a, 0x57, 0xf9, 0x5b, 0xb7, 0x96,
+	0x3f, 0xec, 0x2a, 0xff, 0xcf, 0x8a, 0x6c, 0xa6, 0x92, 0xb5, 0x80, 0xf2, 0x04, 0xe1, 0xe5, 0x25,
+	0x81, 0xfb, 0x4f, 0x1d, 0x2a, 0x47, 0x61, 0xca, 0x38, 0xdf, 0xcf, 0xc1, 0xe6, 0xc2, 0xc9, 0x1a,
+	0xe9, 0xc0, 0x45, 0xc7, 0x92, 0xf8, 0x5f, 0x80, 0x45, 0x7c, 0x16, 0x5e, 0xd1, 0x09, 0x61, 0x0d,
+	0xfd, 0x4e, 0x37, 0xab, 0x52, 0xb9, 0xc3, 0x78, 0x7f, 0xe0, 0xc5, 0xbf, 0x31, 0x9a, 0xec, 0x28,
+	0x9c, 0x65, 0xcd, 0x44, 0xa8, 0x90, 0xe5, 0x64, 0x23, 0xb7, 0xec, 0x88, 0x2c, 0x73, 0x95, 0x55,
+	0x8e, 0x1b, 0xeb, 0x39, 0x2e, 0x13, 0x97, 0x27, 0x39, 0xcd, 0x73, 0xfc, 0x67, 0x50, 0x4e, 0x63,
+	0x15, 0xbd, 0x7a, 0x5e, 0x45, 0xa3, 0x38, 0x61, 0x6f, 0x42, 0x3a, 0x0d, 0xb0, 0x58, 0xe5, 0x45,
+	0x1b, 0xd0, 0xd4, 0x17, 0x31, 0xab, 0x62, 0xf1, 0x9f, 0x13, 0x35, 0x0d, 0xa3, 0x90, 0x65, 0x81,
+	0x11, 0x80, 0xa7, 0x97, 0xbf, 0x48, 0xd2, 0x58, 0x86, 0xc3, 0xc2, 0x0a, 0xb9, 0x83, 0x8c, 0xbf,
+	0x55, 0x59, 0x69, 0xad, 0xd2, 0xce, 0xb2, 0x7a, 0x0e, 0xf6, 0x8c, 0x2e, 0xd9, 0x44, 0x9d, 0x25,
+	0x1b, 0x0a, 0x70, 0x51, 0x57, 0x9e, 0xf7, 0x77, 0x1d, 0x6c, 0x4c, 0x53, 0x9a, 0x5c, 0x11, 0x16,
+	0xc6, 0xb3, 0x1b, 0x53, 0xff, 0x27, 0x00, 0xea, 0x29, 0x31, 0x09, 0x03, 0x55, 0x01, 0x96, 0x92,
+	0xf4, 0x83, 0x5b, 0x3b, 0xcb, 0xaa, 0xa4, 0xcb, 0x3b, 0x4b, 0xfa, 0x66, 0x15, 0x64, 0x9c, 0x4b,
+	0x22, 0x1b, 0x79, 0xed, 0xe6, 0x26, 0x6e, 0x34, 0x97, 0x5f, 0x01, 0xd0, 0xe5, 0x3c, 0x4c, 0x68,
+	0xca, 0xb3, 0xe4, 0xee, 0x62, 0xb0, 0x94, 0x76, 0x87, 0xf1, 0xad, 0x7e, 0x42, 0x09, 0xa3, 0xc1,
+	0x84, 0x48, 0xfa, 0xef, 0xd8, 0xaa, 0xb4, 0x3b, 0xcc, 0x9d, 0x02, 0x48, 0x93, 0x44, 0xae, 0xb7,
+	0xc1, 0xbc, 0x12, 0x8d, 0x4f, 0x10, 0xb7, 0x6a, 0xa2, 0x79, 0xbf, 0xc4, 0x6a, 0x1d, 0x7d, 0x03,
+	0x76, 0xb2, 0x72, 0x45, 0x25, 0x35, 0xba, 0xe9, 0x24, 0x2e, 0xaa, 0xb9, 0x2d, 0xa8, 0x17, 0xd7,
+	0xe8, 0xe5, 0x66, 0x98, 0xdc, 0xbf, 0x6a, 0x00, 0xde, 0x15, 0x99, 0x2e, 0x64, 0x14, 0xb7, 0x0d,
+	0x87, 0x4f, 0x01, 0xc8, 0x7c, 0x3e, 0x0d, 0x7d, 0x31, 0xd5, 0x74, 0x91, 0x81, 0x05, 0x09, 0x0f,
+	0x0b, 0x9d, 0x86, 0xe7, 0x21, 0x5f, 0x55, 0x03, 0x33, 0xc3, 0xb7, 0x76, 0xe1, 0x42, 0xbf, 0x35,
+	0x6e, 0xed, 0xb7, 0x3e, 0xd8, 0xca, 0x44, 0xd1, 0x6e, 0xb7, 0x36, 0x88, 0x9d, 0x23, 0x2c, 0x1f,
+	0x53, 0xa5, 0xdb, 0xc7, 0xd4, 0x5f, 0xb4, 0xe2, 0x2d, 0x29, 0xfa, 0x1a, 0x6c, 0x9a, 0xf3, 0x92,
+	0xaa, 0x5a, 0x79, 0xa2, 0x76, 0xaf, 0x18, 0xc3, 0x45, 0xad, 0xdb, 0xe6, 0xe8, 0xce, 0xe7, 0x6e,
+	0x3e, 0x8c, 0xca, 0x85, 0x61, 0xe4, 0x4e, 0xc0, 0xea, 0x5e, 0x90, 0xd9, 0x39, 0xdd, 0x12, 0x34,
+	0xbe, 0x85, 0xf8, 0x2c, 0x2f, 0x4b, 0x09, 0xb2, 0xb6, 0x5e, 0xba, 0x57, 0x5b, 0x77, 0xff, 0xad,
+	0x81, 0xd1, 0x59, 0x04, 0x21, 0x7b, 0x68, 0xe5, 0x72, 0x1f, 0x7d, 0x91, 0x84, 0xb2, 0x6e, 0x15,
+	0x5a, 0x19, 0x55, 0x2e, 0x1a, 0xf5, 0x0c, 0xcc, 0x53, 0x7a, 0x16, 0x27, 0xf2, 0x39, 0x64, 0x61,
+	0x85, 0x84, 0xf6, 0x19, 0x53, 0x6f, 0x46, 0x0b, 0x4b, 0xb0, 0x51, 0x58, 0x95, 0x87, 0x14, 0xd6,
+	0x97, 0x50, 0x15, 0xee, 0x70, 0xbe, 0xd6, 0x3d, 0xd0, 0x36, 0x3c, 0x70, 0x5f, 0xe4, 0xaa, 0x29,
+	0x6a, 0xad, 0xf5, 0xc2, 0x9a, 0x8a, 0xaf, 0x5c, 0x16, 0x2b, 0xee, 0x02, 0xac, 0x7e, 0x34, 0x8f,
+	0x13, 0x86, 0xe3, 0x6b, 0xe4, 0x40, 0x29, 0x89, 0xaf, 0xd5, 0x91, 0xfc, 0x2f, 0xfa, 0x0a, 0x2a,
+	0xea, 0x64, 0x55, 0x94, 0x4f, 0x36, 0xfa, 0x29, 0xbd, 0xc4, 0x99, 0x06, 0xfa, 0x12, 0x4c, 0x9a,
+	0x24, 0x71, 0x92, 0x65, 0x63, 0xa6, 0x2b, 0x5a, 0xbd, 0xc7, 0x57, 0xb0, 0x52, 0x70, 0x7b, 0x00,
+	0x2b, 0x29, 0xa7, 0xeb, 0x8c, 0x23, 0x55, 0x98, 0x12, 0xa0, 0x16, 0xd8, 0x7c, 0x12, 0x24, 0xe1,
+	0x3c, 0x6f, 0x0a, 0x16, 0x2e, 0x8a, 0xdc, 0x0f, 0x50, 0x53, 0xc6, 0xd3, 0x74, 0x31, 0x65, 0x5b,
+	0xec, 0xaf, 0x83, 0x1e, 0x7f, 0x50, 0x55, 0xad, 0xc7, 0x1f, 0x54, 0x36, 0x94, 0xf2, 0x6c, 0x58,
+	0x99, 0x5c, 0xbe, 0xcb, 0xe4, 0x59, 0xce, 0x14, 0x4d, 0x79, 0xca, 0x87, 0x02, 0xd0, 0x2c, 0x02,
+	0x39, 0xe6, 0x49, 0x71, 0x46, 0x42, 0xfe, 0x29, 0x24, 0xb3, 0x4b, 0x21, 0xf4, 0x73, 0xde, 0x11,
+	0xb8, 0x9d, 0x19, 0x3f, 0xd9, 0xe4, 0x2c, 0xfa, 0x80, 0x33, 0x1d, 0xf7, 0x7b, 0xb0, 0xbc, 0xa5,
+	0x5c, 0xb8, 0xc7, 0xa3, 0x60, 0x35, 0x95, 0xf5, 0x3b, 0xa7, 0xb2, 0xfb, 0x59, 0x7e, 0x74, 0x7c,
+	0xcd, 0xcd, 0xe5, 0x35, 0x9e, 0x77, 0x1c, 0x85, 0xf6, 0xff, 0xac, 0x41, 0xad, 0xf8, 0x6e, 0x45,
+	0x26, 0xe8, 0xc3, 0xdf, 0x3b, 0x7b, 0xe8, 0x11, 0x58, 0x83, 0xe1, 0x78, 0xf2, 0x66, 0x78, 0x32,
+	0xe8, 0x39, 0x1a, 0xb2, 0xa1, 0xe2, 0xbd, 0x3f, 0xee, 0x63, 0xaf, 0xe7, 0xe8, 0xe8, 0x31, 0xd8,
+	0x7c, 0x6d, 0x34, 0xee, 0xe0, 0xb1, 0xd7, 0x73, 0x4a, 0x5c, 0xd9, 0x7b, 0xff, 0xdb, 0xce, 0xc9,
+	0x88, 0xc3, 0x32, 0x42, 0x50, 0xef, 0x9e, 0x8c, 0xc6, 0xc3, 0xb7, 0x1e, 0x9e, 0x1c, 0xf5, 0xdf,
+	0xf6, 0xc7, 0x8e, 0xc1, 0x65, 0x7c, 0x4f, 0xe7, 0xf8, 0xf8, 0xa8, 0xdf, 0xed, 0x7c, 0x7b, 0xe4,
+	0x39, 0x26, 0xaa, 0x41, 0xb5, 0x3f, 0xe8, 0x74, 0xc7, 0xfd, 0x77, 0x9e, 0x53, 0xd9, 0xff, 0x1c,
+	0x6a, 0xc5, 0xcf, 0x1d, 0x7e, 0xe5, 0xb1, 0x87, 0xbb, 0xde, 0x60, 0xec, 0xec, 0x21, 0x0b, 0x8c,
+	0x37, 0xfd, 0xf7, 0x5e, 0xcf, 0xd1, 0xf6, 0xff, 0x04, 0xb5, 0xa2, 0xbf, 0xfc, 0xf2, 0xd1, 0xb8,
+	0x33, 0xf6, 0x26, 0x9d, 0xc1, 0xf7, 0xce, 0x1e, 0x72, 0xa0, 0xa6, 0xa0, 0x3c, 0x58, 0xe3, 0x57,
+	0x4b, 0xc9, 0xc9, 0x71, 0x77, 0xf8, 0xb6, 0x3f, 0xf8, 0xce, 0xd1, 0xd1, 0x13, 0x78, 0x24, 0x65,
+	0x99, 0x57, 0x25, 0xf4, 0x14, 0x1e, 0x67, 0xa2, 0x35, 0x57, 0xa4, 0x30, 0x37, 0xd4, 0xd8, 0x7f,
+	0x0f, 0x56, 0xfe, 0xb6, 0xe1, 0x56, 0x8e, 0x86, 0x78, 0x3c, 0xe9, 0xf7, 0x24, 0x69, 0x02, 0x74,
+	0x87, 0x3d, 0x7e, 0x71, 0x1d, 0x40, 0x40, 0x41, 0x94, 0xa3, 0x73, 0x7f, 0x05, 0xf6, 0x06, 0xfc,
+	0x3e, 0x6e, 0x02, 0x47, 0xbd, 0xfe, 0xa8, 0x3b, 0x3c, 0x19, 0x8c, 0x9d, 0xf2, 0xfe, 0x1f, 0xe0,
+	0xc9, 0x8d, 0x61, 0xcf, 0x77, 0x61, 0x6f, 0xe4, 0xe1, 0x77, 0x9e, 0xba, 0xa2, 0x3b, 0x1c, 0xbc,
+	0xe9, 0xe3, 0xb7, 0x9c, 0x0c, 0xb9, 0x78, 0xe4, 0x75, 0x46, 0x22, 0x30, 0x3f, 0x84, 0xa7, 0x52,
+	0xb5, 0x33, 0xee, 0x0f, 0x07, 0x2b, 0xdf, 0x0e, 0xff, 0x6b, 0x42, 0x3d, 0x23, 0x8d, 0x26, 0x57,
+	0xa1, 0x4f, 0xd1, 0x21, 0x54, 0x31, 0x3d, 0x0f, 0x53, 0xde, 0xb3, 0x6e, 0xd6, 0x7b, 0xf3, 0x86,
+	0x28, 0x75, 0xf7, 0xd0, 0x01, 0x98, 0x32, 0x59, 0xd0, 0xe6, 0x94, 0xbf, 0x6c, 0x6e, 0x4a, 0x94,
+	0x3e, 0xa6, 0x01, 0xa5, 0xd1, 0x3d, 0xf5, 0x7f, 0x0d, 0x8f, 0xb2, 0x67, 0xf4, 0xb7, 0x84, 0xf9,
+	0x17, 0x28, 0x7b, 0x1d, 0x14, 0x3e, 0x55, 0x9a, 0x37, 0x65, 0xa9, 0xbb, 0xf7, 0x4a, 0x43, 0xaf,
+	0xa0, 0xc6, 0x5f, 0x87, 0xca, 0xe0, 0x14, 0x65, 0x8f, 0x42, 0xf5, 0xe4, 0x6e, 0xae, 0x63, 0x7e,
+	0xdd, 0x2b, 0xa8, 0xa8, 0x87, 0xcc, 0x16, 0xfb, 0x9e, 0xac, 0x3d, 0x4c, 0xe4, 0x2d, 0xe8, 0x35,
+	0x54, 0xba, 0xf1, 0xec, 0x2c, 0x4c, 0x22, 0xf4, 0x83, 0x2d, 0x0f, 0x97, 0x82, 0x75, 0x05, 0xb1,
+	0xdc, 0x87, 0xe9, 0x94, 0x92, 0x94, 0x3e, 0x6c, 0xdf, 0x37, 0x50, 0xcd, 0x06, 0x7a, 0xce, 0x45,
+	0xe1, 0x1d, 0xd1, 0xbc, 0x29, 0x93, 0x7e, 0x41, 0x8f, 0x8a, 0x0f, 0x02, 0xc2, 0x56, 0xae, 0xe5,
+	0xb3, 0xb8, 0xb9, 0xf1, 0x5c, 0x96, 0x3b, 0xf0, 0xc3, 0x76, 0xbc, 0x00, 0xd3, 0x5b, 0x32, 0xfe,
+	0x71, 0x75, 0x4f, 0xed, 0x1e, 0x9d, 0xd2, 0xfb, 0x5b, 0xc3, 0x83, 0x24, 0x26, 0x58, 0x8a, 0x1e,
+	0xaf, 0x0d, 0x34, 0x7a, 0xd9, 0xdc, 0x10, 0x70, 0x8f, 0x7f, 0x09, 0x75, 0xd9, 0x5f, 0xf3, 0xe8,
+	0x3b, 0xeb, 0x6d, 0x37, 0xbe, 0x6e, 0x3a, 0x9b, 0x8d, 0xd8, 0xdd, 0x6b, 0x6b, 0x7c, 0xa7, 0xb7,
+	0xdc, 0xba, 0x33, 0xef, 0xcb, 0xcd, 0x0d, 0x49, 0x7c, 0xcd, 0xf3, 0xed, 0xd4, 0x14, 0xc2, 0xaf,
+	0xff, 0x3f, 0x00, 0xc5, 0xce, 0x63, 0x76, 0x75, 0x14, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// VoucherServiceClient is the client API for VoucherService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type VoucherServiceClient interface {
+	Register(ctx context.Context, in *VoucherReq, opts ...grpc.CallOption) (*VoucherRes, error)
+	Verify(ctx context.Context, in *VerifyReq, opts ...grpc.CallOption) (*VerifyRes, error)
+	Redeem(ctx context.Context, in *VerifyReq, opts ...grpc.CallOption) (*VerifyRes, error)
+	// Stream ve code sau moi batch duoc insert
+	GenerateBatch(ctx context.Context, in *GenerateReq, opts ...grpc.CallOption) (VoucherService_GenerateBatchClient, error)
+	ListVouchers(ctx context.Context, in *ListReq, opts ...grpc.CallOption) (*ListRes, error)
+	// Giu cho voucher trong RESERVATION_TTL, sau do phai Confirm hoac Release
+	Reserve(ctx context.Context, in *VerifyReq, opts ...grpc.CallOption) (*ReserveRes, error)
+	Confirm(ctx context.Context, in *ReservationReq, opts ...grpc.CallOption) (*Reservation, error)
+	Release(ctx context.Context, in *ReservationReq, opts ...grpc.CallOption) (*Reservation, error)
+	// Tinh discount khi dung chung nhieu voucher, khong tinh vao quota
+	Evaluate(ctx context.Context, in *EvaluateReq, opts ...grpc.CallOption) (*EvaluateRes, error)
+	// Lifecycle, moi thay doi deu duoc ghi audit cung voi actor
+	Deactivate(ctx context.Context, in *ChangeReq, opts ...grpc.CallOption) (*Voucher, error)
+	Reactivate(ctx context.Context, in *ChangeReq, opts ...grpc.CallOption) (*Voucher, error)
+	Extend(ctx context.Context, in *ChangeReq, opts ...grpc.CallOption) (*Voucher, error)
+	Delete(ctx context.Context, in *ChangeReq, opts ...grpc.CallOption) (*Voucher, error)
+	ListAudits(ctx context.Context, in *AuditReq, opts ...grpc.CallOption) (*AuditRes, error)
+	// Client stream tung dong cua file, server tra ve ket qua cua tung dong khi client dong stream
+	ImportVouchers(ctx context.Context, opts ...grpc.CallOption) (VoucherService_ImportVouchersClient, error)
+	// Message dau tien la header, cac message sau la tung voucher
+	ExportVouchers(ctx context.Context, in *ExportReq, opts ...grpc.CallOption) (VoucherService_ExportVouchersClient, error)
+}
+
+type voucherServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewVoucherServiceClient(cc *grpc.ClientConn) VoucherServiceClient {
+	return &voucherServiceClient{cc}
+}
+
+func (c *voucherServiceClient) Register(ctx context.Context, in *VoucherReq, opts ...grpc.CallOption) (*VoucherRes, error) {
+	out := new(VoucherRes)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Register", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Verify(ctx context.Context, in *VerifyReq, opts ...grpc.CallOption) (*VerifyRes, error) {
+	out := new(VerifyRes)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Verify", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Redeem(ctx context.Context, in *VerifyReq, opts ...grpc.CallOption) (*VerifyRes, error) {
+	out := new(VerifyRes)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Redeem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) GenerateBatch(ctx context.Context, in *GenerateReq, opts ...grpc.CallOption) (VoucherService_GenerateBatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_VoucherService_serviceDesc.Streams[0], "/proto.VoucherService/GenerateBatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &voucherServiceGenerateBatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type VoucherService_GenerateBatchClient interface {
+	Recv() (*GenerateRes, error)
+	grpc.ClientStream
+}
+
+type voucherServiceGenerateBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *voucherServiceGenerateBatchClient) Recv() (*GenerateRes, error) {
+	m := new(GenerateRes)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *voucherServiceClient) ListVouchers(ctx context.Context, in *ListReq, opts ...grpc.CallOption) (*ListRes, error) {
+	out := new(ListRes)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/ListVouchers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Reserve(ctx context.Context, in *VerifyReq, opts ...grpc.CallOption) (*ReserveRes, error) {
+	out := new(ReserveRes)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Reserve", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Confirm(ctx context.Context, in *ReservationReq, opts ...grpc.CallOption) (*Reservation, error) {
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Confirm", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Release(ctx context.Context, in *ReservationReq, opts ...grpc.CallOption) (*Reservation, error) {
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Release", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Evaluate(ctx context.Context, in *EvaluateReq, opts ...grpc.CallOption) (*EvaluateRes, error) {
+	out := new(EvaluateRes)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Evaluate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Deactivate(ctx context.Context, in *ChangeReq, opts ...grpc.CallOption) (*Voucher, error) {
+	out := new(Voucher)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Deactivate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Reactivate(ctx context.Context, in *ChangeReq, opts ...grpc.CallOption) (*Voucher, error) {
+	out := new(Voucher)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Reactivate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Extend(ctx context.Context, in *ChangeReq, opts ...grpc.CallOption) (*Voucher, error) {
+	out := new(Voucher)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Extend", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) Delete(ctx context.Context, in *ChangeReq, opts ...grpc.CallOption) (*Voucher, error) {
+	out := new(Voucher)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) ListAudits(ctx context.Context, in *AuditReq, opts ...grpc.CallOption) (*AuditRes, error) {
+	out := new(AuditRes)
+	err := c.cc.Invoke(ctx, "/proto.VoucherService/ListAudits", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voucherServiceClient) ImportVouchers(ctx context.Context, opts ...grpc.CallOption) (VoucherService_ImportVouchersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_VoucherService_serviceDesc.Streams[1], "/proto.VoucherService/ImportVouchers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &voucherServiceImportVouchersClient{stream}
+	return x, nil
+}
+
+type VoucherService_ImportVouchersClient interface {
+	Send(*ImportRow) error
+	CloseAndRecv() (*ImportRes, error)
+	grpc.ClientStream
+}
+
+type voucherServiceImportVouchersClient struct {
+	grpc.ClientStream
+}
+
+func (x *voucherServiceImportVouchersClient) Send(m *ImportRow) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *voucherServiceImportVouchersClient) CloseAndRecv() (*ImportRes, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportRes)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *voucherServiceClient) ExportVouchers(ctx context.Context, in *ExportReq, opts ...grpc.CallOption) (VoucherService_ExportVouchersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_VoucherService_serviceDesc.Streams[2], "/proto.VoucherService/ExportVouchers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &voucherServiceExportVouchersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type VoucherService_ExportVouchersClient interface {
+	Recv() (*ExportRow, error)
+	grpc.ClientStream
+}
+
+type voucherServiceExportVouchersClient struct {
+	grpc.ClientStream
+}
+
+func (x *voucherServiceExportVouchersClient) Recv() (*ExportRow, error) {
+	m := new(ExportRow)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// VoucherServiceServer is the server API for VoucherService service.
+type VoucherServiceServer interface {
+	Register(context.Context, *VoucherReq) (*VoucherRes, error)
+	Verify(context.Context, *VerifyReq) (*VerifyRes, error)
+	Redeem(context.Context, *VerifyReq) (*VerifyRes, error)
+	// Stream ve code sau moi batch duoc insert
+	GenerateBatch(*GenerateReq, VoucherService_GenerateBatchServer) error
+	ListVouchers(context.Context, *ListReq) (*ListRes, error)
+	// Giu cho voucher trong RESERVATION_TTL, sau do phai Confirm hoac Release
+	Reserve(context.Context, *VerifyReq) (*ReserveRes, error)
+	Confirm(context.Context, *ReservationReq) (*Reservation, error)
+	Release(context.Context, *ReservationReq) (*Reservation, error)
+	// Tinh discount khi dung chung nhieu voucher, khong tinh vao quota
+	Evaluate(context.Context, *EvaluateReq) (*EvaluateRes, error)
+	// Lifecycle, moi thay doi deu duoc ghi audit cung voi actor
+	Deactivate(context.Context, *ChangeReq) (*Voucher, error)
+	Reactivate(context.Context, *ChangeReq) (*Voucher, error)
+	Extend(context.Context, *ChangeReq) (*Voucher, error)
+	Delete(context.Context, *ChangeReq) (*Voucher, error)
+	ListAudits(context.Context, *AuditReq) (*AuditRes, error)
+	// Client stream tung dong cua file, server tra ve ket qua cua tung dong khi client dong stream
+	ImportVouchers(VoucherService_ImportVouchersServer) error
+	// Message dau tien la header, cac message sau la tung voucher
+	ExportVouchers(*ExportReq, VoucherService_ExportVouchersServer) error
+}
+
+// UnimplementedVoucherServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedVoucherServiceServer struct {
+}
+
+func (*UnimplementedVoucherServiceServer) Register(ctx context.Context, req *VoucherReq) (*VoucherRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Verify(ctx context.Context, req *VerifyReq) (*VerifyRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Redeem(ctx context.Context, req *VerifyReq) (*VerifyRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Redeem not implemented")
+}
+func (*UnimplementedVoucherServiceServer) GenerateBatch(req *GenerateReq, srv VoucherService_GenerateBatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method GenerateBatch not implemented")
+}
+func (*UnimplementedVoucherServiceServer) ListVouchers(ctx context.Context, req *ListReq) (*ListRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVouchers not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Reserve(ctx context.Context, req *VerifyReq) (*ReserveRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reserve not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Confirm(ctx context.Context, req *ReservationReq) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Confirm not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Release(ctx context.Context, req *ReservationReq) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Release not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Evaluate(ctx context.Context, req *EvaluateReq) (*EvaluateRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Evaluate not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Deactivate(ctx context.Context, req *ChangeReq) (*Voucher, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deactivate not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Reactivate(ctx context.Context, req *ChangeReq) (*Voucher, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reactivate not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Extend(ctx context.Context, req *ChangeReq) (*Voucher, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Extend not implemented")
+}
+func (*UnimplementedVoucherServiceServer) Delete(ctx context.Context, req *ChangeReq) (*Voucher, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedVoucherServiceServer) ListAudits(ctx context.Context, req *AuditReq) (*AuditRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAudits not implemented")
+}
+func (*UnimplementedVoucherServiceServer) ImportVouchers(srv VoucherService_ImportVouchersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportVouchers not implemented")
+}
+func (*UnimplementedVoucherServiceServer) ExportVouchers(req *ExportReq, srv VoucherService_ExportVouchersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportVouchers not implemented")
+}
+
+func RegisterVoucherServiceServer(s *grpc.Server, srv VoucherServiceServer) {
+	s.RegisterService(&_VoucherService_serviceDesc, srv)
+}
+
+func _VoucherService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoucherReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Register(ctx, req.(*VoucherReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Verify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Verify(ctx, req.(*VerifyReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Redeem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Redeem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Redeem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Redeem(ctx, req.(*VerifyReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_GenerateBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GenerateReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VoucherServiceServer).GenerateBatch(m, &voucherServiceGenerateBatchServer{stream})
+}
+
+type VoucherService_GenerateBatchServer interface {
+	Send(*GenerateRes) error
+	grpc.ServerStream
+}
+
+type voucherServiceGenerateBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *voucherServiceGenerateBatchServer) Send(m *GenerateRes) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _VoucherService_ListVouchers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).ListVouchers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/ListVouchers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).ListVouchers(ctx, req.(*ListReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Reserve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Reserve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Reserve",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Reserve(ctx, req.(*VerifyReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Confirm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReservationReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Confirm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Confirm",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Confirm(ctx, req.(*ReservationReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReservationReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Release(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Release",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Release(ctx, req.(*ReservationReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Evaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Evaluate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Evaluate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Evaluate(ctx, req.(*EvaluateReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Deactivate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Deactivate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Deactivate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Deactivate(ctx, req.(*ChangeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Reactivate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Reactivate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Reactivate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Reactivate(ctx, req.(*ChangeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Extend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Extend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Extend",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Extend(ctx, req.(*ChangeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).Delete(ctx, req.(*ChangeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_ListAudits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoucherServiceServer).ListAudits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VoucherService/ListAudits",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoucherServiceServer).ListAudits(ctx, req.(*AuditReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoucherService_ImportVouchers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(VoucherServiceServer).ImportVouchers(&voucherServiceImportVouchersServer{stream})
+}
+
+type VoucherService_ImportVouchersServer interface {
+	SendAndClose(*ImportRes) error
+	Recv() (*ImportRow, error)
+	grpc.ServerStream
+}
+
+type voucherServiceImportVouchersServer struct {
+	grpc.ServerStream
+}
+
+func (x *voucherServiceImportVouchersServer) SendAndClose(m *ImportRes) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *voucherServiceImportVouchersServer) Recv() (*ImportRow, error) {
+	m := new(ImportRow)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _VoucherService_ExportVouchers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VoucherServiceServer).ExportVouchers(m, &voucherServiceExportVouchersServer{stream})
+}
+
+type VoucherService_ExportVouchersServer interface {
+	Send(*ExportRow) error
+	grpc.ServerStream
+}
+
+type voucherServiceExportVouchersServer struct {
+	grpc.ServerStream
+}
+
+func (x *voucherServiceExportVouchersServer) Send(m *ExportRow) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _VoucherService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.VoucherService",
+	HandlerType: (*VoucherServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _VoucherService_Register_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _VoucherService_Verify_Handler,
+		},
+		{
+			MethodName: "Redeem",
+			Handler:    _VoucherService_Redeem_Handler,
+		},
+		{
+			MethodName: "ListVouchers",
+			Handler:    _VoucherService_ListVouchers_Handler,
+		},
+		{
+			MethodName: "Reserve",
+			Handler:    _VoucherService_Reserve_Handler,
+		},
+		{
+			MethodName: "Confirm",
+			Handler:    _VoucherService_Confirm_Handler,
+		},
+		{
+			MethodName: "Release",
+			Handler:    _VoucherService_Release_Handler,
+		},
+		{
+			MethodName: "Evaluate",
+			Handler:    _VoucherService_Evaluate_Handler,
+		},
+		{
+			MethodName: "Deactivate",
+			Handler:    _VoucherService_Deactivate_Handler,
+		},
+		{
+			MethodName: "Reactivate",
+			Handler:    _VoucherService_Reactivate_Handler,
+		},
+		{
+			MethodName: "Extend",
+			Handler:    _VoucherService_Extend_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _VoucherService_Delete_Handler,
+		},
+		{
+			MethodName: "ListAudits",
+			Handler:    _VoucherService_ListAudits_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GenerateBatch",
+			Handler:       _VoucherService_GenerateBatch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportVouchers",
+			Handler:       _VoucherService_ImportVouchers_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportVouchers",
+			Handler:       _VoucherService_ExportVouchers_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/voucher.proto",
 }
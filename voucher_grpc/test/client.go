@@ -8,6 +8,9 @@ import (
 	"../proto"
 	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -33,10 +36,33 @@ func main() {
 		Start:    start,
 		End:      end,
 	}
-	res, err := client.Register(context.TODO(), &req)
+	// Gui kem idempotency-key, neu bi timeout thi retry voi cung key
+	// se nhan lai voucher da insert thay vi AlreadyExists
+	ctx := metadata.AppendToOutgoingContext(context.TODO(), "idempotency-key", fmt.Sprintf("register-%d", now.UnixNano()))
+	var res *proto.VoucherRes
+	var err error
+	for i := 0; i < 3; i++ {
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Second)
+		res, err = client.Register(timeoutCtx, &req)
+		cancel()
+		if status.Code(err) != codes.DeadlineExceeded && status.Code(err) != codes.Unavailable {
+			break
+		}
+	}
+	// 4. In ket qua, loi (vd: AlreadyExists) nam trong status cua err
+	if err != nil {
+		st := status.Convert(err)
+		fmt.Println("Error:", st.Code(), st.Message(), st.Details())
+	} else {
+		fmt.Println("Response:", res)
+	}
+	// 5. Verify voucher vua tao voi don hang 100
+	verifyRes, err := client.Verify(context.TODO(), &proto.VerifyReq{
+		Code:   req.Code,
+		Amount: 100,
+	})
 	if err != nil {
 		panic(err)
 	}
-	// 4. In ket qua
-	fmt.Println("Response:", res)
+	fmt.Println("Verify:", verifyRes)
 }
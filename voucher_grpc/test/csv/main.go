@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"../../proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc"
+)
+
+const (
+	address = "localhost:10000"
+)
+
+// go run test/csv/main.go -import vouchers.csv
+// go run test/csv/main.go -export vouchers.csv -prefix SALE
+func main() {
+	importFile := flag.String("import", "", "file CSV can import, dong dau la header")
+	exportFile := flag.String("export", "", "file CSV de ghi ket qua export")
+	prefix := flag.String("prefix", "", "chi export voucher co code bat dau bang prefix")
+	flag.Parse()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+	client := proto.NewVoucherServiceClient(conn)
+	switch {
+	case *importFile != "":
+		err = importVouchers(client, *importFile)
+	case *exportFile != "":
+		err = exportVouchers(client, *exportFile, *prefix)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+func importVouchers(client proto.VoucherServiceClient, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	stream, err := client.ImportVouchers(context.TODO())
+	if err != nil {
+		return err
+	}
+	// Dong 1 la header
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		// Dong parse loi van duoc gui len de server bao cao cung cac dong khac
+		importRow := &proto.ImportRow{Row: int32(row)}
+		importRow.Voucher, importRow.Errors = parseVoucher(header, record)
+		if err := stream.Send(importRow); err != nil {
+			return err
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	for _, result := range res.Results {
+		if result.Ok {
+			fmt.Printf("Row %d: OK id=%d\n", result.Row, result.Id)
+			continue
+		}
+		for _, e := range result.Errors {
+			fmt.Printf("Row %d: %s %s\n", result.Row, e.Field, e.Description)
+		}
+	}
+	fmt.Printf("Imported: %d, failed: %d\n", res.Imported, res.Failed)
+	return nil
+}
+
+// parseVoucher doc cac cot theo ten trong header, cot khong biet (id, used...) bi bo qua.
+// Tra ve loi cua tung cot khong parse duoc, khi do voucher la nil.
+func parseVoucher(header []string, record []string) (*proto.VoucherReq, []*proto.FieldError) {
+	voucher := &proto.VoucherReq{Rule: &proto.Rule{}}
+	errs := []*proto.FieldError{}
+	for i, column := range header {
+		if i >= len(record) || record[i] == "" {
+			continue
+		}
+		value := record[i]
+		var err error
+		switch strings.TrimSpace(column) {
+		case "code":
+			voucher.Code = value
+		case "discount":
+			voucher.Discount, err = parseFloat(value)
+		case "start":
+			voucher.Start, err = parseTime(value)
+		case "end":
+			voucher.End, err = parseTime(value)
+		case "quota":
+			voucher.Quota, err = parseInt(value)
+		case "max_per_customer":
+			voucher.MaxPerCustomer, err = parseInt(value)
+		case "type":
+			voucher.Rule.Type, err = parseType(value)
+		case "discount_cap":
+			voucher.Rule.DiscountCap, err = parseFloat(value)
+		case "min_order":
+			voucher.Rule.MinOrder, err = parseFloat(value)
+		case "categories":
+			voucher.Rule.Categories = strings.Split(value, ";")
+		case "stackable":
+			voucher.Rule.Stackable, err = strconv.ParseBool(value)
+		}
+		if err != nil {
+			errs = append(errs, &proto.FieldError{Field: strings.TrimSpace(column), Description: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return voucher, nil
+}
+
+func parseType(value string) (proto.DiscountType, error) {
+	t, ok := proto.DiscountType_value[strings.ToUpper(value)]
+	if !ok {
+		return 0, fmt.Errorf("unknown discount type %q", value)
+	}
+	return proto.DiscountType(t), nil
+}
+
+func parseFloat(value string) (float32, error) {
+	f, err := strconv.ParseFloat(value, 32)
+	return float32(f), err
+}
+
+func parseInt(value string) (int32, error) {
+	i, err := strconv.ParseInt(value, 10, 32)
+	return int32(i), err
+}
+
+func parseTime(value string) (*timestamp.Timestamp, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &timestamp.Timestamp{Seconds: t.Unix()}, nil
+}
+
+func exportVouchers(client proto.VoucherServiceClient, file string, prefix string) error {
+	stream, err := client.ExportVouchers(context.TODO(), &proto.ExportReq{CodePrefix: prefix})
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	writer := csv.NewWriter(f)
+	count := 0
+	for {
+		row, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := writer.Write(row.Values); err != nil {
+			return err
+		}
+		count++
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	// Tru dong header
+	fmt.Printf("Exported: %d\n", count-1)
+	return nil
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"../model"
+	"../repo"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// NoteBatch dung cho client offline gui nhieu thay doi mot lan.
+// Loi cua tung op nam trong ket qua cua op do, response van la 200.
+func NoteBatch(c *gin.Context, noteRepo repo.NoteRepo) (*model.NoteBatchRes, error) {
+	form := model.NoteBatchForm{}
+	if err := c.ShouldBind(&form); err != nil {
+		return nil, err
+	}
+	res := &model.NoteBatchRes{
+		Atomic:  form.Atomic,
+		Results: make([]model.NoteBatchResult, len(form.Ops)),
+	}
+	// Validate Title cua create va update truoc, chi gui cac op hop le xuong repo
+	ops := []model.NoteBatchOp{}
+	indexes := []int{}
+	for i, op := range form.Ops {
+		res.Results[i].Op = op.Op
+		if err := validateBatchOp(op); err != nil {
+			res.Results[i].Err = err
+			continue
+		}
+		ops = append(ops, op)
+		indexes = append(indexes, i)
+	}
+	if form.Atomic && len(ops) < len(form.Ops) {
+		for _, i := range indexes {
+			res.Results[i].Err = repo.ErrBatchAborted
+		}
+		return batchResponse(res), nil
+	}
+	if len(ops) > 0 {
+		results, committed, err := noteRepo.Batch(ops, form.Atomic)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range indexes {
+			res.Results[i] = results[j]
+		}
+		res.Committed = committed
+	}
+	return batchResponse(res), nil
+}
+
+func validateBatchOp(op model.NoteBatchOp) error {
+	if op.Op != model.BATCH_CREATE && op.Op != model.BATCH_UPDATE {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(&model.Note{Title: op.Title})
+}
+
+// batchResponse doi loi cua tung op sang error envelope nhu khi goi endpoint rieng le
+func batchResponse(res *model.NoteBatchRes) *model.NoteBatchRes {
+	for i := range res.Results {
+		if res.Results[i].Err != nil {
+			res.Results[i].Error = toAppError(res.Results[i].Err)
+		}
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"../helper"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+var (
+	ErrNotFound        = helper.NewError(helper.KIND_NOT_FOUND, "NOT_FOUND", "Resource not found")
+	ErrInvalidRequest  = helper.NewError(helper.KIND_INVALID, "INVALID_REQUEST", "Request body or query is malformed")
+	ErrValidation      = helper.NewError(helper.KIND_INVALID, "VALIDATION_FAILED", "Request validation failed")
+	ErrUnauthorized    = helper.NewError(helper.KIND_UNAUTHORIZED, "UNAUTHORIZED", "Missing or invalid access token")
+	ErrForbidden       = helper.NewError(helper.KIND_FORBIDDEN, "FORBIDDEN", "You are not allowed to do this")
+	ErrIfMatchRequired = helper.NewError(helper.KIND_PRECONDITION_MISSING, "IF_MATCH_REQUIRED", "If-Match header is required")
+	ErrInternal        = helper.NewError(helper.KIND_INTERNAL, "INTERNAL", "Internal server error")
+)
+
+// KIND_STATUS map loai loi sang HTTP status
+var KIND_STATUS = map[string]int{
+	helper.KIND_INVALID:              http.StatusUnprocessableEntity,
+	helper.KIND_NOT_FOUND:            http.StatusNotFound,
+	helper.KIND_CONFLICT:             http.StatusConflict,
+	helper.KIND_UNAUTHORIZED:         http.StatusUnauthorized,
+	helper.KIND_FORBIDDEN:            http.StatusForbidden,
+	helper.KIND_PRECONDITION_FAILED:  http.StatusPreconditionFailed,
+	helper.KIND_PRECONDITION_MISSING: http.StatusPreconditionRequired,
+	helper.KIND_INTERNAL:             http.StatusInternalServerError,
+}
+
+// toAppError doi err bat ky thanh *helper.Error. Loi khong biet (vd loi SQL)
+// chi duoc log, client nhan ErrInternal de khong lo chi tiet ben trong.
+func toAppError(err error) *helper.Error {
+	switch e := err.(type) {
+	case *helper.Error:
+		return e
+	case validator.ValidationErrors:
+		return validationError(e)
+	case *json.SyntaxError, *json.UnmarshalTypeError, *time.ParseError, *strconv.NumError:
+		return ErrInvalidRequest
+	}
+	// Note cua user khac cung tra ve 404 de khong lo la note do co ton tai
+	if gorm.IsRecordNotFoundError(err) {
+		return ErrNotFound
+	}
+	// Body rong hoac bi cat giua chung
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrInvalidRequest
+	}
+	log.Printf("Unexpected error: %v", err)
+	return ErrInternal
+}
+
+func validationError(errs validator.ValidationErrors) *helper.Error {
+	res := *ErrValidation
+	res.Fields = make([]helper.FieldError, 0, len(errs))
+	for _, e := range errs {
+		res.Fields = append(res.Fields, helper.FieldError{
+			Field:   e.Field(),
+			Rule:    e.Tag(),
+			Param:   e.Param(),
+			Message: fieldMessage(e),
+		})
+	}
+	return &res
+}
+
+func fieldMessage(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return e.Field() + " is required"
+	case "min":
+		return e.Field() + " must be at least " + e.Param()
+	case "max":
+		return e.Field() + " must be at most " + e.Param()
+	case "oneof":
+		return e.Field() + " must be one of: " + e.Param()
+	case "email":
+		return e.Field() + " must be a valid email"
+	}
+	return e.Field() + " failed on the '" + e.Tag() + "' rule"
+}
+
+func abortWithError(c *gin.Context, err error) {
+	appErr := toAppError(err)
+	status, ok := KIND_STATUS[appErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	// Envelope chung cua moi response loi:
+	// {"error": {"code": "...", "message": "...", "fields": [...]}}
+	c.AbortWithStatusJSON(status, gin.H{
+		"error": appErr,
+	})
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"strconv"
+	"strings"
 
 	"../helper"
 	"../model"
@@ -35,11 +36,61 @@ func NoteGet(c *gin.Context, notePepo repo.NoteRepo) (*model.Note, error) {
 	return notePepo.Find(id)
 }
 
-func NoteList(c *gin.Context, notePepo repo.NoteRepo) ([]model.Note, error) {
+func NoteList(c *gin.Context, notePepo repo.NoteRepo, config helper.Config) (*model.NoteListRes, error) {
+	var filter model.NoteFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		return nil, err
+	}
+	var pagination helper.Pagination
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		return nil, err
+	}
+	pagination.Limit = pagination.GetLimit(config)
+	return notePepo.List(filter, pagination)
+}
+
+// NoteTrash giong NoteList nhung chi lay cac note da xoa
+func NoteTrash(c *gin.Context, notePepo repo.NoteRepo, config helper.Config) (*model.NoteListRes, error) {
+	var filter model.NoteFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		return nil, err
+	}
+	filter.Trashed = true
 	var pagination helper.Pagination
-	c.ShouldBindQuery(&pagination)
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		return nil, err
+	}
+	pagination.Limit = pagination.GetLimit(config)
+	return notePepo.List(filter, pagination)
+}
+
+// NoteShared giong NoteList nhung chi lay cac note user khac share cho minh
+func NoteShared(c *gin.Context, notePepo repo.NoteRepo, config helper.Config) (*model.NoteListRes, error) {
+	var filter model.NoteFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		return nil, err
+	}
+	filter.Shared = true
+	var pagination helper.Pagination
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		return nil, err
+	}
+	pagination.Limit = pagination.GetLimit(config)
+	return notePepo.List(filter, pagination)
+}
 
-	return notePepo.List(pagination)
+// NoteSearch tim note theo title, limit mac dinh va toi da giong NoteList
+func NoteSearch(c *gin.Context, notePepo repo.NoteRepo, config helper.Config) (*model.NoteSearchRes, error) {
+	var form model.NoteSearchForm
+	if err := c.ShouldBindQuery(&form); err != nil {
+		return nil, err
+	}
+	pagination := helper.Pagination{Limit: form.Limit}
+	hits, err := notePepo.Search(form.Q, pagination.GetLimit(config))
+	if err != nil {
+		return nil, err
+	}
+	return &model.NoteSearchRes{Data: hits}, nil
 }
 
 func NoteUpdate(c *gin.Context, notePepo repo.NoteRepo) error {
@@ -48,11 +99,117 @@ func NoteUpdate(c *gin.Context, notePepo repo.NoteRepo) error {
 	if err := c.ShouldBind(&note); err != nil {
 		return err
 	}
-	note.Title = "[Editted] " + note.Title
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	note.Version = version
 	return notePepo.Update(id, note)
 }
 
 func NoteDelete(c *gin.Context, notePepo repo.NoteRepo) error {
 	id, _ := strconv.Atoi(c.Param("id"))
-	return notePepo.Delete(id)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	return notePepo.Delete(id, version)
+}
+
+// noteETag la version cua note, client gui lai trong If-Match khi PUT/DELETE
+func noteETag(note *model.Note) string {
+	return `"` + strconv.Itoa(int(note.Version)) + `"`
+}
+
+// ifMatchVersion doc version tu If-Match: "3" hoac W/"3".
+// Khong nhan "*" vi se bo qua kiem tra version va ghi de thay doi cua client khac.
+func ifMatchVersion(c *gin.Context) (uint, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, ErrIfMatchRequired
+	}
+	ifMatch = strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	version, err := strconv.ParseUint(ifMatch, 10, 32)
+	if err != nil || version == 0 {
+		return 0, repo.ErrVersionMismatch
+	}
+	return uint(version), nil
+}
+
+func NoteRevisions(c *gin.Context, notePepo repo.NoteRepo) ([]model.NoteRevision, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	return notePepo.Revisions(id)
+}
+
+// NoteDiff so sanh revision from voi revision to (to = 0 la noi dung hien tai)
+func NoteDiff(c *gin.Context, notePepo repo.NoteRepo) (*model.NoteDiff, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	form := model.NoteDiffForm{}
+	if err := c.ShouldBindQuery(&form); err != nil {
+		return nil, err
+	}
+	from, err := notePepo.Revision(id, form.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := notePepo.Revision(id, form.To)
+	if err != nil {
+		return nil, err
+	}
+	diff := from.Diff(*to)
+	return &diff, nil
+}
+
+func NoteRestore(c *gin.Context, notePepo repo.NoteRepo) (*model.Note, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	rev, _ := strconv.Atoi(c.Param("rev"))
+	return notePepo.Restore(id, rev)
+}
+
+func NoteUndelete(c *gin.Context, notePepo repo.NoteRepo) (*model.Note, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	return notePepo.Undelete(id)
+}
+
+func NoteDeletePermanent(c *gin.Context, notePepo repo.NoteRepo) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	return notePepo.DeletePermanent(id)
+}
+
+func NoteAddTags(c *gin.Context, notePepo repo.NoteRepo) (*model.Note, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	form := model.NoteTagsForm{}
+	if err := c.ShouldBind(&form); err != nil {
+		return nil, err
+	}
+	return notePepo.AddTags(id, form.Tags)
+}
+
+func NoteRemoveTag(c *gin.Context, notePepo repo.NoteRepo) (*model.Note, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	return notePepo.RemoveTag(id, c.Param("tag"))
+}
+
+func NoteShare(c *gin.Context, notePepo repo.NoteRepo, userRepo repo.UserRepo) (*model.NoteShare, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	form := model.NoteShareForm{}
+	if err := c.ShouldBind(&form); err != nil {
+		return nil, err
+	}
+	user, err := userRepo.FindByUserLogin(form.Login)
+	if err != nil {
+		return nil, err
+	}
+	return notePepo.Share(id, user.ID, form.Permission)
+}
+
+func NoteUnshare(c *gin.Context, notePepo repo.NoteRepo) error {
+	id, _ := strconv.Atoi(c.Param("id"))
+	userID, _ := strconv.Atoi(c.Param("user_id"))
+	return notePepo.Unshare(id, uint(userID))
+}
+
+func NoteShares(c *gin.Context, notePepo repo.NoteRepo) ([]model.NoteAccess, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	return notePepo.Shares(id)
 }
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"../helper"
 	mock "../mock"
 	"../model"
 
@@ -166,17 +167,18 @@ func Test_NoteCreate_TitleMaxLengthWithCorrectError(t *testing.T) {
 
 func Test_NoteUpdate_TitleMaxLengthIsHitLimit(t *testing.T) {
 	gin.SetMode(gin.ReleaseMode)
-	// 1.1 "[Editted] " len=10 + 245 = 255 still ok
-	title := fakeString(245)
+	// 1.1 len = 255 still ok
+	title := fakeString(255)
 	id := 1
 	data := `{"title": "` + title + `","completed": false}`
 	ctx := buildMockContext("PUT", "/note/"+strconv.Itoa(id), data)
 	ctx.Params = append(ctx.Params, gin.Param{"id", strconv.Itoa(id)})
+	ctx.Request.Header.Set("If-Match", `"1"`)
 	// 1.2 Mock db
 	noteRepo := new(mock.NoteRepoImpl)
 	note := model.Note{}
 	json.Unmarshal([]byte(data), &note)
-	note.Title = "[Editted] " + note.Title
+	note.Version = 1
 	noteRepo.On("Update", id, note).Return(nil)
 	// 2  Mock function
 	err := NoteUpdate(ctx, noteRepo)
@@ -190,18 +192,23 @@ func Test_NoteUpdate_TitleMaxLengthIsHitLimit(t *testing.T) {
 // 2. Su dung tinh huong cua NoteUpdate de test giup cac ban de hieu hon
 func Test_NoteUpdate_TitleMaxLengthCorrectDBError(t *testing.T) {
 	gin.SetMode(gin.ReleaseMode)
-	// 1. Tinh huong test pass validation (<255)
-	// 2. Nhung sau do cai ham xu ly lam cho cai Title > 255
+	// 1. Tinh huong test pass validation (<=255)
+	// 2. Nhung DB van tra ve loi (vd column bi thu nho lai)
 	title := fakeString(255)
 	id := 1
 	data := `{"title": "` + title + `","completed": false}`
 	ctx := buildMockContext("PUT", "/note/"+strconv.Itoa(id), data)
 	ctx.Params = append(ctx.Params, gin.Param{"id", strconv.Itoa(id)})
+	ctx.Request.Header.Set("If-Match", `"1"`)
 	noteRepo := new(mock.NoteRepoImpl)
+	note := model.Note{}
+	json.Unmarshal([]byte(data), &note)
+	note.Version = 1
+	expectedErr := errors.New(`Error 1406: Data too long for column 'title' at row 1`)
+	noteRepo.On("Update", id, note).Return(expectedErr)
 	// 2  Mock function
 	err := NoteUpdate(ctx, noteRepo)
 	// 3. Kiem tra ket qua la dung nhu mong doi
-	expectedErr := errors.New(`Error 1406: Data too long for column 'title' at row 1`)
 	if err == nil || err.Error() != expectedErr.Error() {
 		t.Error("Expected error should be DB error")
 	}
@@ -247,3 +254,43 @@ func Test_NoteGet_With_Not_Found(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func Test_NoteList_LimitFromConfig(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	config := helper.Config{ListDefaultLimit: 10, ListMaxLimit: 50}
+	completed := true
+	for query, limit := range map[string]uint{"": 10, "&limit=20": 20, "&limit=500": 50} {
+		ctx := buildMockContext("GET", "/note?completed=true&title=buy&sort=-title"+query, "")
+		noteRepo := new(mock.NoteRepoImpl)
+		filter := model.NoteFilter{Completed: &completed, Title: "buy"}
+		pagination := helper.Pagination{Limit: limit, Sort: "-title"}
+		noteRepo.On("List", filter, pagination).Return(&model.NoteListRes{Total: 1}, nil)
+		res, err := NoteList(ctx, noteRepo, config)
+		if err != nil || res.Total != 1 {
+			t.Errorf("query %q: limit should be %d, err %v", query, limit, err)
+		}
+	}
+}
+
+func Test_NoteList_InvalidFilter(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	ctx := buildMockContext("GET", "/note?created_from=yesterday", "")
+	noteRepo := new(mock.NoteRepoImpl)
+	_, err := NoteList(ctx, noteRepo, helper.Config{ListDefaultLimit: 10, ListMaxLimit: 50})
+	if err == nil {
+		t.Error("Invalid time should be error")
+	}
+}
+
+func Test_NoteDiff_WithCurrent(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	ctx := buildMockContext("GET", "/note/7/diff?from=1", "")
+	ctx.Params = gin.Params{gin.Param{Key: "id", Value: "7"}}
+	noteRepo := new(mock.NoteRepoImpl)
+	noteRepo.On("Revision", 7, 1).Return(&model.NoteRevision{Rev: 1, Title: "old title"}, nil)
+	noteRepo.On("Revision", 7, 0).Return(&model.NoteRevision{Title: "new title"}, nil)
+	diff, err := NoteDiff(ctx, noteRepo)
+	if err != nil || len(diff.Changes) != 1 || diff.Changes[0].Field != "Title" {
+		t.Error("Diff should only contain title", diff, err)
+	}
+}
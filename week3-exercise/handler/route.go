@@ -2,17 +2,18 @@ package handler
 
 import (
 	"fmt"
+	"strconv"
 
+	"../helper"
+	"../model"
 	"../repo"
-	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/gorm"
 )
 
-var jwtSecretKey []byte = []byte("ThisIsAVerySecretKey")
 var identityKey = "identity"
 
-func InitRoutes(engine *gin.Engine, db *gorm.DB) {
+func InitRoutes(engine *gin.Engine, db *gorm.DB, config helper.Config) {
 	engine.GET("/ping", pingHandler)
 	engine.GET("/get-increment-id", func(c *gin.Context) {
 		settingRepository := &repo.SettingRepoImpl{
@@ -21,11 +22,13 @@ func InitRoutes(engine *gin.Engine, db *gorm.DB) {
 		result, err := GetIncrementId(c, settingRepository)
 		simpleReturnHandler(c, err, result)
 	})
-	initNoteRoutes(engine, db)
-	initUserRoutes(engine, db)
+	initNoteRoutes(engine, db, config)
+	initUserRoutes(engine, db, config)
+	initAdminRoutes(engine, db, config)
+	initTagRoutes(engine, db, config)
 }
 
-func initUserRoutes(engine *gin.Engine, db *gorm.DB) {
+func initUserRoutes(engine *gin.Engine, db *gorm.DB, config helper.Config) {
 	engine.POST("/signin", func(c *gin.Context) {
 		userRepository := &repo.UserRepoImpl{
 			DB: db,
@@ -37,47 +40,252 @@ func initUserRoutes(engine *gin.Engine, db *gorm.DB) {
 		userRepository := &repo.UserRepoImpl{
 			DB: db,
 		}
-		result, err := UserLogin(c, userRepository)
+		tokenRepository := &repo.TokenRepoImpl{
+			DB: db,
+		}
+		result, err := UserLogin(c, userRepository, tokenRepository, config)
+		simpleReturnHandler(c, err, result)
+	})
+	engine.POST("/token/refresh", func(c *gin.Context) {
+		tokenRepository := &repo.TokenRepoImpl{
+			DB: db,
+		}
+		userRepository := &repo.UserRepoImpl{
+			DB: db,
+		}
+		result, err := TokenRefresh(c, tokenRepository, userRepository, config)
 		simpleReturnHandler(c, err, result)
 	})
+	engine.POST("/logout", authenMiddleware(db, config), func(c *gin.Context) {
+		tokenRepository := &repo.TokenRepoImpl{
+			DB: db,
+		}
+		err := UserLogout(c, tokenRepository)
+		simpleReturnHandler(c, err, nil)
+	})
+}
+
+func initAdminRoutes(engine *gin.Engine, db *gorm.DB, config helper.Config) {
+	groupRouter := engine.Group("/admin")
+	groupRouter.Use(authenMiddleware(db, config), requireRole(model.ROLE_ADMIN))
+	{
+		groupRouter.GET("/users", func(c *gin.Context) {
+			userRepository := &repo.UserRepoImpl{
+				DB: db,
+			}
+			result, err := UserList(c, userRepository, config)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.PUT("/users/:id/role", func(c *gin.Context) {
+			userRepository := &repo.UserRepoImpl{
+				DB: db,
+			}
+			result, err := UserChangeRole(c, userRepository)
+			simpleReturnHandler(c, err, result)
+		})
+	}
+}
+
+func initTagRoutes(engine *gin.Engine, db *gorm.DB, config helper.Config) {
+	groupRouter := engine.Group("/tags")
+	groupRouter.Use(authenMiddleware(db, config))
+	{
+		groupRouter.GET("", func(c *gin.Context) {
+			tagRepository := &repo.TagRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			result, err := TagList(c, tagRepository)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.PUT("/:id", requireRole(model.ROLE_ADMIN, model.ROLE_EDITOR), func(c *gin.Context) {
+			tagRepository := &repo.TagRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			result, err := TagRename(c, tagRepository)
+			simpleReturnHandler(c, err, result)
+		})
+	}
 }
 
-func initNoteRoutes(engine *gin.Engine, db *gorm.DB) {
+func initNoteRoutes(engine *gin.Engine, db *gorm.DB, config helper.Config) {
 	groupRouter := engine.Group("/note")
 
 	// 1. Authentication // Identity
 	// 2. Lam logger/tracking
 	// 3. Recovery
 	// 4. Add nhieu cai middleware va no chay tuan tu
-	// groupRouter.Use(authenMiddleware)
+	groupRouter.Use(authenMiddleware(db, config))
+	// Viewer va support chi duoc doc
+	canWrite := requireRole(model.ROLE_ADMIN, model.ROLE_EDITOR)
+	// Index dung chung cho moi request
+	searchIndex := repo.NewSearchIndex(db)
 	{
+		groupRouter.GET("", func(c *gin.Context) {
+			noteRepository := &repo.NoteRepoImpl{
+				DB:      db,
+				UserID:  getUserID(c),
+				ReadAll: canReadAll(c),
+			}
+			result, err := NoteList(c, noteRepository, config)
+			simpleReturnHandler(c, err, result)
+		})
 		groupRouter.GET("/:id", func(c *gin.Context) {
 			noteRepository := &repo.NoteRepoImpl{
-				DB: db,
+				DB:     db,
+				UserID: getUserID(c),
 			}
+			// Gin khong cho dang ky /trash, /shared va /search cung cap voi /:id
+			switch c.Param("id") {
+			case "search":
+				noteRepository.ReadAll = canReadAll(c)
+				noteRepository.Index = searchIndex
+				result, err := NoteSearch(c, noteRepository, config)
+				simpleReturnHandler(c, err, result)
+				return
+			case "trash":
+				result, err := NoteTrash(c, noteRepository, config)
+				simpleReturnHandler(c, err, result)
+				return
+			case "shared":
+				result, err := NoteShared(c, noteRepository, config)
+				simpleReturnHandler(c, err, result)
+				return
+			}
+			noteRepository.ReadAll = canReadAll(c)
 			result, err := NoteGet(c, noteRepository)
+			if err == nil {
+				c.Header("ETag", noteETag(result))
+			}
 			simpleReturnHandler(c, err, result)
 		})
-		groupRouter.POST("", func(c *gin.Context) {
-			// 1. Repo
+		groupRouter.GET("/:id/revisions", func(c *gin.Context) {
+			noteRepository := &repo.NoteRepoImpl{
+				DB:      db,
+				UserID:  getUserID(c),
+				ReadAll: canReadAll(c),
+			}
+			result, err := NoteRevisions(c, noteRepository)
+			simpleReturnHandler(c, err, result)
+		})
+		// Khong dat duoi /revisions vi se trung voi /revisions/:rev
+		groupRouter.GET("/:id/diff", func(c *gin.Context) {
+			noteRepository := &repo.NoteRepoImpl{
+				DB:      db,
+				UserID:  getUserID(c),
+				ReadAll: canReadAll(c),
+			}
+			result, err := NoteDiff(c, noteRepository)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.POST("/:id/revisions/:rev/restore", canWrite, func(c *gin.Context) {
 			repo := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			result, err := NoteRestore(c, repo)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.POST("/:id/restore", canWrite, func(c *gin.Context) {
+			repo := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			result, err := NoteUndelete(c, repo)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.DELETE("/:id/permanent", canWrite, func(c *gin.Context) {
+			repo := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+				Index:  searchIndex,
+			}
+			err := NoteDeletePermanent(c, repo)
+			simpleReturnHandler(c, err, nil)
+		})
+		groupRouter.POST("/:id/tags", canWrite, func(c *gin.Context) {
+			repo := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			result, err := NoteAddTags(c, repo)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.DELETE("/:id/tags/:tag", canWrite, func(c *gin.Context) {
+			repo := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			result, err := NoteRemoveTag(c, repo)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.GET("/:id/shares", func(c *gin.Context) {
+			noteRepository := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			result, err := NoteShares(c, noteRepository)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.POST("/:id/shares", canWrite, func(c *gin.Context) {
+			noteRepository := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			userRepository := &repo.UserRepoImpl{
 				DB: db,
 			}
+			result, err := NoteShare(c, noteRepository, userRepository)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.DELETE("/:id/shares/:user_id", canWrite, func(c *gin.Context) {
+			repo := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			err := NoteUnshare(c, repo)
+			simpleReturnHandler(c, err, nil)
+		})
+		groupRouter.POST("", canWrite, func(c *gin.Context) {
+			// 1. Repo
+			repo := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
 			// 2. Create note
 			result, err := NoteCreate(c, repo)
+			if err == nil {
+				c.Header("ETag", noteETag(result))
+			}
 			// 3. Handle result & err
 			simpleReturnHandler(c, err, result)
 		})
-		groupRouter.PUT("/:id", func(c *gin.Context) {
+		// Gin khong cho dang ky /batch cung cap voi /:id/...
+		groupRouter.POST("/:id", canWrite, func(c *gin.Context) {
+			if c.Param("id") != "batch" {
+				abortWithError(c, ErrNotFound)
+				return
+			}
+			noteRepository := &repo.NoteRepoImpl{
+				DB:     db,
+				UserID: getUserID(c),
+			}
+			result, err := NoteBatch(c, noteRepository)
+			simpleReturnHandler(c, err, result)
+		})
+		groupRouter.PUT("/:id", canWrite, requireIfMatch, func(c *gin.Context) {
 			repo := &repo.NoteRepoImpl{
-				DB: db,
+				DB:     db,
+				UserID: getUserID(c),
 			}
 			err := NoteUpdate(c, repo)
 			simpleReturnHandler(c, err, nil)
 		})
-		groupRouter.DELETE("/:id", func(c *gin.Context) {
+		groupRouter.DELETE("/:id", canWrite, requireIfMatch, func(c *gin.Context) {
 			repo := &repo.NoteRepoImpl{
-				DB: db,
+				DB:     db,
+				UserID: getUserID(c),
 			}
 			err := NoteDelete(c, repo)
 			simpleReturnHandler(c, err, nil)
@@ -87,9 +295,7 @@ func initNoteRoutes(engine *gin.Engine, db *gorm.DB) {
 
 func simpleReturnHandler(c *gin.Context, err error, result interface{}) {
 	if err != nil {
-		c.AbortWithStatusJSON(400, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(200, result)
@@ -107,30 +313,67 @@ func simpleMiddleware(c *gin.Context) {
 	c.Next()
 }
 
-func authenMiddleware(c *gin.Context) {
-
-	tokenString := c.GetHeader("Authentication")
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.StandardClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+// authenMiddleware verify access token theo kid, sau do kiem tra session
+// cua token chua bi revoke (logout)
+func authenMiddleware(db *gorm.DB, config helper.Config) gin.HandlerFunc {
+	tokenRepository := &repo.TokenRepoImpl{
+		DB: db,
+	}
+	return func(c *gin.Context) {
+		tokenString := c.GetHeader("Authentication")
+		claims, err := parseAccessToken(config, tokenString)
+		if err != nil {
+			abortWithError(c, ErrUnauthorized)
+			return
 		}
+		revoked, err := tokenRepository.IsRevoked(claims.Id)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if revoked {
+			abortWithError(c, ErrUnauthorized)
+			return
+		}
+		c.Set(identityKey, claims.Subject)
+		c.Set(sessionKey, claims.Id)
+		c.Set(roleKey, claims.Role)
+		c.Next()
+	}
+}
 
-		// hmacSampleSecret is a []byte containing your secret, e.g. []byte("my_secret_key")
-		return jwtSecretKey, nil
-	})
+// getUserID lay identity ma authenMiddleware da set vao context
+func getUserID(c *gin.Context) uint {
+	id, _ := strconv.ParseUint(c.GetString(identityKey), 10, 32)
+	return uint(id)
+}
 
-	if err != nil {
-		c.AbortWithStatus(401)
-		return
+// requireRole dat sau authenMiddleware, role khong nam trong roles thi tra ve 403
+func requireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(roleKey)
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		abortWithError(c, ErrForbidden)
 	}
+}
 
-	claims, ok := token.Claims.(*jwt.StandardClaims)
-	fmt.Println("jwt-claims:", claims)
-	if ok && claims.Valid() == nil {
-		c.Set(identityKey, claims.Id)
-		c.Next()
+// canReadAll la cac role duoc doc note cua tat ca user
+func canReadAll(c *gin.Context) bool {
+	role := c.GetString(roleKey)
+	return role == model.ROLE_ADMIN || role == model.ROLE_SUPPORT
+}
+
+// requireIfMatch bat client gui If-Match la ETag lay tu GET /note/:id ("*" khong duoc nhan)
+// de khong ghi de len thay doi cua client khac
+func requireIfMatch(c *gin.Context) {
+	if _, err := ifMatchVersion(c); err == ErrIfMatchRequired {
+		abortWithError(c, ErrIfMatchRequired)
 		return
 	}
-	c.AbortWithStatus(401)
+	c.Next()
 }
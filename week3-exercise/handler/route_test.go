@@ -0,0 +1,524 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"../helper"
+	"../model"
+	"../repo"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+var testConfig = helper.Config{
+	ListDefaultLimit: 10,
+	ListMaxLimit:     100,
+	JwtKeys:          []helper.JwtKey{{Kid: "test", Secret: []byte("test-secret")}},
+	AccessTokenTTL:   time.Minute,
+	RefreshTokenTTL:  time.Hour,
+}
+
+func newTestEngine(t *testing.T) (*gin.Engine, *gorm.DB) {
+	gin.SetMode(gin.ReleaseMode)
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.DB().SetMaxOpenConns(1)
+	db.AutoMigrate(&model.Note{}, &model.User{}, &model.Setting{}, &model.Session{}, &model.RefreshToken{}, &model.NoteRevision{}, &model.Tag{}, &model.NoteShare{})
+	engine := gin.New()
+	InitRoutes(engine, db, testConfig)
+	return engine, db
+}
+
+// testToken tao session that trong db roi tra ve access token cua session do
+func testToken(t *testing.T, db *gorm.DB, userID uint, role string) string {
+	user := model.User{Role: role}
+	user.ID = userID
+	tokens, err := issueTokens(&repo.TokenRepoImpl{DB: db}, testConfig, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens.Token
+}
+
+// headers la cac cap key, value
+func doRequest(engine *gin.Engine, method string, path string, token string, data string, headers ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	if token != "" {
+		req.Header.Set("Authentication", token)
+	}
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func Test_NoteRoutes_Owner(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	note := model.Note{Title: "alice note", UserID: 1}
+	db.Create(&note)
+	path := "/note/" + strconv.Itoa(int(note.ID))
+
+	if w := doRequest(engine, "GET", path, "", ""); w.Code != http.StatusUnauthorized {
+		t.Error("Request without token should be 401, actual", w.Code)
+	}
+	if w := doRequest(engine, "GET", path, testToken(t, db, 1, model.ROLE_EDITOR), ""); w.Code != http.StatusOK {
+		t.Error("Owner should get note, actual", w.Code)
+	}
+	bob := testToken(t, db, 2, model.ROLE_EDITOR)
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		w := doRequest(engine, method, path, bob, `{"title": "bob edit"}`, "If-Match", `"1"`)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s other user's note should be 404, actual %d", method, w.Code)
+		}
+	}
+	w := doRequest(engine, "POST", "/note", bob, `{"title": "bob note", "UserID": 1}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"UserID":2`) {
+		t.Error("Created note should belong to token user", w.Code, w.Body.String())
+	}
+}
+
+func Test_TokenRoutes_RefreshAndLogout(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	w := doRequest(engine, "POST", "/signin", "", `{"username": "alice", "email": "alice@example.com", "password": "123456"}`)
+	if w.Code != http.StatusOK {
+		t.Fatal("Signin failed", w.Body.String())
+	}
+	login := model.UserLoginReponse{}
+	w = doRequest(engine, "POST", "/login", "", `{"login": "alice", "password": "123456"}`)
+	json.Unmarshal(w.Body.Bytes(), &login)
+	if w.Code != http.StatusOK || login.Token == "" || login.RefreshToken == "" {
+		t.Fatal("Login should return access and refresh token", w.Body.String())
+	}
+	if login.ExpiresAt.After(time.Now().Add(testConfig.AccessTokenTTL)) {
+		t.Error("Access token should expire after AccessTokenTTL")
+	}
+
+	// Refresh token chi dung duoc mot lan
+	refreshed := model.TokenResponse{}
+	w = doRequest(engine, "POST", "/token/refresh", "", `{"refreshToken": "`+login.RefreshToken+`"}`)
+	json.Unmarshal(w.Body.Bytes(), &refreshed)
+	if w.Code != http.StatusOK || refreshed.RefreshToken == login.RefreshToken {
+		t.Fatal("Refresh should rotate refresh token", w.Body.String())
+	}
+	if w := doRequest(engine, "GET", "/note", refreshed.Token, ""); w.Code != http.StatusOK {
+		t.Error("New access token should work, actual", w.Code)
+	}
+	// Dung lai token cu la dau hieu bi lo, ca session bi revoke
+	if w := doRequest(engine, "POST", "/token/refresh", "", `{"refreshToken": "`+login.RefreshToken+`"}`); w.Code != http.StatusUnauthorized {
+		t.Error("Reused refresh token should be 401, actual", w.Code)
+	}
+	if w := doRequest(engine, "GET", "/note", refreshed.Token, ""); w.Code != http.StatusUnauthorized {
+		t.Error("Access token of revoked session should be 401, actual", w.Code)
+	}
+	if w := doRequest(engine, "POST", "/token/refresh", "", `{"refreshToken": "`+refreshed.RefreshToken+`"}`); w.Code != http.StatusUnauthorized {
+		t.Error("Refresh token of revoked session should be 401, actual", w.Code)
+	}
+
+	// Logout revoke access token ngay lap tuc
+	token := testToken(t, db, 1, model.ROLE_EDITOR)
+	if w := doRequest(engine, "POST", "/logout", token, ""); w.Code != http.StatusOK {
+		t.Fatal("Logout failed", w.Code)
+	}
+	if w := doRequest(engine, "GET", "/note", token, ""); w.Code != http.StatusUnauthorized {
+		t.Error("Access token after logout should be 401, actual", w.Code)
+	}
+}
+
+func Test_AuthenMiddleware_KeyRotation(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	session := model.Session{ID: "rotation", UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	db.Create(&session)
+	sign := func(kid string, secret string) string {
+		claims := &accessClaims{
+			Role:           model.ROLE_EDITOR,
+			StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix(), Subject: "1", Id: session.ID},
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = kid
+		tokenString, _ := token.SignedString([]byte(secret))
+		return tokenString
+	}
+	if w := doRequest(engine, "GET", "/note", sign("old", "old-secret"), ""); w.Code != http.StatusUnauthorized {
+		t.Error("Unknown kid should be 401, actual", w.Code)
+	}
+	if w := doRequest(engine, "GET", "/note", sign("test", "wrong-secret"), ""); w.Code != http.StatusUnauthorized {
+		t.Error("Wrong secret should be 401, actual", w.Code)
+	}
+	// Key cu van verify duoc sau khi them key moi len dau
+	rotated := gin.New()
+	config := testConfig
+	config.JwtKeys = append([]helper.JwtKey{{Kid: "new", Secret: []byte("new-secret")}}, testConfig.JwtKeys...)
+	InitRoutes(rotated, db, config)
+	for _, token := range []string{sign("test", "test-secret"), sign("new", "new-secret")} {
+		if w := doRequest(rotated, "GET", "/note", token, ""); w.Code != http.StatusOK {
+			t.Error("Token signed by any configured key should work, actual", w.Code)
+		}
+	}
+}
+
+func Test_NoteRoutes_Role(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	note := model.Note{Title: "alice note", UserID: 1}
+	db.Create(&note)
+	path := "/note/" + strconv.Itoa(int(note.ID))
+	cases := []struct {
+		role  string
+		read  int
+		list  int
+		write int
+	}{
+		// Role nao cung doc duoc note cua minh, chi admin va support doc duoc note cua user khac
+		{model.ROLE_EDITOR, http.StatusNotFound, 0, http.StatusOK},
+		{model.ROLE_VIEWER, http.StatusNotFound, 0, http.StatusForbidden},
+		{model.ROLE_SUPPORT, http.StatusOK, 1, http.StatusForbidden},
+		{model.ROLE_ADMIN, http.StatusOK, 1, http.StatusOK},
+	}
+	for _, c := range cases {
+		token := testToken(t, db, 2, c.role)
+		if w := doRequest(engine, "GET", path, token, ""); w.Code != c.read {
+			t.Errorf("%s: GET other user's note should be %d, actual %d", c.role, c.read, w.Code)
+		}
+		res := model.NoteListRes{}
+		w := doRequest(engine, "GET", "/note?title=alice", token, "")
+		json.Unmarshal(w.Body.Bytes(), &res)
+		if res.Total != c.list {
+			t.Errorf("%s: list should contain %d note of other user, actual %d", c.role, c.list, res.Total)
+		}
+		if w := doRequest(engine, "POST", "/note", token, `{"title": "own note"}`); w.Code != c.write {
+			t.Errorf("%s: POST should be %d, actual %d", c.role, c.write, w.Code)
+		}
+		// Doc duoc nhung khong sua duoc note cua user khac
+		if w := doRequest(engine, "PUT", path, token, `{"title": "edit"}`, "If-Match", `"1"`); w.Code == http.StatusOK {
+			t.Errorf("%s: should not update other user's note", c.role)
+		}
+	}
+}
+
+func Test_AdminRoutes(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	for _, username := range []string{"admin", "alice", "bob"} {
+		w := doRequest(engine, "POST", "/signin", "", `{"username": "`+username+`", "email": "`+username+`@example.com", "password": "123456", "role": "admin"}`)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"Role":"editor"`) {
+			t.Fatal("Signin should always create editor", w.Body.String())
+		}
+	}
+	db.Model(&model.User{}).Where("username = ?", "admin").Update("role", model.ROLE_ADMIN)
+	admin := testToken(t, db, 1, model.ROLE_ADMIN)
+	if w := doRequest(engine, "GET", "/admin/users", testToken(t, db, 2, model.ROLE_EDITOR), ""); w.Code != http.StatusForbidden {
+		t.Error("Non admin should be 403, actual", w.Code)
+	}
+
+	res := model.UserListRes{}
+	w := doRequest(engine, "GET", "/admin/users?limit=2", admin, "")
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if len(res.Data) != 2 || res.NextCursor == "" || strings.Contains(w.Body.String(), "Password") {
+		t.Fatal("First page should have 2 users without password", w.Body.String())
+	}
+	w = doRequest(engine, "GET", "/admin/users?limit=2&cursor="+res.NextCursor, admin, "")
+	res = model.UserListRes{}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if len(res.Data) != 1 || res.Data[0].Username != "bob" || res.NextCursor != "" {
+		t.Error("Second page should have only bob", w.Body.String())
+	}
+
+	if w := doRequest(engine, "PUT", "/admin/users/2/role", admin, `{"role": "owner"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Error("Unknown role should be 422, actual", w.Code)
+	}
+	if w := doRequest(engine, "PUT", "/admin/users/1/role", admin, `{"role": "viewer"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Error("Admin should not change own role, actual", w.Code)
+	}
+	if w := doRequest(engine, "PUT", "/admin/users/99/role", admin, `{"role": "viewer"}`); w.Code != http.StatusNotFound {
+		t.Error("Unknown user should be 404, actual", w.Code)
+	}
+	old := testToken(t, db, 2, model.ROLE_EDITOR)
+	w = doRequest(engine, "PUT", "/admin/users/2/role", admin, `{"role": "support"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"Role":"support"`) {
+		t.Error("Admin should change role", w.Body.String())
+	}
+	// Token mang role cu bi revoke ngay, khong doi toi khi het han
+	if w := doRequest(engine, "GET", "/note", old, ""); w.Code != http.StatusUnauthorized {
+		t.Error("Token with old role should be revoked, actual", w.Code)
+	}
+
+	login := model.UserLoginReponse{}
+	w = doRequest(engine, "POST", "/login", "", `{"login": "alice", "password": "123456"}`)
+	json.Unmarshal(w.Body.Bytes(), &login)
+	if login.Role != model.ROLE_SUPPORT {
+		t.Error("Login should return new role", w.Body.String())
+	}
+	// Refresh token cung bi revoke khi doi role
+	w = doRequest(engine, "PUT", "/admin/users/2/role", admin, `{"role": "viewer"}`)
+	if w := doRequest(engine, "POST", "/token/refresh", "", `{"refreshToken": "`+login.RefreshToken+`"}`); w.Code != http.StatusUnauthorized {
+		t.Error("Refresh token of demoted user should be revoked, actual", w.Code)
+	}
+	// Role doi thang trong db (khong qua UpdateRole) co trong token sau khi refresh
+	w = doRequest(engine, "POST", "/login", "", `{"login": "alice", "password": "123456"}`)
+	json.Unmarshal(w.Body.Bytes(), &login)
+	db.Model(&model.User{}).Where("username = ?", "alice").Update("role", model.ROLE_EDITOR)
+	refreshed := model.TokenResponse{}
+	w = doRequest(engine, "POST", "/token/refresh", "", `{"refreshToken": "`+login.RefreshToken+`"}`)
+	json.Unmarshal(w.Body.Bytes(), &refreshed)
+	claims, err := parseAccessToken(testConfig, refreshed.Token)
+	if err != nil || claims.Role != model.ROLE_EDITOR {
+		t.Error("Refreshed token should have role from db", err)
+	}
+}
+
+func Test_NoteRoutes_Trash(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	token := testToken(t, db, 1, model.ROLE_EDITOR)
+	note := model.Note{Title: "alice note", UserID: 1}
+	db.Create(&note)
+	path := "/note/" + strconv.Itoa(int(note.ID))
+	doRequest(engine, "DELETE", path, token, "", "If-Match", `"1"`)
+
+	res := model.NoteListRes{}
+	w := doRequest(engine, "GET", "/note/trash", token, "")
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusOK || res.Total != 1 {
+		t.Fatal("Trash should contain deleted note", w.Body.String())
+	}
+	if w := doRequest(engine, "POST", path+"/restore", token, ""); w.Code != http.StatusOK {
+		t.Error("Restore should be 200, actual", w.Code)
+	}
+	if w := doRequest(engine, "DELETE", path+"/permanent", token, ""); w.Code != http.StatusOK {
+		t.Error("Permanent delete should be 200, actual", w.Code)
+	}
+	if w := doRequest(engine, "POST", path+"/restore", token, ""); w.Code != http.StatusNotFound {
+		t.Error("Permanently deleted note should be 404, actual", w.Code)
+	}
+}
+
+func Test_NoteRoutes_Share(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	for _, username := range []string{"alice", "bob"} {
+		db.Create(&model.User{Username: username, Email: username + "@example.com", Role: model.ROLE_EDITOR})
+	}
+	alice := testToken(t, db, 1, model.ROLE_EDITOR)
+	bob := testToken(t, db, 2, model.ROLE_EDITOR)
+	note := model.Note{Title: "alice note", UserID: 1}
+	db.Create(&note)
+	path := "/note/" + strconv.Itoa(int(note.ID))
+
+	if w := doRequest(engine, "POST", path+"/shares", alice, `{"login": "nobody", "permission": "view"}`); w.Code != http.StatusNotFound {
+		t.Error("Share with unknown user should be 404, actual", w.Code)
+	}
+	if w := doRequest(engine, "POST", path+"/shares", alice, `{"login": "bob@example.com", "permission": "view"}`); w.Code != http.StatusOK {
+		t.Fatal("Share should be 200, actual", w.Code, w.Body.String())
+	}
+	res := model.NoteListRes{}
+	w := doRequest(engine, "GET", "/note/shared", bob, "")
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if res.Total != 1 {
+		t.Error("Shared with me should contain note", w.Body.String())
+	}
+	if w := doRequest(engine, "GET", path, bob, ""); w.Code != http.StatusOK {
+		t.Error("Viewer should get shared note, actual", w.Code)
+	}
+	if w := doRequest(engine, "PUT", path, bob, `{"title": "bob edit"}`, "If-Match", `"1"`); w.Code != http.StatusNotFound {
+		t.Error("Viewer should not update shared note, actual", w.Code)
+	}
+	if w := doRequest(engine, "DELETE", path+"/shares/2", alice, ""); w.Code != http.StatusOK {
+		t.Error("Revoke should be 200, actual", w.Code)
+	}
+	if w := doRequest(engine, "GET", path, bob, ""); w.Code != http.StatusNotFound {
+		t.Error("Revoked user should get 404, actual", w.Code)
+	}
+}
+
+func Test_NoteRoutes_IfMatch(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	token := testToken(t, db, 1, model.ROLE_EDITOR)
+	w := doRequest(engine, "POST", "/note", token, `{"title": "alice note"}`)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag != `"1"` {
+		t.Fatal("Create should return ETag of version 1", w.Code, etag)
+	}
+	note := model.Note{}
+	json.Unmarshal(w.Body.Bytes(), &note)
+	path := "/note/" + strconv.Itoa(int(note.ID))
+
+	if w := doRequest(engine, "PUT", path, token, `{"title": "no etag"}`); w.Code != http.StatusPreconditionRequired {
+		t.Error("PUT without If-Match should be 428, actual", w.Code)
+	}
+	if w := doRequest(engine, "DELETE", path, token, ""); w.Code != http.StatusPreconditionRequired {
+		t.Error("DELETE without If-Match should be 428, actual", w.Code)
+	}
+	// Client 1 sua truoc, client 2 van giu ETag cu
+	if w := doRequest(engine, "PUT", path, token, `{"title": "client 1"}`, "If-Match", "*"); w.Code != http.StatusPreconditionRequired {
+		t.Error("PUT with If-Match * should be 428, actual", w.Code)
+	}
+	if w := doRequest(engine, "PUT", path, token, `{"title": "client 1", "completed": true}`, "If-Match", etag); w.Code != http.StatusOK {
+		t.Fatal("PUT with current ETag should be 200, actual", w.Code)
+	}
+	if w := doRequest(engine, "PUT", path, token, `{"title": "client 2"}`, "If-Match", etag); w.Code != http.StatusPreconditionFailed {
+		t.Error("PUT with old ETag should be 412, actual", w.Code)
+	}
+	if w := doRequest(engine, "DELETE", path, token, "", "If-Match", etag); w.Code != http.StatusPreconditionFailed {
+		t.Error("DELETE with old ETag should be 412, actual", w.Code)
+	}
+	if w := doRequest(engine, "PUT", path, token, `{"title": "client 2"}`, "If-Match", `"abc"`); w.Code != http.StatusPreconditionFailed {
+		t.Error("Invalid If-Match should be 412, actual", w.Code)
+	}
+
+	w = doRequest(engine, "GET", path, token, "")
+	etag = w.Header().Get("ETag")
+	json.Unmarshal(w.Body.Bytes(), &note)
+	if etag != `"2"` || note.Title != "client 1" || !note.Completed {
+		t.Fatal("GET should return new ETag and content of client 1", etag, note)
+	}
+	// Completed = false cung phai duoc luu
+	if w := doRequest(engine, "PUT", path, token, `{"title": "client 2", "completed": false}`, "If-Match", "W/"+etag); w.Code != http.StatusOK {
+		t.Error("PUT with weak ETag should be 200, actual", w.Code)
+	}
+	w = doRequest(engine, "GET", path, token, "")
+	json.Unmarshal(w.Body.Bytes(), &note)
+	if w.Header().Get("ETag") != `"3"` || note.Completed {
+		t.Error("PUT should save completed = false", note)
+	}
+	if w := doRequest(engine, "DELETE", path, token, "", "If-Match", `"3"`); w.Code != http.StatusOK {
+		t.Error("DELETE with current ETag should be 200, actual", w.Code)
+	}
+}
+
+func Test_Routes_ErrorEnvelope(t *testing.T) {
+	engine, db := newTestEngine(t)
+	signin := `{"username": "alice", "email": "alice@example.com", "password": "123456"}`
+	if w := doRequest(engine, "POST", "/signin", "", signin); w.Code != http.StatusOK {
+		t.Fatal("Signin should be ok", w.Body.String())
+	}
+
+	type envelope struct {
+		Error struct {
+			Code    string
+			Message string
+			Fields  []helper.FieldError
+		}
+	}
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		data   string
+		status int
+		code   string
+	}{
+		{"Duplicate username", "POST", "/signin", "", signin, http.StatusConflict, "USER_EXISTS"},
+		{"Wrong password", "POST", "/login", "", `{"login": "alice", "password": "654321"}`, http.StatusUnauthorized, "INVALID_CREDENTIALS"},
+		{"Unknown user", "POST", "/login", "", `{"login": "nobody", "password": "123456"}`, http.StatusUnauthorized, "INVALID_CREDENTIALS"},
+		{"Missing token", "GET", "/note", "", "", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"Unknown note", "GET", "/note/99", testToken(t, db, 1, model.ROLE_EDITOR), "", http.StatusNotFound, "NOT_FOUND"},
+		{"Malformed body", "POST", "/note", testToken(t, db, 1, model.ROLE_EDITOR), `{"title": `, http.StatusUnprocessableEntity, "INVALID_REQUEST"},
+		{"Invalid sort", "GET", "/note?sort=password", testToken(t, db, 1, model.ROLE_EDITOR), "", http.StatusUnprocessableEntity, "INVALID_SORT"},
+	}
+	for _, tc := range cases {
+		w := doRequest(engine, tc.method, tc.path, tc.token, tc.data)
+		res := envelope{}
+		json.Unmarshal(w.Body.Bytes(), &res)
+		if w.Code != tc.status || res.Error.Code != tc.code || res.Error.Message == "" {
+			t.Errorf("%s should be %d %s, actual %d %s", tc.name, tc.status, tc.code, w.Code, w.Body.String())
+		}
+	}
+
+	w := doRequest(engine, "POST", "/note", testToken(t, db, 1, model.ROLE_EDITOR), `{"title": "ab"}`)
+	res := envelope{}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusUnprocessableEntity || res.Error.Code != "VALIDATION_FAILED" || len(res.Error.Fields) != 1 {
+		t.Fatal("Invalid title should be 422 with field details", w.Body.String())
+	}
+	if field := res.Error.Fields[0]; field.Field != "Title" || field.Rule != "min" || field.Param != "3" {
+		t.Error("Field details should point to Title min 3", field)
+	}
+}
+
+func Test_NoteRoutes_Batch(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	note := model.Note{Title: "alice note", UserID: 1, Version: 1}
+	db.Create(&note)
+	id := strconv.Itoa(int(note.ID))
+	alice := testToken(t, db, 1, model.ROLE_EDITOR)
+
+	if w := doRequest(engine, "POST", "/note/batch", testToken(t, db, 2, model.ROLE_VIEWER), `{"ops": [{"op": "create", "title": "viewer"}]}`); w.Code != http.StatusForbidden {
+		t.Error("Viewer should not run batch, actual", w.Code)
+	}
+	if w := doRequest(engine, "POST", "/note/batch", alice, `{"ops": [{"op": "archive", "id": `+id+`}]}`); w.Code != http.StatusUnprocessableEntity {
+		t.Error("Unknown op should be 422, actual", w.Code)
+	}
+
+	ops := `[{"op": "create", "title": "ab"}, {"op": "complete", "id": ` + id + `, "version": 1}]`
+	res := model.NoteBatchRes{}
+	w := doRequest(engine, "POST", "/note/batch", alice, `{"atomic": true, "ops": `+ops+`}`)
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusOK || res.Committed || len(res.Results) != 2 {
+		t.Fatal("Atomic batch with invalid op should not be committed", w.Body.String())
+	}
+	if res.Results[0].Error.Code != "VALIDATION_FAILED" || len(res.Results[0].Error.Fields) != 1 || res.Results[1].Error.Code != "BATCH_ABORTED" {
+		t.Error("Results should have error of each op", w.Body.String())
+	}
+	found := model.Note{}
+	if db.First(&found, note.ID); found.Completed {
+		t.Error("Note should not be completed")
+	}
+
+	res = model.NoteBatchRes{}
+	w = doRequest(engine, "POST", "/note/batch", alice, `{"ops": `+ops+`}`)
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusOK || !res.Committed || res.Results[0].OK || !res.Results[1].OK || !res.Results[1].Note.Completed {
+		t.Fatal("Best-effort batch should run valid ops", w.Body.String())
+	}
+
+	res = model.NoteBatchRes{}
+	w = doRequest(engine, "POST", "/note/batch", alice, `{"ops": [{"op": "delete", "id": `+id+`}]}`)
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if res.Results[0].OK || res.Results[0].Error.Code != "IF_MATCH_REQUIRED" {
+		t.Error("Delete without version should be rejected", w.Body.String())
+	}
+}
+
+func Test_NoteRoutes_Search(t *testing.T) {
+	engine, db := newTestEngine(t)
+	defer db.Close()
+	db.Create(&model.Note{Title: "alice milk", UserID: 1})
+	db.Create(&model.Note{Title: "bob milk", UserID: 2})
+	alice := testToken(t, db, 1, model.ROLE_EDITOR)
+
+	if w := doRequest(engine, "GET", "/note/search", alice, ""); w.Code != http.StatusUnprocessableEntity {
+		t.Error("Search without q should be 422, actual", w.Code)
+	}
+	res := model.NoteSearchRes{}
+	w := doRequest(engine, "GET", "/note/search?q=milk", alice, "")
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusOK || len(res.Data) != 1 || res.Data[0].Snippets[0].Text != "alice <mark>milk</mark>" {
+		t.Fatal("Editor should only find own note", w.Body.String())
+	}
+	res = model.NoteSearchRes{}
+	w = doRequest(engine, "GET", "/note/search?q=milk", testToken(t, db, 3, model.ROLE_SUPPORT), "")
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if len(res.Data) != 2 {
+		t.Error("Support should find all notes", w.Body.String())
+	}
+}
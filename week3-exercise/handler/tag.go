@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"strconv"
+
+	"../model"
+	"../repo"
+	"github.com/gin-gonic/gin"
+)
+
+func TagList(c *gin.Context, tagRepo repo.TagRepo) ([]model.TagCount, error) {
+	return tagRepo.List()
+}
+
+// TagRename doi ten tag, trung ten voi tag khac thi gop hai tag
+func TagRename(c *gin.Context, tagRepo repo.TagRepo) (*model.Tag, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	form := model.TagRenameForm{}
+	if err := c.ShouldBind(&form); err != nil {
+		return nil, err
+	}
+	return tagRepo.Rename(id, form.Name)
+}
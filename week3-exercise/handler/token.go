@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"../helper"
+	"../model"
+	"../repo"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+var sessionKey = "session"
+var roleKey = "role"
+
+// accessClaims them role cua user vao token de middleware khong phai query db
+type accessClaims struct {
+	Role string `json:"role"`
+	jwt.StandardClaims
+}
+
+// newAccessToken ky token bang key dau tien cua config, kid nam trong header
+// de verify duoc ca token ky bang key cu
+func newAccessToken(config helper.Config, user model.User, sessionID string, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(config.AccessTokenTTL)
+	claims := &accessClaims{
+		Role: user.Role,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expiresAt.Unix(),
+			IssuedAt:  now.Unix(),
+			Issuer:    "NordicCoder",
+			Subject:   strconv.Itoa(int(user.ID)),
+			Id:        sessionID,
+		},
+	}
+	key := config.SigningKey()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = key.Kid
+	tokenString, err := token.SignedString(key.Secret)
+	return tokenString, expiresAt, err
+}
+
+func parseAccessToken(config helper.Config, tokenString string) (*accessClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &accessClaims{}, func(token *jwt.Token) (interface{}, error) {
+		// Don't forget to validate the alg is what you expect:
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := config.FindKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("Unknown kid: %v", token.Header["kid"])
+		}
+		return key.Secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*accessClaims)
+	if !ok || claims.Valid() != nil || claims.Subject == "" || claims.Id == "" || claims.Role == "" {
+		return nil, errors.New("Invalid token")
+	}
+	return claims, nil
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func randomToken(n int) string {
+	return base64.RawURLEncoding.EncodeToString(randomBytes(n))
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueTokens tao session moi cung voi access token va refresh token dau tien
+func issueTokens(tokenRepo repo.TokenRepo, config helper.Config, user model.User) (*model.TokenResponse, error) {
+	now := time.Now()
+	refreshToken := randomToken(32)
+	session := model.Session{
+		ID:        hex.EncodeToString(randomBytes(16)),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(config.RefreshTokenTTL),
+	}
+	refresh := model.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: session.ExpiresAt,
+	}
+	if err := tokenRepo.CreateSession(session, refresh); err != nil {
+		return nil, err
+	}
+	token, expiresAt, err := newAccessToken(config, user, session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenResponse{Token: token, RefreshToken: refreshToken, ExpiresAt: expiresAt}, nil
+}
+
+// TokenRefresh doi refresh token lay cap token moi, refresh token cu khong dung duoc nua.
+// Role duoc doc lai tu db nen admin doi role thi token moi co role moi.
+func TokenRefresh(c *gin.Context, tokenRepo repo.TokenRepo, userRepo repo.UserRepo, config helper.Config) (*model.TokenResponse, error) {
+	form := model.TokenRefreshForm{}
+	if err := c.ShouldBind(&form); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	refreshToken := randomToken(32)
+	next := model.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(config.RefreshTokenTTL),
+	}
+	session, err := tokenRepo.Rotate(hashToken(form.RefreshToken), next, now)
+	if err != nil {
+		return nil, err
+	}
+	user, err := userRepo.FindByID(session.UserID)
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, repo.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	token, expiresAt, err := newAccessToken(config, *user, session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenResponse{Token: token, RefreshToken: refreshToken, ExpiresAt: expiresAt}, nil
+}
+
+// UserLogout revoke session cua access token dang dung
+func UserLogout(c *gin.Context, tokenRepo repo.TokenRepo) error {
+	return tokenRepo.RevokeSession(c.GetString(sessionKey), time.Now())
+}
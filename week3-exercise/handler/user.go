@@ -2,21 +2,28 @@ package handler
 
 import (
 	"strconv"
-	"time"
 
+	"../helper"
 	"../model"
 	"../repo"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrChangeOwnRole = helper.NewError(helper.KIND_INVALID, "CHANGE_OWN_ROLE", "Cannot change your own role")
+
+// Sai password va khong co user deu tra ve cung mot loi de khong lo username nao ton tai
+var ErrInvalidCredentials = helper.NewError(helper.KIND_UNAUTHORIZED, "INVALID_CREDENTIALS", "Invalid login or password")
+
 // Khai bao la mot interface
 func UserSignin(c *gin.Context, repo repo.UserRepo) (*model.UserSigninResponse, error) {
 	user := model.User{}
 	if err := c.ShouldBind(&user); err != nil {
 		return nil, err
 	}
+	// Khong cho client tu chon role khi signin
+	user.Role = model.ROLE_EDITOR
 	password := []byte(user.Password)
 	hashPassword, _ := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
 	user.Password = string(hashPassword)
@@ -26,22 +33,31 @@ func UserSignin(c *gin.Context, repo repo.UserRepo) (*model.UserSigninResponse,
 		return nil, err
 	}
 	// return createdUser, nil
-	userSigninResponse := &model.UserSigninResponse{
-		ID:       createdUser.ID,
-		Username: createdUser.Username,
-		Email:    createdUser.Email,
-		Fullname: createdUser.Fullname,
-		Bod:      createdUser.Bod,
+	userSigninResponse := toUserResponse(*createdUser)
+	return &userSigninResponse, nil
+}
+
+// toUserResponse bo Password truoc khi tra ve cho client
+func toUserResponse(user model.User) model.UserSigninResponse {
+	return model.UserSigninResponse{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Fullname: user.Fullname,
+		Bod:      user.Bod,
+		Role:     user.Role,
 	}
-	return userSigninResponse, nil
 }
 
-func UserLogin(c *gin.Context, repo repo.UserRepo) (*model.UserLoginReponse, error) {
+func UserLogin(c *gin.Context, repo repo.UserRepo, tokenRepo repo.TokenRepo, config helper.Config) (*model.UserLoginReponse, error) {
 	form := model.UserLoginForm{}
 	if err := c.ShouldBind(&form); err != nil {
 		return nil, err
 	}
 	user, err := repo.FindByUserLogin(form.Login)
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -49,23 +65,66 @@ func UserLogin(c *gin.Context, repo repo.UserRepo) (*model.UserLoginReponse, err
 	password := []byte(form.Password)
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), password)
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidCredentials
 	}
 	// Co 2 y phuc tap ve cai JWT
-	// 1. Expire trong bao lau
-	// 2. Neu expire ngan thi co che de client refresh lai new token la gi
-	claims := &jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(time.Hour * 24 * 365).Unix(),
-		Issuer:    "NordicCoder",
-		Id:        strconv.Itoa(int(user.ID)),
+	// 1. Expire trong bao lau: access token chi song AccessTokenTTL
+	// 2. Client dung RefreshToken goi /token/refresh de lay token moi
+	tokens, err := issueTokens(tokenRepo, config, *user)
+	if err != nil {
+		return nil, err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, _ := token.SignedString(jwtSecretKey)
 	userLoginResponse := &model.UserLoginReponse{
-		ID:       user.ID,
-		Fullname: user.Fullname,
-		Token:    tokenString,
+		ID:           user.ID,
+		Fullname:     user.Fullname,
+		Role:         user.Role,
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}
+	c.SetCookie("Token", tokens.Token, int(config.AccessTokenTTL.Seconds()), "/", "", false, true)
+	return userLoginResponse, nil
+}
+
+// UserList chi danh cho admin, cursor la id cua user cuoi cung trang truoc
+func UserList(c *gin.Context, repo repo.UserRepo, config helper.Config) (*model.UserListRes, error) {
+	var pagination helper.Pagination
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		return nil, err
+	}
+	afterID, _ := strconv.Atoi(pagination.Cursor)
+	limit := pagination.GetLimit(config)
+	users, err := repo.List(uint(afterID), limit+1)
+	if err != nil {
+		return nil, err
+	}
+	res := &model.UserListRes{Data: []model.UserSigninResponse{}}
+	for i, user := range users {
+		if i == int(limit) {
+			res.NextCursor = strconv.Itoa(int(users[i-1].ID))
+			break
+		}
+		res.Data = append(res.Data, toUserResponse(user))
+	}
+	return res, nil
+}
+
+// UserChangeRole chi danh cho admin. Moi session cua user bi revoke,
+// user phai dang nhap lai va nhan token co role moi.
+func UserChangeRole(c *gin.Context, repo repo.UserRepo) (*model.UserSigninResponse, error) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	form := model.UserRoleForm{}
+	if err := c.ShouldBind(&form); err != nil {
+		return nil, err
+	}
+	// Admin tu ha role cua minh thi co the khong con ai la admin
+	if uint(id) == getUserID(c) {
+		return nil, ErrChangeOwnRole
+	}
+	user, err := repo.UpdateRole(uint(id), form.Role)
+	if err != nil {
+		return nil, err
 	}
-	c.SetCookie("Token", tokenString, 3600*24*365, "/", "", false, true)
-	return userLoginResponse, err
+	res := toUserResponse(*user)
+	return &res, nil
 }
@@ -1,30 +1,63 @@
 package helper
 
+import (
+	"strings"
+)
+
+var ErrInvalidSort = NewError(KIND_INVALID, "INVALID_SORT", "Invalid sort")
+
+// Pagination dung cursor thay vi offset, Cursor lay tu NextCursor cua trang truoc
 type Pagination struct {
-	Page  uint `form:"p"`
-	Limit uint `form:"l"`
+	Limit  uint   `form:"limit"`
+	Cursor string `form:"cursor"`
+	// Vd: sort=-updated_at,title, dau "-" la giam dan
+	Sort string `form:"sort"`
 }
 
-func (self *Pagination) GetPage() uint {
-	if self.Page == 0 {
-		return 1
+func (self *Pagination) GetLimit(config Config) uint {
+	if self.Limit == 0 {
+		return config.ListDefaultLimit
 	}
-	if self.Page > 100 {
-		return 100
+	if self.Limit > config.ListMaxLimit {
+		return config.ListMaxLimit
 	}
-	return self.Page
+	return self.Limit
 }
 
-func (self *Pagination) GetOffset() uint {
-	page := self.GetPage()
-	limit := self.GetLimit()
-	offset := (page - 1) * limit
-	return offset
+type SortField struct {
+	Column string
+	Desc   bool
 }
 
-func (self *Pagination) GetLimit() uint {
-	if self.Limit == 0 || self.Limit > 5 {
-		return 5
+// GetSort parse Sort, chi nhan cac column trong allowed.
+// Luon co "id" o cuoi de thu tu la duy nhat, cursor moi dung.
+func (self *Pagination) GetSort(allowed []string, defaultSort string) ([]SortField, error) {
+	sort := self.Sort
+	if sort == "" {
+		sort = defaultSort
 	}
-	return self.Limit
+	fields := []SortField{}
+	seen := map[string]bool{}
+	for _, s := range strings.Split(sort, ",") {
+		s = strings.TrimSpace(s)
+		field := SortField{Column: strings.TrimPrefix(s, "-"), Desc: strings.HasPrefix(s, "-")}
+		if !contains(allowed, field.Column) || seen[field.Column] {
+			return nil, ErrInvalidSort
+		}
+		seen[field.Column] = true
+		fields = append(fields, field)
+	}
+	if !seen["id"] {
+		fields = append(fields, SortField{Column: "id"})
+	}
+	return fields, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,95 @@
+package helper
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config doc tu ENV, gia tri nao khong set thi dung mac dinh (tru JWT_KEYS bat buoc phai set)
+type Config struct {
+	// So note tra ve moi trang khi client khong truyen limit
+	ListDefaultLimit uint
+	// Limit lon nhat client duoc phep truyen
+	ListMaxLimit uint
+	// Key dau tien dung de ky token, cac key sau chi dung de verify
+	// token cu trong luc rotate key
+	JwtKeys         []JwtKey
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// Note nam trong thung rac lau hon TrashRetention se bi xoa han,
+	// job kiem tra moi TrashPurgeInterval
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+}
+
+type JwtKey struct {
+	Kid    string
+	Secret []byte
+}
+
+// LoadConfig tra ve loi khi JWT_KEYS khong set hoac sai dinh dang,
+// khong co secret mac dinh de khong ai ky duoc token bang secret cong khai
+func LoadConfig() (Config, error) {
+	jwtKeys, err := getJwtKeys("JWT_KEYS")
+	if err != nil {
+		return Config{}, err
+	}
+	return Config{
+		ListDefaultLimit:   getUint("NOTE_LIST_DEFAULT_LIMIT", 20),
+		ListMaxLimit:       getUint("NOTE_LIST_MAX_LIMIT", 100),
+		JwtKeys:            jwtKeys,
+		AccessTokenTTL:     getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:    getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		TrashRetention:     getDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval: getDuration("TRASH_PURGE_INTERVAL", time.Hour),
+	}, nil
+}
+
+func (self Config) SigningKey() JwtKey {
+	return self.JwtKeys[0]
+}
+
+func (self Config) FindKey(kid string) (JwtKey, bool) {
+	for _, key := range self.JwtKeys {
+		if key.Kid == kid {
+			return key, true
+		}
+	}
+	return JwtKey{}, false
+}
+
+func getUint(key string, defaultValue uint) uint {
+	value, err := strconv.ParseUint(os.Getenv(key), 10, 32)
+	if err != nil || value == 0 {
+		return defaultValue
+	}
+	return uint(value)
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getJwtKeys doc dang "kid2:secret2,kid1:secret1"
+func getJwtKeys(key string) ([]JwtKey, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, errors.New(key + " is required")
+	}
+	keys := []JwtKey{}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.New("Invalid " + key + ", expected kid:secret[,kid:secret]")
+		}
+		keys = append(keys, JwtKey{Kid: parts[0], Secret: []byte(parts[1])})
+	}
+	return keys, nil
+}
@@ -0,0 +1,42 @@
+package helper
+
+// Loai cua loi, handler dua vao day de chon HTTP status
+const (
+	KIND_INVALID              = "invalid"
+	KIND_NOT_FOUND            = "not_found"
+	KIND_CONFLICT             = "conflict"
+	KIND_UNAUTHORIZED         = "unauthorized"
+	KIND_FORBIDDEN            = "forbidden"
+	KIND_PRECONDITION_FAILED  = "precondition_failed"
+	KIND_PRECONDITION_MISSING = "precondition_missing"
+	KIND_INTERNAL             = "internal"
+)
+
+// Error la loi nghiep vu, Code on dinh de client dua vao xu ly,
+// Message chi de doc va co the thay doi
+type Error struct {
+	Kind    string       `json:"-"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError la loi validate cua mot field trong request
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func NewError(kind string, code string, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (self *Error) Error() string {
+	return self.Message
+}
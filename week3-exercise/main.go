@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"./handler"
+	"./helper"
 	"./model"
+	"./repo"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/gorm"
@@ -21,6 +23,11 @@ func main() {
 	// if err != nil {
 	// 	log.Fatal("Error loading .env file")
 	// }
+	// JWT_KEYS khong co mac dinh, thieu thi khong start
+	config, err := helper.LoadConfig()
+	if err != nil {
+		panic(err)
+	}
 	// 1. Lien quan toi database
 	db, err := gorm.Open("mysql", "default:secret@/notes?charset=utf8&parseTime=True&loc=Local")
 	if err != nil {
@@ -28,7 +35,7 @@ func main() {
 	}
 	defer db.Close()
 	db.LogMode(true)
-	db.AutoMigrate(&model.Note{}, &model.User{}, &model.Setting{})
+	db.AutoMigrate(&model.Note{}, &model.User{}, &model.Setting{}, &model.Session{}, &model.RefreshToken{}, &model.NoteRevision{}, &model.Tag{}, &model.NoteShare{})
 
 	// 2. Write access log ra file & de giu lai cai Println -> Stdout
 	fileWriter, err := os.Create("access.log")
@@ -40,7 +47,9 @@ func main() {
 
 	// 3. Tao ra router
 	r := gin.Default()
-	handler.InitRoutes(r, db) // Move cai code minh lam qua cho khac
+	stopPurge := repo.StartTrashPurge(db, config.TrashRetention, config.TrashPurgeInterval)
+	defer stopPurge()
+	handler.InitRoutes(r, db, config) // Move cai code minh lam qua cho khac
 	// 4. Start chuong trinh
 	port := os.Getenv("HTTP_PORT")
 	if port == "" {
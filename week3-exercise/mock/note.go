@@ -27,9 +27,9 @@ func (self *NoteRepoImpl) Find(id int) (*model.Note, error) {
 	return args.Get(0).(*model.Note), args.Error(1)
 }
 
-func (self *NoteRepoImpl) List(pagination helper.Pagination) ([]model.Note, error) {
-	args := self.Called(pagination)
-	return args.Get(0).([]model.Note), args.Error(1)
+func (self *NoteRepoImpl) List(filter model.NoteFilter, pagination helper.Pagination) (*model.NoteListRes, error) {
+	args := self.Called(filter, pagination)
+	return args.Get(0).(*model.NoteListRes), args.Error(1)
 }
 
 func (self *NoteRepoImpl) Update(id int, note model.Note) error {
@@ -40,7 +40,67 @@ func (self *NoteRepoImpl) Update(id int, note model.Note) error {
 	return args.Error(0)
 }
 
-func (self *NoteRepoImpl) Delete(id int) error {
+func (self *NoteRepoImpl) Delete(id int, version uint) error {
+	args := self.Called(id, version)
+	return args.Error(0)
+}
+
+func (self *NoteRepoImpl) Revisions(id int) ([]model.NoteRevision, error) {
+	args := self.Called(id)
+	return args.Get(0).([]model.NoteRevision), args.Error(1)
+}
+
+func (self *NoteRepoImpl) Revision(id int, rev int) (*model.NoteRevision, error) {
+	args := self.Called(id, rev)
+	return args.Get(0).(*model.NoteRevision), args.Error(1)
+}
+
+func (self *NoteRepoImpl) Restore(id int, rev int) (*model.Note, error) {
+	args := self.Called(id, rev)
+	return args.Get(0).(*model.Note), args.Error(1)
+}
+
+func (self *NoteRepoImpl) Undelete(id int) (*model.Note, error) {
+	args := self.Called(id)
+	return args.Get(0).(*model.Note), args.Error(1)
+}
+
+func (self *NoteRepoImpl) DeletePermanent(id int) error {
 	args := self.Called(id)
 	return args.Error(0)
 }
+
+func (self *NoteRepoImpl) AddTags(id int, names []string) (*model.Note, error) {
+	args := self.Called(id, names)
+	return args.Get(0).(*model.Note), args.Error(1)
+}
+
+func (self *NoteRepoImpl) RemoveTag(id int, name string) (*model.Note, error) {
+	args := self.Called(id, name)
+	return args.Get(0).(*model.Note), args.Error(1)
+}
+
+func (self *NoteRepoImpl) Share(id int, userID uint, permission string) (*model.NoteShare, error) {
+	args := self.Called(id, userID, permission)
+	return args.Get(0).(*model.NoteShare), args.Error(1)
+}
+
+func (self *NoteRepoImpl) Unshare(id int, userID uint) error {
+	args := self.Called(id, userID)
+	return args.Error(0)
+}
+
+func (self *NoteRepoImpl) Shares(id int) ([]model.NoteAccess, error) {
+	args := self.Called(id)
+	return args.Get(0).([]model.NoteAccess), args.Error(1)
+}
+
+func (self *NoteRepoImpl) Batch(ops []model.NoteBatchOp, atomic bool) ([]model.NoteBatchResult, bool, error) {
+	args := self.Called(ops, atomic)
+	return args.Get(0).([]model.NoteBatchResult), args.Bool(1), args.Error(2)
+}
+
+func (self *NoteRepoImpl) Search(query string, limit uint) ([]model.NoteSearchHit, error) {
+	args := self.Called(query, limit)
+	return args.Get(0).([]model.NoteSearchHit), args.Error(1)
+}
@@ -0,0 +1,48 @@
+package model
+
+import "../helper"
+
+// Cac op cua POST /note/batch
+const (
+	BATCH_CREATE = "create"
+	BATCH_UPDATE = "update"
+	BATCH_DELETE = "delete"
+	// Chi set Completed = true, giu nguyen Title
+	BATCH_COMPLETE = "complete"
+)
+
+// NoteBatchOp: ID va Version dung cho update, delete va complete.
+// Version la version client dang co (nhu If-Match), bat buoc > 0 de op khong
+// ghi de thay doi ma client chua thay.
+type NoteBatchOp struct {
+	Op        string `binding:"required,oneof=create update delete complete"`
+	ID        int    `binding:"min=0"`
+	Version   uint
+	Title     string
+	Completed bool
+}
+
+// NoteBatchForm: Atomic = true thi mot op loi la khong op nao duoc luu,
+// false thi bo qua op loi va van luu cac op khac
+type NoteBatchForm struct {
+	Atomic bool
+	Ops    []NoteBatchOp `binding:"required,min=1,max=100,dive"`
+}
+
+// NoteBatchResult la ket qua cua op cung vi tri trong Ops.
+// Note la note sau khi chay op, nil voi delete hoac khi op loi.
+type NoteBatchResult struct {
+	Op    string
+	OK    bool
+	Note  *Note         `json:",omitempty"`
+	Error *helper.Error `json:",omitempty"`
+	// Loi goc cua op, handler doi sang Error truoc khi tra ve client
+	Err error `json:"-"`
+}
+
+type NoteBatchRes struct {
+	Atomic bool
+	// Committed = false khi Atomic va co op loi
+	Committed bool
+	Results   []NoteBatchResult
+}
@@ -1,9 +1,64 @@
 package model
 
-import "github.com/jinzhu/gorm"
+import (
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
 
 type Note struct {
 	gorm.Model
 	Title     string `binding:"required,min=3,max=255"`
 	Completed bool
+	// Chu cua note, lay tu identity cua token chu khong lay tu request
+	UserID uint `gorm:"index;not null" binding:"-"`
+	// Tang 1 moi lan update, dung lam ETag
+	Version uint `gorm:"not null;default:1" binding:"-"`
+	// Chi doc, them/bot tag qua /note/:id/tags
+	Tags []Tag `gorm:"many2many:note_tags" binding:"-"`
+}
+
+// NoteFilter la cac dieu kien loc cua GET /note, thoi gian dang RFC3339
+type NoteFilter struct {
+	Completed *bool `form:"completed"`
+	// Tim note co title chua chuoi nay
+	Title       string     `form:"title"`
+	CreatedFrom *time.Time `form:"created_from"`
+	CreatedTo   *time.Time `form:"created_to"`
+	UpdatedFrom *time.Time `form:"updated_from"`
+	UpdatedTo   *time.Time `form:"updated_to"`
+	// tags=a&tags=b hoac tags=a,b
+	Tags    []string `form:"tags"`
+	TagMode string   `form:"tag_mode" binding:"omitempty,oneof=any all"`
+	// Chi lay cac note trong thung rac, duoc set boi GET /note/trash
+	Trashed bool `form:"-"`
+	// Chi lay cac note user khac share cho minh, duoc set boi GET /note/shared
+	Shared bool `form:"-"`
+}
+
+type NoteListRes struct {
+	Data []Note
+	// Tong so note thoa filter, khong phu thuoc cursor
+	Total int
+	// Rong la het du lieu
+	NextCursor string
+}
+
+// TagNames tach cac tag viet cach nhau bang dau phay va chuan hoa ten
+func (self NoteFilter) TagNames() []string {
+	names := []string{}
+	for _, tags := range self.Tags {
+		for _, name := range strings.Split(tags, ",") {
+			if name = NormalizeTag(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// NormalizeTag: ten tag khong phan biet hoa thuong
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
 }
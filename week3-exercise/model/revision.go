@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// Action da thay the noi dung cua revision
+const (
+	REVISION_UPDATE  = "update"
+	REVISION_DELETE  = "delete"
+	REVISION_RESTORE = "restore"
+)
+
+// NoteRevision la noi dung cua note ngay truoc moi lan update, delete hoac restore.
+// Rev tang dan tu 1 trong moi note.
+type NoteRevision struct {
+	ID        uint   `gorm:"primary_key"`
+	NoteID    uint   `gorm:"unique_index:note_rev;not null"`
+	Rev       int    `gorm:"unique_index:note_rev;not null"`
+	Action    string `gorm:"size:16;not null"`
+	Title     string
+	Completed bool
+	// User da thuc hien action
+	UserID    uint
+	CreatedAt time.Time
+}
+
+type NoteDiffForm struct {
+	From int `form:"from" binding:"min=0"`
+	// 0 la noi dung hien tai cua note
+	To int `form:"to" binding:"min=0"`
+}
+
+type FieldChange struct {
+	Field  string
+	Before interface{}
+	After  interface{}
+}
+
+type NoteDiff struct {
+	From    int
+	To      int
+	Changes []FieldChange
+}
+
+// Diff tra ve cac field khac nhau giua hai revision
+func (self NoteRevision) Diff(other NoteRevision) NoteDiff {
+	diff := NoteDiff{From: self.Rev, To: other.Rev, Changes: []FieldChange{}}
+	if self.Title != other.Title {
+		diff.Changes = append(diff.Changes, FieldChange{"Title", self.Title, other.Title})
+	}
+	if self.Completed != other.Completed {
+		diff.Changes = append(diff.Changes, FieldChange{"Completed", self.Completed, other.Completed})
+	}
+	return diff
+}
@@ -0,0 +1,25 @@
+package model
+
+// NoteSearchForm la query cua GET /note/search
+type NoteSearchForm struct {
+	Q     string `form:"q" binding:"required,max=255"`
+	Limit uint   `form:"limit"`
+}
+
+// SearchSnippet la doan text cua Field co chua tu khop, tu khop duoc boc trong <mark></mark>.
+// Phan con lai da duoc escape HTML.
+type SearchSnippet struct {
+	Field string
+	Text  string
+}
+
+// NoteSearchHit: Score cang lon cang khop, chi dung de so sanh trong cung mot lan search
+type NoteSearchHit struct {
+	Note     Note
+	Score    float64
+	Snippets []SearchSnippet
+}
+
+type NoteSearchRes struct {
+	Data []NoteSearchHit
+}
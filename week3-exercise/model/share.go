@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"../helper"
+)
+
+const (
+	// Chi doc note va revision
+	PERMISSION_VIEW = "view"
+	// Doc, sua, xoa va restore revision
+	PERMISSION_EDIT = "edit"
+)
+
+var ErrShareWithOwner = helper.NewError(helper.KIND_INVALID, "SHARE_WITH_OWNER", "Cannot share note with its owner")
+
+// NoteShare cho UserID quyen Permission tren note cua user khac
+type NoteShare struct {
+	ID         uint   `gorm:"primary_key"`
+	NoteID     uint   `gorm:"unique_index:note_user;not null"`
+	UserID     uint   `gorm:"unique_index:note_user;index;not null"`
+	Permission string `gorm:"size:8;not null"`
+	CreatedAt  time.Time
+}
+
+// NoteShareForm: Login la username hoac email cua user duoc share
+type NoteShareForm struct {
+	Login      string `binding:"required"`
+	Permission string `binding:"required,oneof=view edit"`
+}
+
+type NoteAccess struct {
+	UserID     uint
+	Username   string
+	Permission string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Tag thuoc ve mot user, ten tag la duy nhat trong cac tag cua user do
+type Tag struct {
+	ID        uint   `gorm:"primary_key"`
+	UserID    uint   `gorm:"unique_index:user_tag;not null"`
+	Name      string `gorm:"unique_index:user_tag;size:64;not null"`
+	CreatedAt time.Time
+}
+
+// Tag mode cua NoteFilter
+const (
+	// Note co it nhat mot tag trong Tags
+	TAG_MODE_ANY = "any"
+	// Note co tat ca tag trong Tags
+	TAG_MODE_ALL = "all"
+)
+
+type NoteTagsForm struct {
+	Tags []string `binding:"required,min=1,dive,required,max=64"`
+}
+
+// TagRenameForm: neu da co tag ten Name thi hai tag duoc gop lai
+type TagRenameForm struct {
+	Name string `binding:"required,max=64"`
+}
+
+type TagCount struct {
+	ID    uint
+	Name  string
+	Count int
+}
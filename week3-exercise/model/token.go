@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Session duoc tao moi lan login, Id cua access token (jti) la ID cua session.
+// Logout hoac phat hien refresh token bi dung lai thi session bi revoke,
+// tat ca access token cua session se khong dung duoc nua.
+type Session struct {
+	ID        string `gorm:"primary_key;size:32"`
+	UserID    uint   `gorm:"index;not null"`
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// RefreshToken chi dung duoc mot lan, moi lan refresh se tao token moi.
+// Chi luu sha256 cua token.
+type RefreshToken struct {
+	ID        uint   `gorm:"primary_key"`
+	SessionID string `gorm:"index;size:32;not null"`
+	TokenHash string `gorm:"unique;size:64;not null"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type TokenRefreshForm struct {
+	RefreshToken string `binding:"required"`
+}
+
+type TokenResponse struct {
+	Token        string
+	RefreshToken string
+	// Thoi diem access token het han
+	ExpiresAt time.Time
+}
@@ -6,6 +6,20 @@ import (
 	"github.com/jinzhu/gorm"
 )
 
+// Role cua user, user moi signin luon la editor.
+// Admin dau tien phai set truc tiep trong db:
+// UPDATE users SET role = 'admin' WHERE username = '...'
+const (
+	// Quan ly user, doc duoc note cua tat ca user
+	ROLE_ADMIN = "admin"
+	// Tao, sua, xoa note cua minh
+	ROLE_EDITOR = "editor"
+	// Chi doc note cua minh
+	ROLE_VIEWER = "viewer"
+	// Nhan vien support, chi doc duoc note cua tat ca user
+	ROLE_SUPPORT = "support"
+)
+
 type User struct {
 	gorm.Model
 	Username string `gorm:"unique;not null",binding:"required"`
@@ -13,6 +27,17 @@ type User struct {
 	Password string `binding:"required"`
 	Fullname string
 	Bod      *time.Time
+	Role     string `gorm:"size:16;not null;default:'editor'"`
+}
+
+type UserRoleForm struct {
+	Role string `binding:"required,oneof=admin editor viewer support"`
+}
+
+type UserListRes struct {
+	Data []UserSigninResponse
+	// Id cua user cuoi cung, rong la het du lieu
+	NextCursor string
 }
 
 type UserLoginForm struct {
@@ -26,10 +51,14 @@ type UserSigninResponse struct {
 	Email    string
 	Fullname string
 	Bod      *time.Time
+	Role     string
 }
 
 type UserLoginReponse struct {
-	ID       uint
-	Fullname string
-	Token    string
+	ID           uint
+	Fullname     string
+	Role         string
+	Token        string
+	RefreshToken string
+	ExpiresAt    time.Time
 }
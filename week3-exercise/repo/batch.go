@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"fmt"
+
+	"../helper"
+	"../model"
+)
+
+// ErrBatchAborted: op khong loi nhung khong duoc luu vi op khac trong batch atomic bi loi
+var ErrBatchAborted = helper.NewError(helper.KIND_CONFLICT, "BATCH_ABORTED", "Not applied because another operation in the batch failed")
+
+var ErrInvalidBatchOp = helper.NewError(helper.KIND_INVALID, "INVALID_BATCH_OP", "Unknown batch operation")
+
+// ErrBatchVersionRequired cung code voi loi thieu If-Match cua cac endpoint rieng le
+var ErrBatchVersionRequired = helper.NewError(helper.KIND_PRECONDITION_MISSING, "IF_MATCH_REQUIRED", "Version is required for update, complete and delete")
+
+// Batch chay cac op theo thu tu trong mot transaction va tra ve ket qua cua tung op.
+// atomic = true thi dung o op loi dau tien va rollback tat ca.
+// atomic = false thi moi op chay trong mot savepoint, op loi chi rollback ve savepoint cua no.
+// Tra ve true neu transaction duoc commit.
+func (self *NoteRepoImpl) Batch(ops []model.NoteBatchOp, atomic bool) ([]model.NoteBatchResult, bool, error) {
+	results := make([]model.NoteBatchResult, len(ops))
+	for i, op := range ops {
+		results[i].Op = op.Op
+	}
+	tx := self.DB.Begin()
+	if tx.Error != nil {
+		return nil, false, tx.Error
+	}
+	repo := self.in(tx)
+	for i, op := range ops {
+		savepoint := fmt.Sprintf("batch_op_%d", i)
+		if !atomic {
+			if err := tx.Exec("SAVEPOINT " + savepoint).Error; err != nil {
+				tx.Rollback()
+				return nil, false, err
+			}
+		}
+		note, err := repo.batchOp(op)
+		if err == nil {
+			results[i].OK = true
+			results[i].Note = note
+			continue
+		}
+		results[i].Err = err
+		if atomic {
+			tx.Rollback()
+			abortBatch(results)
+			return results, false, nil
+		}
+		if err := tx.Exec("ROLLBACK TO SAVEPOINT " + savepoint).Error; err != nil {
+			tx.Rollback()
+			return nil, false, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, false, err
+	}
+	return results, true, nil
+}
+
+func (self *NoteRepoImpl) batchOp(op model.NoteBatchOp) (*model.Note, error) {
+	if op.Op != model.BATCH_CREATE && op.Version == 0 {
+		return nil, ErrBatchVersionRequired
+	}
+	switch op.Op {
+	case model.BATCH_CREATE:
+		return self.Create(model.Note{Title: op.Title, Completed: op.Completed})
+	case model.BATCH_UPDATE:
+		return self.updateFields(op.ID, op.Version, map[string]interface{}{
+			"title":     op.Title,
+			"completed": op.Completed,
+		})
+	case model.BATCH_COMPLETE:
+		return self.updateFields(op.ID, op.Version, map[string]interface{}{
+			"completed": true,
+		})
+	case model.BATCH_DELETE:
+		return nil, self.Delete(op.ID, op.Version)
+	}
+	return nil, ErrInvalidBatchOp
+}
+
+// abortBatch danh dau cac op chua loi la khong duoc luu
+func abortBatch(results []model.NoteBatchResult) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].OK = false
+			results[i].Note = nil
+			results[i].Err = ErrBatchAborted
+		}
+	}
+}
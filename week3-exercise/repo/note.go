@@ -1,52 +1,401 @@
 package repo
 
 import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
 	"../helper"
 	"../model"
 	"github.com/jinzhu/gorm"
 )
 
+var ErrInvalidCursor = helper.NewError(helper.KIND_INVALID, "INVALID_CURSOR", "Invalid cursor")
+
+// ErrVersionMismatch: note da bi thay doi sau lan client doc gan nhat
+var ErrVersionMismatch = helper.NewError(helper.KIND_PRECONDITION_FAILED, "VERSION_MISMATCH", "Note has been changed, reload and try again")
+
+// Cac column duoc phep sort cua GET /note
+var NOTE_SORT_COLUMNS = []string{"id", "title", "completed", "created_at", "updated_at"}
+
+const NOTE_DEFAULT_SORT = "-updated_at"
+
+// Thung rac sort them duoc theo deleted_at, mac dinh note moi xoa len truoc
+var NOTE_TRASH_SORT_COLUMNS = append([]string{"deleted_at"}, NOTE_SORT_COLUMNS...)
+
+const NOTE_TRASH_DEFAULT_SORT = "-deleted_at"
+
 type NoteRepo interface {
 	Find(int) (*model.Note, error)
-	List(helper.Pagination) ([]model.Note, error)
+	List(model.NoteFilter, helper.Pagination) (*model.NoteListRes, error)
 	Update(int, model.Note) error
-	Delete(int) error
+	// Delete nhan version client dang co, 0 la khong kiem tra
+	Delete(int, uint) error
 	Create(model.Note) (*model.Note, error)
+	Revisions(int) ([]model.NoteRevision, error)
+	// Revision tra ve revision rev cua note, rev = 0 la noi dung hien tai
+	Revision(int, int) (*model.NoteRevision, error)
+	Restore(int, int) (*model.Note, error)
+	// Undelete lay note ra khoi thung rac
+	Undelete(int) (*model.Note, error)
+	// DeletePermanent xoa han note va cac revision cua note
+	DeletePermanent(int) error
+	// AddTags tao tag neu chua co roi gan vao note
+	AddTags(int, []string) (*model.Note, error)
+	RemoveTag(int, string) (*model.Note, error)
+	// Share, Unshare va Shares chi danh cho chu cua note
+	Share(int, uint, string) (*model.NoteShare, error)
+	Unshare(int, uint) error
+	Shares(int) ([]model.NoteAccess, error)
+	// Batch chay nhieu op trong mot transaction, bool la transaction co duoc commit khong
+	Batch([]model.NoteBatchOp, bool) ([]model.NoteBatchResult, bool, error)
+	// Search tra ve toi da limit note khop voi query, note khop nhat truoc
+	Search(string, uint) ([]model.NoteSearchHit, error)
 }
 
+// NoteRepoImpl chi thay duoc note cua UserID va note duoc share cho UserID,
+// note khac coi nhu khong ton tai
 type NoteRepoImpl struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	UserID uint
+	// ReadAll cho phep Find va List doc note cua tat ca user (admin, support).
+	// Update va Delete van chi duoc tren note UserID co quyen sua.
+	ReadAll bool
+	// Index chi can khi goi Search va DeletePermanent
+	Index SearchIndex
+}
+
+// Note duoc share cho user, tham so la user_id va permission
+const SHARED_NOTE_IDS = "SELECT note_id FROM note_shares WHERE user_id = ? AND permission IN (?)"
+
+// owned gioi han query trong cac note cua UserID
+func (self *NoteRepoImpl) owned() *gorm.DB {
+	return self.DB.Where("user_id = ?", self.UserID)
+}
+
+// visible la cac note hien trong GET /note
+func (self *NoteRepoImpl) visible() *gorm.DB {
+	if self.ReadAll {
+		return self.DB
+	}
+	return self.owned()
+}
+
+// readable them cac note duoc share (view hoac edit) cho UserID
+func (self *NoteRepoImpl) readable() *gorm.DB {
+	if self.ReadAll {
+		return self.DB
+	}
+	return self.DB.Where("user_id = ? OR id IN ("+SHARED_NOTE_IDS+")",
+		self.UserID, self.UserID, []string{model.PERMISSION_VIEW, model.PERMISSION_EDIT})
+}
+
+// writable la note cua UserID va note duoc share quyen edit
+func (self *NoteRepoImpl) writable() *gorm.DB {
+	return self.DB.Where("user_id = ? OR id IN ("+SHARED_NOTE_IDS+")",
+		self.UserID, self.UserID, []string{model.PERMISSION_EDIT})
+}
+
+// noteCursor la vi tri cua note cuoi cung trong trang truoc.
+// Mac dinh sort theo updated_at nen cursor chinh la (updated_at, id).
+type noteCursor struct {
+	Sort      string     `json:"s"`
+	ID        uint       `json:"i"`
+	Title     string     `json:"t,omitempty"`
+	Completed bool       `json:"c,omitempty"`
+	CreatedAt time.Time  `json:"ca"`
+	UpdatedAt time.Time  `json:"ua"`
+	DeletedAt *time.Time `json:"da,omitempty"`
+}
+
+func encodeCursor(sort string, note model.Note) string {
+	data, _ := json.Marshal(noteCursor{
+		Sort:      sort,
+		ID:        note.ID,
+		Title:     note.Title,
+		Completed: note.Completed,
+		CreatedAt: note.CreatedAt,
+		UpdatedAt: note.UpdatedAt,
+		DeletedAt: note.DeletedAt,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor tra ve loi neu cursor khong phai cua cung cach sort
+func decodeCursor(sort string, cursor string) (*noteCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &noteCursor{}
+	if err := json.Unmarshal(data, c); err != nil || c.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+func (self *noteCursor) value(column string) interface{} {
+	switch column {
+	case "title":
+		return self.Title
+	case "completed":
+		return self.Completed
+	case "created_at":
+		return self.CreatedAt
+	case "updated_at":
+		return self.UpdatedAt
+	case "deleted_at":
+		return self.DeletedAt
+	}
+	return self.ID
+}
+
+// after tao dieu kien lay cac note dung sau cursor theo thu tu sort:
+// (a > ?) OR (a = ? AND b > ?) OR ...
+func (self *noteCursor) after(fields []helper.SortField) (string, []interface{}) {
+	ors := []string{}
+	args := []interface{}{}
+	for i, field := range fields {
+		ands := []string{}
+		for _, prev := range fields[:i] {
+			ands = append(ands, prev.Column+" = ?")
+			args = append(args, self.value(prev.Column))
+		}
+		op := " > ?"
+		if field.Desc {
+			op = " < ?"
+		}
+		ands = append(ands, field.Column+op)
+		args = append(args, self.value(field.Column))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return strings.Join(ors, " OR "), args
+}
+
+// escapeLike de title chua % hoac _ van duoc tim nhu chuoi thuong
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+func countDistinct(values []string) int {
+	seen := map[string]bool{}
+	for _, v := range values {
+		seen[v] = true
+	}
+	return len(seen)
+}
+
+func filterNotes(db *gorm.DB, filter model.NoteFilter) *gorm.DB {
+	if filter.Completed != nil {
+		db = db.Where("completed = ?", *filter.Completed)
+	}
+	if filter.Title != "" {
+		db = db.Where("title LIKE ? ESCAPE '!'", "%"+escapeLike(filter.Title)+"%")
+	}
+	if filter.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		db = db.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		db = db.Where("updated_at >= ?", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		db = db.Where("updated_at <= ?", *filter.UpdatedTo)
+	}
+	if names := filter.TagNames(); len(names) > 0 {
+		tagged := "SELECT note_tags.note_id FROM note_tags " +
+			"JOIN tags ON tags.id = note_tags.tag_id " +
+			"WHERE tags.name IN (?)"
+		if filter.TagMode == model.TAG_MODE_ALL {
+			tagged += " GROUP BY note_tags.note_id HAVING COUNT(DISTINCT tags.name) = ?"
+			db = db.Where("id IN ("+tagged+")", names, countDistinct(names))
+		} else {
+			db = db.Where("id IN ("+tagged+")", names)
+		}
+	}
+	return db
 }
 
 // 1. That su la co mot func phu thuoc vao db
 func (self *NoteRepoImpl) Create(note model.Note) (*model.Note, error) {
+	note.UserID = self.UserID
+	note.Version = 1
+	// Tag duoc them qua AddTags
+	note.Tags = nil
 	err := self.DB.Create(&note).Error
 	return &note, err
 }
 
 func (self *NoteRepoImpl) Find(id int) (*model.Note, error) {
 	note := &model.Note{}
-	err := self.DB.Where("id = ?", id).First(note).Error
+	err := self.readable().Preload("Tags").Where("id = ?", id).First(note).Error
 	return note, err
 }
 
-func (self *NoteRepoImpl) List(pagination helper.Pagination) ([]model.Note, error) {
-	notes := []model.Note{}
-	offset := pagination.GetOffset()
-	limit := pagination.GetLimit()
-	err := self.DB.Offset(offset).
-		Limit(limit).
-		Find(&notes).
-		Error
-	return notes, err
+// List lay Limit+1 note de biet con trang sau hay khong
+func (self *NoteRepoImpl) List(filter model.NoteFilter, pagination helper.Pagination) (*model.NoteListRes, error) {
+	columns, defaultSort := NOTE_SORT_COLUMNS, NOTE_DEFAULT_SORT
+	query := self.visible()
+	// Thung rac chi co note cua chinh minh
+	if filter.Trashed {
+		columns, defaultSort = NOTE_TRASH_SORT_COLUMNS, NOTE_TRASH_DEFAULT_SORT
+		query = self.owned().Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if filter.Shared {
+		query = self.DB.Where("id IN ("+SHARED_NOTE_IDS+")",
+			self.UserID, []string{model.PERMISSION_VIEW, model.PERMISSION_EDIT})
+	}
+	fields, err := pagination.GetSort(columns, defaultSort)
+	if err != nil {
+		return nil, err
+	}
+	sort := pagination.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	res := &model.NoteListRes{Data: []model.Note{}}
+	query = filterNotes(query.Model(&model.Note{}), filter)
+	if err := query.Count(&res.Total).Error; err != nil {
+		return nil, err
+	}
+	if pagination.Cursor != "" {
+		cursor, err := decodeCursor(sort, pagination.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where, args := cursor.after(fields)
+		query = query.Where(where, args...)
+	}
+	for _, field := range fields {
+		if field.Desc {
+			query = query.Order(field.Column + " DESC")
+		} else {
+			query = query.Order(field.Column)
+		}
+	}
+	limit := int(pagination.Limit)
+	if err := query.Preload("Tags").Limit(limit + 1).Find(&res.Data).Error; err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(res.Data) > limit {
+		res.Data = res.Data[:limit]
+		res.NextCursor = encodeCursor(sort, res.Data[limit-1])
+	}
+	return res, nil
+}
+
+// in tra ve repo dung transaction tx thay cho DB
+func (self *NoteRepoImpl) in(tx *gorm.DB) *NoteRepoImpl {
+	return &NoteRepoImpl{DB: tx, UserID: self.UserID, ReadAll: self.ReadAll, Index: self.Index}
+}
+
+// transaction chay fn trong mot transaction. Neu DB da la transaction (vd trong Batch)
+// thi fn chay luon trong transaction do, commit hay rollback do ben ngoai quyet dinh.
+func (self *NoteRepoImpl) transaction(fn func(repo *NoteRepoImpl) error) error {
+	if _, ok := self.DB.CommonDB().(*sql.Tx); ok {
+		return fn(self)
+	}
+	tx := self.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if err := fn(self.in(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
 }
 
+// change luu revision cua note truoc khi goi fn, tat ca trong mot transaction.
+// Tra ve ErrRecordNotFound neu UserID khong co quyen sua note,
+// ErrVersionMismatch neu version > 0 va khac version hien tai cua note.
+func (self *NoteRepoImpl) change(id int, version uint, action string, fn func(repo *NoteRepoImpl, current *model.Note) error) error {
+	return self.transaction(func(repo *NoteRepoImpl) error {
+		current := &model.Note{}
+		// Lock row cua note den het transaction de cac change cung note chay lan luot,
+		// saveRevision moi tinh dung rev tiep theo
+		if err := forUpdate(repo.writable()).Where("id = ?", id).First(current).Error; err != nil {
+			return err
+		}
+		if version > 0 && version != current.Version {
+			return ErrVersionMismatch
+		}
+		if err := repo.saveRevision(*current, action); err != nil {
+			return err
+		}
+		return fn(repo, current)
+	})
+}
+
+// forUpdate them FOR UPDATE vao cau SELECT, SQLite khong ho tro FOR UPDATE
+// nhung chi cho mot transaction ghi tai mot thoi diem
+func forUpdate(db *gorm.DB) *gorm.DB {
+	if db.Dialect().GetName() == "mysql" {
+		return db.Set("gorm:query_option", "FOR UPDATE")
+	}
+	return db
+}
+
+// save cap nhat fields va tang version. Dieu kien version trong WHERE
+// dam bao khong ghi de len thay doi cua request khac da commit truoc.
+func (self *NoteRepoImpl) save(current *model.Note, fields map[string]interface{}) error {
+	fields["version"] = gorm.Expr("version + 1")
+	result := self.DB.Model(&model.Note{}).
+		Where("id = ? AND version = ?", current.ID, current.Version).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionMismatch
+	}
+	return self.DB.Where("id = ?", current.ID).First(current).Error
+}
+
+// Update va Delete duoc tren note cua UserID va note duoc share quyen edit.
+// note.Version la version client dang co (tu If-Match), 0 la khong kiem tra.
+// Chi Title va Completed duoc cap nhat.
 func (self *NoteRepoImpl) Update(id int, note model.Note) error {
-	err := self.DB.Where("id = ?", id).Update(&note).Error
+	_, err := self.updateFields(id, note.Version, map[string]interface{}{
+		"title":     note.Title,
+		"completed": note.Completed,
+	})
 	return err
 }
 
-func (self *NoteRepoImpl) Delete(id int) error {
-	err := self.DB.Where("id = ?", id).Delete(&model.Note{}).Error
-	return err
+// updateFields la Update nhung chi cap nhat fields, tra ve note sau khi cap nhat
+func (self *NoteRepoImpl) updateFields(id int, version uint, fields map[string]interface{}) (*model.Note, error) {
+	updated := &model.Note{}
+	err := self.change(id, version, model.REVISION_UPDATE, func(repo *NoteRepoImpl, current *model.Note) error {
+		updated = current
+		return repo.save(current, fields)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (self *NoteRepoImpl) Delete(id int, version uint) error {
+	return self.change(id, version, model.REVISION_DELETE, func(repo *NoteRepoImpl, current *model.Note) error {
+		result := repo.DB.Where("id = ? AND version = ?", id, current.Version).Delete(&model.Note{})
+		if result.Error == nil && result.RowsAffected == 0 {
+			return ErrVersionMismatch
+		}
+		return result.Error
+	})
+}
+
+func notFoundIfNoRows(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
@@ -0,0 +1,616 @@
+package repo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"../helper"
+	"../model"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+// newTestDB tao SQLite trong memory, khong can MySQL
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.DB().SetMaxOpenConns(1)
+	db.AutoMigrate(&model.Note{}, &model.NoteRevision{}, &model.Tag{}, &model.NoteShare{}, &model.User{})
+	return db
+}
+
+func createNotes(t *testing.T, db *gorm.DB, notes ...model.Note) {
+	for _, note := range notes {
+		if err := db.Create(&note).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func noteAt(title string, completed bool, at time.Time) model.Note {
+	note := model.Note{Title: title, Completed: completed}
+	note.CreatedAt = at
+	note.UpdatedAt = at
+	return note
+}
+
+func titles(notes []model.Note) []string {
+	result := []string{}
+	for _, note := range notes {
+		result = append(result, note.Title)
+	}
+	return result
+}
+
+func Test_NoteList_Filter(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	// Chi so sanh duoc time cung timezone vi SQLite luu dang text
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	createNotes(t, db,
+		noteAt("buy milk", false, base),
+		noteAt("buy 100% juice", true, base.Add(time.Hour)),
+		noteAt("do homework", false, base.Add(2*time.Hour)),
+	)
+	noteRepo := &NoteRepoImpl{DB: db}
+	completed := false
+	from := base.Add(30 * time.Minute)
+	cases := []struct {
+		name     string
+		filter   model.NoteFilter
+		expected []string
+	}{
+		{"completed", model.NoteFilter{Completed: &completed}, []string{"do homework", "buy milk"}},
+		{"title", model.NoteFilter{Title: "buy"}, []string{"buy 100% juice", "buy milk"}},
+		{"title escape", model.NoteFilter{Title: "0%"}, []string{"buy 100% juice"}},
+		{"created range", model.NoteFilter{CreatedFrom: &from}, []string{"do homework", "buy 100% juice"}},
+		{"updated range", model.NoteFilter{UpdatedTo: &from, Title: "buy"}, []string{"buy milk"}},
+	}
+	for _, c := range cases {
+		res, err := noteRepo.List(c.filter, helper.Pagination{Limit: 10})
+		if err != nil {
+			t.Fatal(c.name, err)
+		}
+		actual := titles(res.Data)
+		if len(actual) != len(c.expected) || res.Total != len(c.expected) {
+			t.Errorf("%s: expected %v, actual %v (total %d)", c.name, c.expected, actual, res.Total)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != c.expected[i] {
+				t.Errorf("%s: expected %v, actual %v", c.name, c.expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func Test_NoteList_Cursor(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Hai note cung updated_at de kiem tra id lam tie-breaker
+	createNotes(t, db,
+		noteAt("note a", true, base),
+		noteAt("note b", false, base.Add(time.Hour)),
+		noteAt("note c", true, base.Add(time.Hour)),
+		noteAt("note d", false, base.Add(2*time.Hour)),
+		noteAt("note e", true, base.Add(3*time.Hour)),
+	)
+	noteRepo := &NoteRepoImpl{DB: db}
+	for _, c := range []struct {
+		sort     string
+		expected []string
+	}{
+		{"", []string{"note e", "note d", "note b", "note c", "note a"}},
+		{"completed,-title", []string{"note d", "note b", "note e", "note c", "note a"}},
+		{"-created_at,-id", []string{"note e", "note d", "note c", "note b", "note a"}},
+	} {
+		pagination := helper.Pagination{Limit: 2, Sort: c.sort}
+		actual := []string{}
+		for page := 0; page < 5; page++ {
+			res, err := noteRepo.List(model.NoteFilter{}, pagination)
+			if err != nil {
+				t.Fatal(c.sort, err)
+			}
+			if res.Total != 5 {
+				t.Errorf("%s: total should be 5, actual %d", c.sort, res.Total)
+			}
+			actual = append(actual, titles(res.Data)...)
+			if res.NextCursor == "" {
+				break
+			}
+			pagination.Cursor = res.NextCursor
+		}
+		if len(actual) != len(c.expected) {
+			t.Errorf("sort %q: expected %v, actual %v", c.sort, c.expected, actual)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != c.expected[i] {
+				t.Errorf("sort %q: expected %v, actual %v", c.sort, c.expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func Test_NoteList_Invalid(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	createNotes(t, db, model.Note{Title: "note a"}, model.Note{Title: "note b"})
+	noteRepo := &NoteRepoImpl{DB: db}
+	if _, err := noteRepo.List(model.NoteFilter{}, helper.Pagination{Limit: 1, Sort: "password"}); err != helper.ErrInvalidSort {
+		t.Error("Expected invalid sort error, actual", err)
+	}
+	res, _ := noteRepo.List(model.NoteFilter{}, helper.Pagination{Limit: 1})
+	// Cursor cua cach sort khac thi khong dung duoc
+	pagination := helper.Pagination{Limit: 1, Sort: "title", Cursor: res.NextCursor}
+	if _, err := noteRepo.List(model.NoteFilter{}, pagination); err != ErrInvalidCursor {
+		t.Error("Expected invalid cursor error, actual", err)
+	}
+	pagination.Cursor = "not-a-cursor"
+	if _, err := noteRepo.List(model.NoteFilter{}, pagination); err != ErrInvalidCursor {
+		t.Error("Expected invalid cursor error, actual", err)
+	}
+}
+
+func Test_NoteRepo_Owner(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	alice := &NoteRepoImpl{DB: db, UserID: 1}
+	bob := &NoteRepoImpl{DB: db, UserID: 2}
+	note, err := alice.Create(model.Note{Title: "alice note", UserID: 2})
+	if err != nil || note.UserID != 1 {
+		t.Fatal("Owner should come from repo, not from note", err)
+	}
+	id := int(note.ID)
+	if _, err := bob.Find(id); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Find other user's note should be not found, actual", err)
+	}
+	if res, _ := bob.List(model.NoteFilter{}, helper.Pagination{Limit: 10}); res.Total != 0 {
+		t.Error("List should not contain other user's note")
+	}
+	if err := bob.Update(id, model.Note{Title: "hacked"}); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Update other user's note should be not found, actual", err)
+	}
+	if err := bob.Delete(id, 0); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Delete other user's note should be not found, actual", err)
+	}
+	// Khong doi duoc chu cua note qua Update
+	if err := alice.Update(id, model.Note{Title: "alice edit", UserID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	found, err := alice.Find(id)
+	if err != nil || found.Title != "alice edit" || found.UserID != 1 {
+		t.Error("Owner should update own note", found, err)
+	}
+	if err := alice.Delete(id, 0); err != nil {
+		t.Error("Owner should delete own note", err)
+	}
+}
+
+func Test_NoteRepo_Revisions(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	alice := &NoteRepoImpl{DB: db, UserID: 1}
+	note, _ := alice.Create(model.Note{Title: "first"})
+	id := int(note.ID)
+	alice.Update(id, model.Note{Title: "second", Completed: true})
+	alice.Update(id, model.Note{Title: "third", Completed: true})
+
+	revisions, err := alice.Revisions(id)
+	if err != nil || len(revisions) != 2 {
+		t.Fatal("Each update should save a revision", revisions, err)
+	}
+	if revisions[0].Rev != 2 || revisions[0].Title != "second" || revisions[1].Title != "first" {
+		t.Error("Revisions should be newest first with content before update", revisions)
+	}
+	from, _ := alice.Revision(id, 1)
+	current, _ := alice.Revision(id, 0)
+	diff := from.Diff(*current)
+	if len(diff.Changes) != 2 || diff.Changes[0].Before != "first" || diff.Changes[0].After != "third" {
+		t.Error("Diff should contain title and completed", diff)
+	}
+
+	restored, err := alice.Restore(id, 1)
+	if err != nil || restored.Title != "first" || restored.Completed {
+		t.Fatal("Restore should bring back revision content", restored, err)
+	}
+	found, _ := alice.Find(id)
+	if found.Title != "first" || found.Completed {
+		t.Error("Restored note should be saved", found)
+	}
+	// Noi dung truoc khi restore cung la mot revision
+	revisions, _ = alice.Revisions(id)
+	if len(revisions) != 3 || revisions[0].Action != model.REVISION_RESTORE || revisions[0].Title != "third" {
+		t.Error("Restore should save a revision", revisions)
+	}
+	if _, err := alice.Restore(id, 99); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Unknown revision should be not found, actual", err)
+	}
+
+	bob := &NoteRepoImpl{DB: db, UserID: 2}
+	if _, err := bob.Revisions(id); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Other user should not see revisions, actual", err)
+	}
+	if _, err := bob.Restore(id, 1); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Other user should not restore, actual", err)
+	}
+
+	alice.Delete(id, 0)
+	var count int
+	db.Model(&model.NoteRevision{}).Where("note_id = ? AND action = ?", id, model.REVISION_DELETE).Count(&count)
+	if count != 1 {
+		t.Error("Delete should save a revision")
+	}
+}
+
+func Test_NoteRepo_Trash(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	alice := &NoteRepoImpl{DB: db, UserID: 1}
+	bob := &NoteRepoImpl{DB: db, UserID: 2}
+	keep, _ := alice.Create(model.Note{Title: "keep"})
+	old, _ := alice.Create(model.Note{Title: "old"})
+	recent, _ := alice.Create(model.Note{Title: "recent"})
+	for _, note := range []*model.Note{old, recent} {
+		alice.Delete(int(note.ID), 0)
+	}
+	// Gia lap note old da vao thung rac tu lau
+	db.Unscoped().Model(old).Update("deleted_at", time.Now().Add(-48*time.Hour))
+
+	trash, err := alice.List(model.NoteFilter{Trashed: true}, helper.Pagination{Limit: 10})
+	if err != nil || trash.Total != 2 || trash.Data[0].Title != "recent" {
+		t.Fatal("Trash should have deleted notes, newest first", trash, err)
+	}
+	if res, _ := bob.List(model.NoteFilter{Trashed: true}, helper.Pagination{Limit: 10}); res.Total != 0 {
+		t.Error("Trash should only contain own notes")
+	}
+	if _, err := bob.Undelete(int(recent.ID)); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Other user should not undelete, actual", err)
+	}
+	if _, err := alice.Undelete(int(keep.ID)); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Note not in trash should be not found, actual", err)
+	}
+	undeleted, err := alice.Undelete(int(recent.ID))
+	if err != nil || undeleted.Title != "recent" || undeleted.DeletedAt != nil {
+		t.Error("Undelete should bring note back", undeleted, err)
+	}
+
+	count, err := PurgeTrash(db, time.Now().Add(-24*time.Hour))
+	if err != nil || count != 1 {
+		t.Error("Purge should only remove notes older than retention", count, err)
+	}
+	var revisions int
+	db.Model(&model.NoteRevision{}).Where("note_id = ?", old.ID).Count(&revisions)
+	if revisions != 0 {
+		t.Error("Purge should remove revisions")
+	}
+
+	if err := bob.DeletePermanent(int(keep.ID)); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Other user should not delete permanently, actual", err)
+	}
+	if err := alice.DeletePermanent(int(keep.ID)); err != nil {
+		t.Error(err)
+	}
+	var notes int
+	db.Unscoped().Model(&model.Note{}).Count(&notes)
+	if notes != 1 {
+		t.Error("Only undeleted note should remain, actual", notes)
+	}
+}
+
+func Test_NoteRepo_Tags(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	alice := &NoteRepoImpl{DB: db, UserID: 1}
+	bob := &NoteRepoImpl{DB: db, UserID: 2}
+	work, _ := alice.Create(model.Note{Title: "work note", Tags: []model.Tag{{ID: 99, Name: "injected"}}})
+	home, _ := alice.Create(model.Note{Title: "home note"})
+	both, _ := alice.Create(model.Note{Title: "both note"})
+	alice.AddTags(int(work.ID), []string{"Work", "urgent"})
+	alice.AddTags(int(home.ID), []string{"home"})
+	note, err := alice.AddTags(int(both.ID), []string{"work", "home", " HOME "})
+	if err != nil || len(note.Tags) != 2 {
+		t.Fatal("Tags should be normalized and not duplicated", note, err)
+	}
+	if _, err := bob.AddTags(int(work.ID), []string{"hack"}); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Other user should not tag, actual", err)
+	}
+
+	for _, c := range []struct {
+		filter   model.NoteFilter
+		expected int
+	}{
+		{model.NoteFilter{Tags: []string{"work,home"}}, 3},
+		{model.NoteFilter{Tags: []string{"work", "home"}, TagMode: model.TAG_MODE_ALL}, 1},
+		{model.NoteFilter{Tags: []string{"urgent"}, TagMode: model.TAG_MODE_ALL}, 1},
+		{model.NoteFilter{Tags: []string{"injected"}}, 0},
+	} {
+		res, err := alice.List(c.filter, helper.Pagination{Limit: 10})
+		if err != nil || res.Total != c.expected {
+			t.Errorf("Filter %v should have %d notes, actual %v %v", c.filter, c.expected, res, err)
+		}
+	}
+
+	tagRepo := &TagRepoImpl{DB: db, UserID: 1}
+	tags, _ := tagRepo.List()
+	counts := map[string]int{}
+	for _, tag := range tags {
+		counts[tag.Name] = tag.Count
+	}
+	if len(counts) != 3 || counts["work"] != 2 || counts["home"] != 2 || counts["urgent"] != 1 {
+		t.Error("Tag counts are wrong", tags)
+	}
+
+	note, _ = alice.RemoveTag(int(both.ID), "HOME")
+	if len(note.Tags) != 1 || note.Tags[0].Name != "work" {
+		t.Error("RemoveTag should only remove that tag", note.Tags)
+	}
+
+	// Doi ten "urgent" thanh "work" la gop vao tag work
+	var urgent model.Tag
+	db.Where("name = ?", "urgent").First(&urgent)
+	merged, err := tagRepo.Rename(int(urgent.ID), "Work")
+	if err != nil || merged.Name != "work" {
+		t.Fatal("Rename to existing name should merge", merged, err)
+	}
+	tags, _ = tagRepo.List()
+	if len(tags) != 2 || tags[1].Name != "work" || tags[1].Count != 2 {
+		t.Error("Merged tag should keep distinct notes", tags)
+	}
+	renamed, err := tagRepo.Rename(int(merged.ID), "job")
+	if err != nil || renamed.Name != "job" {
+		t.Error("Rename should change name", renamed, err)
+	}
+	if _, err := (&TagRepoImpl{DB: db, UserID: 2}).Rename(int(merged.ID), "x"); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Other user should not rename, actual", err)
+	}
+}
+
+func Test_NoteRepo_Share(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	alice := &NoteRepoImpl{DB: db, UserID: 1}
+	bob := &NoteRepoImpl{DB: db, UserID: 2}
+	carol := &NoteRepoImpl{DB: db, UserID: 3}
+	for _, username := range []string{"alice", "bob", "carol"} {
+		db.Create(&model.User{Username: username, Email: username + "@example.com"})
+	}
+	note, _ := alice.Create(model.Note{Title: "alice note"})
+	id := int(note.ID)
+
+	if _, err := bob.Share(id, 3, model.PERMISSION_EDIT); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Only owner can share, actual", err)
+	}
+	if _, err := alice.Share(id, 1, model.PERMISSION_VIEW); err != model.ErrShareWithOwner {
+		t.Error("Cannot share with owner, actual", err)
+	}
+	if _, err := alice.Share(id, 2, model.PERMISSION_VIEW); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.Find(id); err != nil {
+		t.Error("View share should allow Find", err)
+	}
+	if err := bob.Update(id, model.Note{Title: "bob edit"}); !gorm.IsRecordNotFoundError(err) {
+		t.Error("View share should not allow Update, actual", err)
+	}
+	if _, err := carol.Find(id); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Not shared user should not Find, actual", err)
+	}
+	res, _ := bob.List(model.NoteFilter{Shared: true}, helper.Pagination{Limit: 10})
+	if res.Total != 1 {
+		t.Error("Shared with me should contain note")
+	}
+	if res, _ := bob.List(model.NoteFilter{}, helper.Pagination{Limit: 10}); res.Total != 0 {
+		t.Error("Own list should not contain shared note")
+	}
+
+	// Share lai thi doi permission
+	share, err := alice.Share(id, 2, model.PERMISSION_EDIT)
+	if err != nil || share.Permission != model.PERMISSION_EDIT {
+		t.Fatal("Share again should update permission", share, err)
+	}
+	access, _ := alice.Shares(id)
+	if len(access) != 1 || access[0].Username != "bob" || access[0].Permission != model.PERMISSION_EDIT {
+		t.Error("Shares should list bob with edit", access)
+	}
+	if _, err := bob.Shares(id); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Only owner can list access, actual", err)
+	}
+	if err := bob.Update(id, model.Note{Title: "bob edit"}); err != nil {
+		t.Error("Edit share should allow Update", err)
+	}
+	if _, err := bob.Restore(id, 1); err != nil {
+		t.Error("Edit share should allow Restore", err)
+	}
+
+	if err := alice.Unshare(id, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.Unshare(id, 2); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Unshare twice should be not found, actual", err)
+	}
+	if err := bob.Delete(id, 0); !gorm.IsRecordNotFoundError(err) {
+		t.Error("Revoked user should not Delete, actual", err)
+	}
+	alice.Share(id, 2, model.PERMISSION_EDIT)
+	if err := bob.Delete(id, 0); err != nil {
+		t.Error("Edit share should allow Delete", err)
+	}
+	if res, _ := alice.List(model.NoteFilter{Trashed: true}, helper.Pagination{Limit: 10}); res.Total != 1 {
+		t.Error("Note deleted by editor should be in owner's trash")
+	}
+}
+
+func Test_NoteRepo_Version(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	alice := &NoteRepoImpl{DB: db, UserID: 1}
+	note, _ := alice.Create(model.Note{Title: "first"})
+	id := int(note.ID)
+	if note.Version != 1 {
+		t.Fatal("New note should have version 1, actual", note.Version)
+	}
+	if err := alice.Update(id, model.Note{Title: "second", Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.Update(id, model.Note{Title: "stale", Version: 1}); err != ErrVersionMismatch {
+		t.Error("Stale version should be mismatch, actual", err)
+	}
+	if err := alice.Delete(id, 1); err != ErrVersionMismatch {
+		t.Error("Stale version should be mismatch, actual", err)
+	}
+	// Gia lap request khac commit giua luc doc va luc update:
+	// dieu kien version trong UPDATE van chan duoc
+	current, _ := alice.Find(id)
+	db.Model(&model.Note{}).Where("id = ?", id).UpdateColumn("version", gorm.Expr("version + 1"))
+	if err := alice.save(current, map[string]interface{}{"title": "lost update"}); err != ErrVersionMismatch {
+		t.Error("Concurrent change should be mismatch, actual", err)
+	}
+	found, _ := alice.Find(id)
+	if found.Title != "second" || found.Version != 3 {
+		t.Error("Note should keep second with version 3", found)
+	}
+	if _, err := alice.Restore(id, 1); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := alice.Find(id); found.Version != 4 {
+		t.Error("Restore should bump version, actual", found.Version)
+	}
+}
+
+func Test_NoteRepo_Batch(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	alice := &NoteRepoImpl{DB: db, UserID: 1}
+	first, _ := alice.Create(model.Note{Title: "first"})
+	second, _ := alice.Create(model.Note{Title: "second"})
+	other, _ := (&NoteRepoImpl{DB: db, UserID: 2}).Create(model.Note{Title: "other"})
+
+	ops := []model.NoteBatchOp{
+		{Op: model.BATCH_CREATE, Title: "third"},
+		{Op: model.BATCH_COMPLETE, ID: int(first.ID), Version: 1},
+		{Op: model.BATCH_DELETE, ID: int(other.ID), Version: 1},
+	}
+	results, committed, err := alice.Batch(ops, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if committed || results[0].Err != ErrBatchAborted || results[1].Err != ErrBatchAborted || !gorm.IsRecordNotFoundError(results[2].Err) {
+		t.Fatal("Atomic batch should rollback all ops when one fails", results)
+	}
+	if found, _ := alice.Find(int(first.ID)); found.Completed || found.Version != 1 {
+		t.Error("First should not be completed after rollback", found)
+	}
+	if res, _ := alice.List(model.NoteFilter{}, helper.Pagination{Limit: 10}); res.Total != 2 {
+		t.Error("Created note should be rolled back, actual", titles(res.Data))
+	}
+
+	// Best-effort: op loi chi rollback ve savepoint cua no
+	ops = append(ops,
+		model.NoteBatchOp{Op: model.BATCH_UPDATE, ID: int(second.ID), Version: 9, Title: "stale"},
+		model.NoteBatchOp{Op: model.BATCH_DELETE, ID: int(second.ID), Version: 1},
+		model.NoteBatchOp{Op: model.BATCH_COMPLETE, ID: int(first.ID)},
+	)
+	results, committed, err = alice.Batch(ops, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oks := []bool{}
+	for _, result := range results {
+		oks = append(oks, result.OK)
+	}
+	if !committed || !oks[0] || !oks[1] || oks[2] || oks[3] || !oks[4] || results[3].Err != ErrVersionMismatch || results[5].Err != ErrBatchVersionRequired {
+		t.Fatal("Best-effort batch should skip failed ops", oks, results)
+	}
+	if results[0].Note == nil || results[0].Note.Title != "third" || !results[1].Note.Completed || results[1].Note.Version != 2 {
+		t.Error("Results should have notes after each op", results[0].Note, results[1].Note)
+	}
+	if res, _ := alice.List(model.NoteFilter{}, helper.Pagination{Limit: 10, Sort: "title"}); strings.Join(titles(res.Data), ",") != "first,third" {
+		t.Error("Notes should be first and third, actual", titles(res.Data))
+	}
+	// Revision cua op loi cung bi rollback
+	if revisions, _ := alice.Revisions(int(first.ID)); len(revisions) != 1 {
+		t.Error("First should have 1 revision, actual", len(revisions))
+	}
+}
+
+func Test_NoteRepo_Search(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	index := NewMemorySearchIndex()
+	alice := &NoteRepoImpl{DB: db, UserID: 1, Index: index}
+	bob := &NoteRepoImpl{DB: db, UserID: 2, Index: index}
+	milk, _ := alice.Create(model.Note{Title: "Buy milk"})
+	alice.Create(model.Note{Title: "Buy bread, eggs and <b>milk</b> for the whole week"})
+	alice.Create(model.Note{Title: "Do homework"})
+	shared, _ := bob.Create(model.Note{Title: "Milk the cow"})
+	bob.Create(model.Note{Title: "Sell milk"})
+
+	hits, err := alice.Search("MILK", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || hits[0].Note.ID != milk.ID || hits[0].Score <= hits[1].Score {
+		t.Fatal("Shorter note should rank first and other user's notes should be hidden", hits)
+	}
+	if snippet := hits[1].Snippets[0]; snippet.Field != "title" || snippet.Text != "Buy bread, eggs and &lt;b&gt;<mark>milk</mark>&lt;/b&gt; for the whole week" {
+		t.Error("Snippet should escape HTML and mark matched word, actual", snippet.Text)
+	}
+
+	bob.Share(int(shared.ID), 1, model.PERMISSION_VIEW)
+	if hits, _ := alice.Search("milk", 10); len(hits) != 3 {
+		t.Error("Shared note should be found, actual", len(hits))
+	}
+	if hits, _ := alice.Search("milk", 1); len(hits) != 1 {
+		t.Error("Search should respect limit, actual", len(hits))
+	}
+
+	// Index duoc cap nhat khi note doi title
+	alice.Update(int(milk.ID), model.Note{Title: "Buy juice"})
+	if hits, _ := alice.Search("juice", 10); len(hits) != 1 || hits[0].Snippets[0].Text != "Buy <mark>juice</mark>" {
+		t.Error("Updated note should be found by new title", hits)
+	}
+	if hits, _ := alice.Search("milk", 10); len(hits) != 2 {
+		t.Error("Updated note should not be found by old title, actual", len(hits))
+	}
+	alice.Delete(int(milk.ID), 0)
+	if hits, _ := alice.Search("juice", 10); len(hits) != 0 {
+		t.Error("Deleted note should not be found, actual", len(hits))
+	}
+	// Note da xoa khong con trong index va khong duoc tinh vao idf
+	if _, ok := index.versions[milk.ID]; ok || len(index.versions) != 4 {
+		t.Error("Deleted note should be removed from index, indexed", len(index.versions))
+	}
+	homework, _ := alice.Create(model.Note{Title: "Homework again"})
+	alice.Search("homework", 10)
+	alice.DeletePermanent(int(homework.ID))
+	if _, ok := index.versions[homework.ID]; ok {
+		t.Error("Permanently deleted note should be removed from index")
+	}
+	// Note bi xoa han ma index khong biet (vd boi PurgeTrash) bi xoa khi Search gap lai
+	db.Unscoped().Where("title = ?", "Sell milk").Delete(&model.Note{})
+	if hits, _ := bob.Search("milk", 10); len(hits) != 1 || len(index.versions) != 3 {
+		t.Error("Purged note should be removed from index", len(hits), len(index.versions))
+	}
+	if _, err := alice.Search(" ?! ", 10); err != ErrEmptySearch {
+		t.Error("Query without words should be invalid, actual", err)
+	}
+}
+
+func Test_Highlight_Long(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 10) + "target" + strings.Repeat(" dolor sit", 10)
+	snippet, ok := highlight(text, []string{"target"})
+	if !ok || !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || !strings.Contains(snippet, "<mark>target</mark>") {
+		t.Error("Long text should be cut around matched word, actual", snippet)
+	}
+	if _, ok := highlight(text, []string{"targ"}); ok {
+		t.Error("Only whole words should match")
+	}
+}
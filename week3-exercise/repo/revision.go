@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"../model"
+)
+
+// saveRevision luu noi dung hien tai cua note voi rev tiep theo.
+// change da lock row cua note nen MAX(rev) khong bi transaction khac chen giua,
+// Unique (note_id, rev) van chan neu co, khi do tra ve ErrVersionMismatch de client thu lai.
+func (self *NoteRepoImpl) saveRevision(note model.Note, action string) error {
+	var rev struct{ Max int }
+	err := self.DB.Model(&model.NoteRevision{}).
+		Select("COALESCE(MAX(rev), 0) AS max").
+		Where("note_id = ?", note.ID).
+		Scan(&rev).Error
+	if err != nil {
+		return err
+	}
+	revision := model.NoteRevision{
+		NoteID:    note.ID,
+		Rev:       rev.Max + 1,
+		Action:    action,
+		Title:     note.Title,
+		Completed: note.Completed,
+		UserID:    self.UserID,
+	}
+	err = self.DB.Create(&revision).Error
+	if isDuplicateKey(err) {
+		return ErrVersionMismatch
+	}
+	return err
+}
+
+// Revisions tra ve cac revision moi nhat truoc, ai doc duoc note thi doc duoc revision
+func (self *NoteRepoImpl) Revisions(id int) ([]model.NoteRevision, error) {
+	if _, err := self.Find(id); err != nil {
+		return nil, err
+	}
+	revisions := []model.NoteRevision{}
+	err := self.DB.Where("note_id = ?", id).Order("rev DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+func (self *NoteRepoImpl) Revision(id int, rev int) (*model.NoteRevision, error) {
+	note, err := self.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	if rev == 0 {
+		return &model.NoteRevision{
+			NoteID:    note.ID,
+			Title:     note.Title,
+			Completed: note.Completed,
+			UserID:    note.UserID,
+			CreatedAt: note.UpdatedAt,
+		}, nil
+	}
+	revision := &model.NoteRevision{}
+	err = self.DB.Where("note_id = ? AND rev = ?", id, rev).First(revision).Error
+	return revision, err
+}
+
+// Restore dua note ve noi dung cua revision rev, noi dung truoc khi restore
+// cung duoc luu thanh revision moi nen co the restore nguoc lai
+func (self *NoteRepoImpl) Restore(id int, rev int) (*model.Note, error) {
+	restored := &model.Note{}
+	err := self.change(id, 0, model.REVISION_RESTORE, func(repo *NoteRepoImpl, current *model.Note) error {
+		revision := &model.NoteRevision{}
+		if err := repo.DB.Where("note_id = ? AND rev = ?", id, rev).First(revision).Error; err != nil {
+			return err
+		}
+		restored = current
+		return repo.save(current, map[string]interface{}{
+			"title":     revision.Title,
+			"completed": revision.Completed,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"html"
+	"log"
+	"strings"
+	"unicode"
+
+	"../helper"
+	"../model"
+	"github.com/jinzhu/gorm"
+)
+
+// Cac column cua notes duoc search, them body vao day khi note co body
+var NOTE_SEARCH_COLUMNS = []string{"title"}
+
+// So rune toi da cua mot snippet
+const SNIPPET_LENGTH = 80
+
+var ErrEmptySearch = helper.NewError(helper.KIND_INVALID, "EMPTY_SEARCH", "Search query has no words")
+
+// SearchMatch la note khop voi query va diem cua no
+type SearchMatch struct {
+	ID    uint
+	Score float64
+}
+
+// SearchIndex tim note theo cac tu trong query. scope la query da gioi han
+// cac note user duoc doc, index chi tra ve note nam trong scope.
+// Ket qua sap xep theo Score giam dan, toi da limit note.
+// Remove duoc goi sau khi note bi xoa han.
+type SearchIndex interface {
+	Search(scope *gorm.DB, terms []string, limit uint) ([]SearchMatch, error)
+	Remove(ids ...uint)
+}
+
+// NewSearchIndex dung FULLTEXT khi chay MySQL, cac DB khac (SQLite khi test)
+// dung index trong memory
+func NewSearchIndex(db *gorm.DB) SearchIndex {
+	if db.Dialect().GetName() == "mysql" {
+		index := &MySQLSearchIndex{}
+		if err := index.Migrate(db); err != nil {
+			log.Println("Create fulltext index failed:", err)
+		}
+		return index
+	}
+	return NewMemorySearchIndex()
+}
+
+// tokenize tach text thanh cac tu viet thuong, bo dau cau
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// noteSearchText la gia tri cua cac NOTE_SEARCH_COLUMNS, cung thu tu
+func noteSearchText(note model.Note) []string {
+	return []string{note.Title}
+}
+
+func (self *NoteRepoImpl) Search(query string, limit uint) ([]model.NoteSearchHit, error) {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearch
+	}
+	matches, err := self.Index.Search(self.readable().Model(&model.Note{}), terms, limit)
+	if err != nil || len(matches) == 0 {
+		return []model.NoteSearchHit{}, err
+	}
+	ids := make([]uint, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+	}
+	notes := []model.Note{}
+	if err := self.readable().Preload("Tags").Where("id IN (?)", ids).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	byID := map[uint]model.Note{}
+	for _, note := range notes {
+		byID[note.ID] = note
+	}
+	hits := []model.NoteSearchHit{}
+	for _, match := range matches {
+		note, ok := byID[match.ID]
+		if !ok {
+			continue
+		}
+		hit := model.NoteSearchHit{Note: note, Score: match.Score, Snippets: []model.SearchSnippet{}}
+		for i, text := range noteSearchText(note) {
+			if snippet, ok := highlight(text, terms); ok {
+				hit.Snippets = append(hit.Snippets, model.SearchSnippet{Field: NOTE_SEARCH_COLUMNS[i], Text: snippet})
+			}
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// highlight cat mot doan SNIPPET_LENGTH rune quanh tu khop dau tien cua text
+// va boc cac tu khop trong <mark></mark>. Tra ve false neu text khong co tu nao khop.
+func highlight(text string, terms []string) (string, bool) {
+	wanted := map[string]bool{}
+	for _, term := range terms {
+		wanted[term] = true
+	}
+	runes := []rune(text)
+	// Vi tri [start, end) cua cac tu khop
+	spans := [][2]int{}
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsNumber(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsNumber(runes[j])) {
+			j++
+		}
+		if wanted[strings.ToLower(string(runes[i:j]))] {
+			spans = append(spans, [2]int{i, j})
+		}
+		i = j
+	}
+	if len(spans) == 0 {
+		return "", false
+	}
+	from := 0
+	if len(runes) > SNIPPET_LENGTH {
+		// Tu khop dau tien nam o khoang 1/4 snippet
+		from = spans[0][0] - SNIPPET_LENGTH/4
+		if from < 0 {
+			from = 0
+		}
+		if from > len(runes)-SNIPPET_LENGTH {
+			from = len(runes) - SNIPPET_LENGTH
+		}
+	}
+	to := from + SNIPPET_LENGTH
+	if to > len(runes) {
+		to = len(runes)
+	}
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, span := range spans {
+		if span[1] <= from || span[0] >= to {
+			continue
+		}
+		start, end := span[0], span[1]
+		if start < from {
+			start = from
+		}
+		if end > to {
+			end = to
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:start])))
+		b.WriteString("<mark>" + html.EscapeString(string(runes[start:end])) + "</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
@@ -0,0 +1,204 @@
+package repo
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"../model"
+	"github.com/jinzhu/gorm"
+)
+
+// So id toi da trong mot IN (...), SQLite gioi han so tham so cua mot query
+const SEARCH_SYNC_CHUNK = 500
+
+// Lan sync sau doc lai ca note thay doi truoc lan sync truoc SEARCH_SYNC_SLACK,
+// de khong bo sot transaction set updated_at truoc nhung commit sau lan sync do
+const SEARCH_SYNC_SLACK = time.Minute
+
+// MemorySearchIndex la inverted index trong memory, dung cho SQLite va test.
+// Index chua cac note chua bi xoa cua moi user, scope chi loc ket qua khi Search.
+// Moi lan Search chi doc cac note co updated_at hoac deleted_at tu lan sync truoc,
+// note vao thung rac bi xoa khoi index, note moi hoac co version khac luc index
+// thi duoc index lai, nen chi thay du lieu da commit.
+type MemorySearchIndex struct {
+	mutex sync.Mutex
+	// Version cua note luc duoc index, cung la tap note con song dung de tinh idf
+	versions map[uint]uint
+	// Tu -> note id -> so lan tu xuat hien trong note
+	postings map[string]map[uint]int
+	// Cac tu cua note, dung de xoa note khoi postings khi index lai
+	terms map[uint][]string
+	// Thoi diem bat dau lan sync truoc, zero la chua sync lan nao
+	synced time.Time
+}
+
+func NewMemorySearchIndex() *MemorySearchIndex {
+	return &MemorySearchIndex{
+		versions: map[uint]uint{},
+		postings: map[string]map[uint]int{},
+		terms:    map[uint][]string{},
+	}
+}
+
+func (self *MemorySearchIndex) Search(scope *gorm.DB, terms []string, limit uint) ([]SearchMatch, error) {
+	if err := self.sync(scope.New()); err != nil {
+		return nil, err
+	}
+	self.mutex.Lock()
+	candidates := []uint{}
+	seen := map[uint]bool{}
+	for _, term := range terms {
+		for id := range self.postings[term] {
+			if !seen[id] {
+				seen[id] = true
+				candidates = append(candidates, id)
+			}
+		}
+	}
+	self.mutex.Unlock()
+	allowed, err := self.allowed(scope, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	// Score = tong tf * idf cua cac tu, chia cho can bac hai do dai note
+	// de note ngan khop nhieu tu xep truoc
+	scores := map[uint]float64{}
+	for _, term := range terms {
+		posting := self.postings[term]
+		if len(posting) == 0 {
+			continue
+		}
+		idf := math.Log(1 + float64(len(self.versions))/float64(len(posting)))
+		for id, tf := range posting {
+			if allowed[id] {
+				scores[id] += float64(tf) * idf / math.Sqrt(float64(len(self.terms[id])))
+			}
+		}
+	}
+	matches := make([]SearchMatch, 0, len(scores))
+	for id, score := range scores {
+		matches = append(matches, SearchMatch{ID: id, Score: score})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID > matches[j].ID
+	})
+	if uint(len(matches)) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// Remove xoa note khoi index, dung khi note bi xoa han ma khong qua thung rac
+func (self *MemorySearchIndex) Remove(ids ...uint) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	for _, id := range ids {
+		self.remove(id)
+	}
+}
+
+// sync doc cac note thay doi tu lan sync truoc, lan dau doc tat ca
+func (self *MemorySearchIndex) sync(db *gorm.DB) error {
+	self.mutex.Lock()
+	since := self.synced
+	self.mutex.Unlock()
+	start := gorm.NowFunc()
+	query := db.Unscoped()
+	if !since.IsZero() {
+		from := since.Add(-SEARCH_SYNC_SLACK)
+		query = query.Where("updated_at >= ? OR deleted_at >= ?", from, from)
+	}
+	notes := []model.Note{}
+	if err := query.Find(&notes).Error; err != nil {
+		return err
+	}
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	for _, note := range notes {
+		if note.DeletedAt != nil {
+			self.remove(note.ID)
+		} else if version, ok := self.versions[note.ID]; !ok || version != note.Version {
+			self.index(note)
+		}
+	}
+	if start.After(self.synced) {
+		self.synced = start
+	}
+	return nil
+}
+
+// allowed tra ve cac id trong ids ma scope doc duoc. Id khong con trong bang
+// notes (bi xoa han boi PurgeTrash hay process khac) thi bi xoa khoi index.
+func (self *MemorySearchIndex) allowed(scope *gorm.DB, ids []uint) (map[uint]bool, error) {
+	allowed := map[uint]bool{}
+	for i := 0; i < len(ids); i += SEARCH_SYNC_CHUNK {
+		end := i + SEARCH_SYNC_CHUNK
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := ids[i:end]
+		live := []uint{}
+		if err := scope.New().Model(&model.Note{}).Where("id IN (?)", chunk).Pluck("id", &live).Error; err != nil {
+			return nil, err
+		}
+		isLive := make(map[uint]bool, len(live))
+		for _, id := range live {
+			isLive[id] = true
+		}
+		self.mutex.Lock()
+		for _, id := range chunk {
+			if !isLive[id] {
+				self.remove(id)
+			}
+		}
+		self.mutex.Unlock()
+		if len(live) == 0 {
+			continue
+		}
+		readable := []uint{}
+		if err := scope.Where("id IN (?)", live).Pluck("id", &readable).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range readable {
+			allowed[id] = true
+		}
+	}
+	return allowed, nil
+}
+
+// index phai duoc goi khi dang giu mutex
+func (self *MemorySearchIndex) index(note model.Note) {
+	self.remove(note.ID)
+	terms := []string{}
+	for _, text := range noteSearchText(note) {
+		terms = append(terms, tokenize(text)...)
+	}
+	for _, term := range terms {
+		if self.postings[term] == nil {
+			self.postings[term] = map[uint]int{}
+		}
+		self.postings[term][note.ID]++
+	}
+	self.terms[note.ID] = terms
+	self.versions[note.ID] = note.Version
+}
+
+// remove phai duoc goi khi dang giu mutex
+func (self *MemorySearchIndex) remove(id uint) {
+	for _, term := range self.terms[id] {
+		delete(self.postings[term], id)
+		if len(self.postings[term]) == 0 {
+			delete(self.postings, term)
+		}
+	}
+	delete(self.terms, id)
+	delete(self.versions, id)
+}
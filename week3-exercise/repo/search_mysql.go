@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+const FULLTEXT_INDEX = "idx_notes_fulltext"
+
+// MySQLSearchIndex dung FULLTEXT index cua MySQL tren NOTE_SEARCH_COLUMNS,
+// MySQL tu cap nhat index khi note thay doi
+type MySQLSearchIndex struct{}
+
+// Migrate tao FULLTEXT index neu chua co, AutoMigrate khong tao duoc loai index nay
+func (self *MySQLSearchIndex) Migrate(db *gorm.DB) error {
+	var count int
+	err := db.Raw("SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'notes' AND index_name = ?", FULLTEXT_INDEX).
+		Row().Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	return db.Exec("ALTER TABLE notes ADD FULLTEXT INDEX " + FULLTEXT_INDEX + " (" + strings.Join(NOTE_SEARCH_COLUMNS, ", ") + ")").Error
+}
+
+func (self *MySQLSearchIndex) Search(scope *gorm.DB, terms []string, limit uint) ([]SearchMatch, error) {
+	match := "MATCH(" + strings.Join(NOTE_SEARCH_COLUMNS, ", ") + ") AGAINST(? IN NATURAL LANGUAGE MODE)"
+	query := strings.Join(terms, " ")
+	matches := []SearchMatch{}
+	err := scope.Select("id, "+match+" AS score", query).
+		Where(match, query).
+		Order("score DESC, id DESC").
+		Limit(limit).
+		Scan(&matches).Error
+	return matches, err
+}
+
+// Remove khong can lam gi, row bi xoa thi cung khong con trong FULLTEXT index
+func (self *MySQLSearchIndex) Remove(ids ...uint) {}
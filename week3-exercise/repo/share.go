@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"../model"
+)
+
+// Share tao hoac doi permission cua user tren note
+func (self *NoteRepoImpl) Share(id int, userID uint, permission string) (*model.NoteShare, error) {
+	note := &model.Note{}
+	if err := self.owned().Where("id = ?", id).First(note).Error; err != nil {
+		return nil, err
+	}
+	if userID == note.UserID {
+		return nil, model.ErrShareWithOwner
+	}
+	share := &model.NoteShare{}
+	err := self.DB.Where(model.NoteShare{NoteID: note.ID, UserID: userID}).
+		Assign(model.NoteShare{Permission: permission}).
+		FirstOrCreate(share).Error
+	return share, err
+}
+
+func (self *NoteRepoImpl) Unshare(id int, userID uint) error {
+	note := &model.Note{}
+	if err := self.owned().Where("id = ?", id).First(note).Error; err != nil {
+		return err
+	}
+	result := self.DB.Where("note_id = ? AND user_id = ?", note.ID, userID).Delete(&model.NoteShare{})
+	return notFoundIfNoRows(result)
+}
+
+// Shares liet ke cac user duoc share note, khong gom chu cua note
+func (self *NoteRepoImpl) Shares(id int) ([]model.NoteAccess, error) {
+	note := &model.Note{}
+	if err := self.owned().Where("id = ?", id).First(note).Error; err != nil {
+		return nil, err
+	}
+	access := []model.NoteAccess{}
+	err := self.DB.Table("note_shares").
+		Select("note_shares.user_id, users.username, note_shares.permission, note_shares.created_at").
+		Joins("JOIN users ON users.id = note_shares.user_id").
+		Where("note_shares.note_id = ?", note.ID).
+		Order("note_shares.id").
+		Scan(&access).Error
+	return access, err
+}
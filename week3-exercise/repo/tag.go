@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"../model"
+	"github.com/jinzhu/gorm"
+)
+
+func (self *NoteRepoImpl) AddTags(id int, names []string) (*model.Note, error) {
+	tx := self.DB.Begin()
+	repo := self.in(tx)
+	note := &model.Note{}
+	if err := repo.owned().Where("id = ?", id).First(note).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tags := []model.Tag{}
+	for _, name := range names {
+		if name = model.NormalizeTag(name); name == "" {
+			continue
+		}
+		tag := model.Tag{}
+		err := tx.Where(model.Tag{UserID: self.UserID, Name: name}).
+			FirstOrCreate(&tag).Error
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	// Append chi insert vao note_tags nhung cap chua co
+	if err := tx.Model(note).Association("Tags").Append(tags).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return self.Find(id)
+}
+
+// RemoveTag chi go tag khoi note, tag van con trong danh sach tag cua user
+func (self *NoteRepoImpl) RemoveTag(id int, name string) (*model.Note, error) {
+	note := &model.Note{}
+	if err := self.owned().Where("id = ?", id).First(note).Error; err != nil {
+		return nil, err
+	}
+	tag := &model.Tag{}
+	err := self.DB.Where("user_id = ? AND name = ?", self.UserID, model.NormalizeTag(name)).First(tag).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := self.DB.Model(note).Association("Tags").Delete(tag).Error; err != nil {
+		return nil, err
+	}
+	return self.Find(id)
+}
+
+type TagRepo interface {
+	// List tra ve cac tag cua user cung so note (chua xoa) co tag do
+	List() ([]model.TagCount, error)
+	// Rename doi ten tag, neu da co tag trung ten thi gop vao tag do
+	Rename(int, string) (*model.Tag, error)
+}
+
+type TagRepoImpl struct {
+	DB     *gorm.DB
+	UserID uint
+}
+
+func (self *TagRepoImpl) List() ([]model.TagCount, error) {
+	tags := []model.TagCount{}
+	err := self.DB.Table("tags").
+		Select("tags.id, tags.name, COUNT(notes.id) AS count").
+		Joins("LEFT JOIN note_tags ON note_tags.tag_id = tags.id").
+		Joins("LEFT JOIN notes ON notes.id = note_tags.note_id AND notes.deleted_at IS NULL").
+		Where("tags.user_id = ?", self.UserID).
+		Group("tags.id, tags.name").
+		Order("tags.name").
+		Scan(&tags).Error
+	return tags, err
+}
+
+func (self *TagRepoImpl) Rename(id int, name string) (*model.Tag, error) {
+	name = model.NormalizeTag(name)
+	tx := self.DB.Begin()
+	tag := &model.Tag{}
+	if err := tx.Where("id = ? AND user_id = ?", id, self.UserID).First(tag).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	target := &model.Tag{}
+	err := tx.Where("user_id = ? AND name = ? AND id <> ?", self.UserID, name, id).First(target).Error
+	if gorm.IsRecordNotFoundError(err) {
+		err = tx.Model(tag).Update("name", name).Error
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		return tag, tx.Commit().Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	// Gop: chuyen note sang target (bo qua note da co target) roi xoa tag cu
+	err = tx.Exec("INSERT INTO note_tags (note_id, tag_id) "+
+		"SELECT note_id, ? FROM note_tags WHERE tag_id = ? "+
+		"AND note_id NOT IN (SELECT note_id FROM note_tags WHERE tag_id = ?)", target.ID, tag.ID, target.ID).Error
+	if err == nil {
+		err = tx.Exec("DELETE FROM note_tags WHERE tag_id = ?", tag.ID).Error
+	}
+	if err == nil {
+		err = tx.Delete(tag).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return target, tx.Commit().Error
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"time"
+
+	"../helper"
+	"../model"
+	"github.com/jinzhu/gorm"
+)
+
+var ErrInvalidToken = helper.NewError(helper.KIND_UNAUTHORIZED, "INVALID_TOKEN", "Invalid refresh token")
+
+type TokenRepo interface {
+	CreateSession(model.Session, model.RefreshToken) error
+	// Rotate danh dau refresh token co hash la da dung va luu next cho cung session
+	Rotate(string, model.RefreshToken, time.Time) (*model.Session, error)
+	RevokeSession(string, time.Time) error
+	IsRevoked(string) (bool, error)
+}
+
+type TokenRepoImpl struct {
+	DB *gorm.DB
+}
+
+func (self *TokenRepoImpl) CreateSession(session model.Session, refresh model.RefreshToken) error {
+	tx := self.DB.Begin()
+	if err := tx.Create(&session).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	refresh.SessionID = session.ID
+	if err := tx.Create(&refresh).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// Rotate dung UPDATE co dieu kien thay cho FOR UPDATE: chi mot request
+// danh dau duoc token la da dung. Token da dung ma bi gui lai thi coi nhu
+// bi lo, revoke ca session.
+func (self *TokenRepoImpl) Rotate(hash string, next model.RefreshToken, now time.Time) (*model.Session, error) {
+	tx := self.DB.Begin()
+	result := tx.Model(&model.RefreshToken{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+	token := &model.RefreshToken{}
+	if err := tx.Where("token_hash = ?", hash).First(token).Error; err != nil {
+		tx.Rollback()
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		// Token het han thi chi tu choi, token da dung thi revoke session
+		if token.UsedAt != nil {
+			if err := revokeSession(tx, token.SessionID, now); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+	session := &model.Session{}
+	if err := tx.Where("id = ? AND revoked_at IS NULL", token.SessionID).First(session).Error; err != nil {
+		tx.Rollback()
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	next.SessionID = session.ID
+	if err := tx.Create(&next).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	session.ExpiresAt = next.ExpiresAt
+	if err := tx.Model(session).Update("expires_at", next.ExpiresAt).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return session, tx.Commit().Error
+}
+
+func (self *TokenRepoImpl) RevokeSession(id string, now time.Time) error {
+	return revokeSession(self.DB, id, now)
+}
+
+func revokeSession(db *gorm.DB, id string, now time.Time) error {
+	return db.Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+// IsRevoked tra ve true neu session da bi revoke hoac khong ton tai
+func (self *TokenRepoImpl) IsRevoked(id string) (bool, error) {
+	session := &model.Session{}
+	err := self.DB.Where("id = ?", id).First(session).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return session.RevokedAt != nil, nil
+}
@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"log"
+	"time"
+
+	"../model"
+	"github.com/jinzhu/gorm"
+)
+
+// trashed la cac note cua UserID dang nam trong thung rac
+func (self *NoteRepoImpl) trashed() *gorm.DB {
+	return self.owned().Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+func (self *NoteRepoImpl) Undelete(id int) (*model.Note, error) {
+	result := self.trashed().Model(&model.Note{}).Where("id = ?", id).Update("deleted_at", nil)
+	if err := notFoundIfNoRows(result); err != nil {
+		return nil, err
+	}
+	return self.Find(id)
+}
+
+// DeletePermanent xoa ca note dang o ngoai thung rac
+func (self *NoteRepoImpl) DeletePermanent(id int) error {
+	tx := self.DB.Begin()
+	repo := self.in(tx)
+	result := repo.owned().Unscoped().Where("id = ?", id).Delete(&model.Note{})
+	if err := notFoundIfNoRows(result); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("note_id = ?", id).Delete(&model.NoteRevision{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Exec("DELETE FROM note_tags WHERE note_id = ?", id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("note_id = ?", id).Delete(&model.NoteShare{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	if self.Index != nil {
+		self.Index.Remove(uint(id))
+	}
+	return nil
+}
+
+// PurgeTrash xoa han cac note da vao thung rac truoc thoi diem before
+func PurgeTrash(db *gorm.DB, before time.Time) (int, error) {
+	tx := db.Begin()
+	ids := []uint{}
+	err := tx.Unscoped().Model(&model.Note{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Where("note_id IN (?)", ids).Delete(&model.NoteRevision{}).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Exec("DELETE FROM note_tags WHERE note_id IN (?)", ids).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Where("note_id IN (?)", ids).Delete(&model.NoteShare{}).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Unscoped().Where("id IN (?)", ids).Delete(&model.Note{}).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return len(ids), tx.Commit().Error
+}
+
+// StartTrashPurge chay PurgeTrash moi interval cho cac note nam trong
+// thung rac lau hon retention. Goi ham tra ve de dung job.
+func StartTrashPurge(db *gorm.DB, retention time.Duration, interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				count, err := PurgeTrash(db, now.Add(-retention))
+				if err != nil {
+					log.Println("Purge trash:", err)
+				} else if count > 0 {
+					log.Printf("Purge trash: %d notes\n", count)
+				}
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
@@ -1,13 +1,24 @@
 package repo
 
 import (
+	"strings"
+	"time"
+
+	"../helper"
 	"../model"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
 )
 
+var ErrUserExists = helper.NewError(helper.KIND_CONFLICT, "USER_EXISTS", "Username or email is already taken")
+
 type UserRepo interface {
 	Create(model.User) (*model.User, error)
 	FindByUserLogin(string) (*model.User, error)
+	FindByID(uint) (*model.User, error)
+	// List lay toi da limit user co id > afterID
+	List(uint, uint) ([]model.User, error)
+	UpdateRole(uint, string) (*model.User, error)
 }
 
 type UserRepoImpl struct {
@@ -16,9 +27,23 @@ type UserRepoImpl struct {
 
 func (self *UserRepoImpl) Create(user model.User) (*model.User, error) {
 	err := self.DB.Create(&user).Error
+	if isDuplicateKey(err) {
+		return nil, ErrUserExists
+	}
 	return &user, err
 }
 
+// isDuplicateKey nhan ra loi unique cua MySQL (1062) va SQLite (dung khi test)
+func isDuplicateKey(err error) bool {
+	if err == nil {
+		return false
+	}
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+		return mysqlErr.Number == 1062
+	}
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
 func (self *UserRepoImpl) FindByUserLogin(login string) (*model.User, error) {
 	user := &model.User{}
 	err := self.DB.
@@ -26,3 +51,44 @@ func (self *UserRepoImpl) FindByUserLogin(login string) (*model.User, error) {
 		First(user).Error
 	return user, err
 }
+
+func (self *UserRepoImpl) FindByID(id uint) (*model.User, error) {
+	user := &model.User{}
+	err := self.DB.Where("id = ?", id).First(user).Error
+	return user, err
+}
+
+func (self *UserRepoImpl) List(afterID uint, limit uint) ([]model.User, error) {
+	users := []model.User{}
+	err := self.DB.Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// UpdateRole doi role va revoke moi session cua user trong cung transaction.
+// Role nam trong access token nen neu khong revoke, user bi ha role van giu
+// quyen cu (vd doc note cua tat ca user) cho toi khi token het han.
+func (self *UserRepoImpl) UpdateRole(id uint, role string) (*model.User, error) {
+	user, err := self.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+	tx := self.DB.Begin()
+	if err := tx.Model(user).Update("role", role).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = tx.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return user, tx.Commit().Error
+}